@@ -1,3 +1,12 @@
 #!/bin/bash
 
-CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o godojo
+# Embed build information for godojo -version
+PKG="github.com/defectdojo/godojo/cmd"
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")
+DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+LDFLAGS="-X ${PKG}.buildCommit=${COMMIT} -X ${PKG}.buildDate=${DATE}"
+if [ -n "$VERSION" ]; then
+	LDFLAGS="${LDFLAGS} -X ${PKG}.buildVersion=${VERSION}"
+fi
+
+CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "${LDFLAGS}" -o godojo
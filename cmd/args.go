@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // readArgs() takes no arguements and returns filled launchArgs struct unless
@@ -12,10 +13,38 @@ import (
 func readArgs(d *DDConfig) {
 	d.traceMsg("Called readArgs")
 	// Read in the supported command-line options
-	var version, help, v, h bool
+	var version, help, h, quiet bool
+	var phases string
 	flag.BoolVar(&d.defInstall, "default", false, "Do an install based on default config values")
+	flag.StringVar(&d.cfPath, "config", "", "Path to the config file to use instead of ./dojoConfig.yml")
+	flag.BoolVar(&d.dryRun, "dry-run", false, "Print the commands and downloads an install would do without running them")
+	flag.BoolVar(&quiet, "quiet", false, "Replace the progress spinner with plain status lines, with -trace-to-syslog show no console output")
+	flag.BoolVar(&d.forceExtract, "force-extract", false, "Extract the release tarball even if it was already extracted")
+	flag.BoolVar(&d.allowUnpriv, "allow-unprivileged", false, "Don't exit when godojo isn't run as root")
+	flag.StringVar(&d.logFormat, "log-format", "text", "Format of the log file entries, either text or json")
+	flag.StringVar(&d.logFile, "log-file", "", "Also write all log messages and command output to this file")
+	flag.BoolVar(&d.traceRedact, "trace-redact", false, "Redact passwords, tokens and keys from all output even if Install.Redact is false")
+	flag.BoolVar(&d.traceSyslog, "trace-to-syslog", false, "Also send trace messages to the local syslog daemon")
+	flag.BoolVar(&d.syslogAll, "syslog-all", false, "Send every log level to syslog with -trace-to-syslog, not just trace")
+	flag.StringVar(&d.syslogFacility, "syslog-facility", d.syslogFacility, "Syslog facility used with -trace-to-syslog")
+	flag.StringVar(&d.syslogTag, "syslog-tag", d.syslogTag, "Syslog tag used with -trace-to-syslog")
+	flag.BoolVar(&d.restart, "restart", false, "Run every install phase, even those completed by an earlier run")
+	flag.BoolVar(&d.skipBootstrap, "skip-bootstrap", false, "Skip bootstrapping the installer's OS packages, e.g. on pre-provisioned images")
+	flag.BoolVar(&d.skipMirror, "skip-mirror-check", false, "Don't time connecting to the package mirror before the OS package commands")
+	flag.BoolVar(&d.insecure, "insecure-skip-verify", false, "Don't verify TLS certificates for downloads and clones, for lab use only")
+	flag.BoolVar(&d.noColor, "no-color", d.noColor, "Turn off ANSI colors in the status, warning and error messages and the spinner")
+	flag.BoolVar(&d.noRollback, "no-rollback", false, "Leave the changes made by a failed install in place for debugging")
+	flag.BoolVar(&d.offline, "offline", false, "Fail instead of making any HTTP or git network call, needs LocalTarball or an existing clone")
+	flag.StringVar(&d.report, "report", "", "Write a JSON summary of the run to this file, even if the install fails")
+	flag.StringVar(&d.metrics, "metrics", "", "Write each phase's start, end and duration to this file as CSV, or JSON if it ends in .json")
+	flag.StringVar(&phases, "phase", "", "Comma separated list of the install phases to run, e.g. bootstrap,download")
+	flag.DurationVar(&d.timeoutOverall, "timeout-overall", 0, "Stop the install with an error if it runs longer than this, e.g. 90m")
+	flag.BoolVar(&d.upgrade, "upgrade", false, "Replace an existing install of a different DefectDojo version in Install.Root")
+	flag.BoolVar(&d.yes, "yes", false, "Don't prompt for confirmation before destructive steps like dropping an existing database")
+	flag.Var(verbosity{&d.verbosity, 1}, "verbose", "Show verbose messages, give it twice to also show trace messages")
+	flag.Var(verbosity{&d.verbosity, 1}, "v", "Show verbose messages, give it twice to also show trace messages")
+	flag.Var(verbosity{&d.verbosity, 2}, "vv", "Show verbose and trace messages")
 	flag.BoolVar(&version, "version", false, "Print the version and exit")
-	flag.BoolVar(&v, "v", false, "Print the version and exit")
 	flag.BoolVar(&help, "help", false, "Print the help message and exit")
 	flag.BoolVar(&h, "h", false, "Print the help message and exit")
 	flag.Parse()
@@ -26,16 +55,58 @@ func readArgs(d *DDConfig) {
 		os.Exit(0)
 	}
 	// Print version
-	if version || v {
-		fmt.Printf("godojo version %s\n", d.ver)
+	if version {
+		fmt.Println(d.versionInfo())
 		os.Exit(0)
 	}
 
+	// Run only the selected phases
+	if len(phases) > 0 {
+		var err error
+		d.phases, err = parsePhases(phases)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	// Check the log format
+	if d.logFormat != "text" && d.logFormat != "json" {
+		fmt.Printf("Unsupported -log-format of %q, it must be either text or json\n", d.logFormat)
+		os.Exit(1)
+	}
+
+	// Open the -log-file, if any, before anything else is logged
+	err := openLogFile(d)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	err = openSyslog(d)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if quiet {
+		d.plain = true
+		syslogQuiet(d)
+	}
+
 	// Handle special install case of default installs
 	if d.defInstall {
 		return
 	}
 
+	// Use the config file provided with -config, it must already exist
+	if len(d.cfPath) > 0 {
+		err := checkConfigPath(d.cfPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// See if the dojoConfig.yml is in the local directory
 	path, err := os.Getwd()
 	if err != nil {
@@ -46,12 +117,31 @@ func readArgs(d *DDConfig) {
 	_, err = os.Stat(path + "/" + d.cf)
 	if err != nil {
 		// No config file found, so create one and exit
-		writeDefaultConfig(d.cf, true)
+		err = writeDefaultConfig(d.cf, true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	d.traceMsg("Reached the end of readArgs")
 }
 
+// checkConfigPath returns an error if the config file at path p doesn't exist
+// or is a directory
+func checkConfigPath(p string) error {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("Unable to use the config file %s provided with -config: %v", p, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("The -config path %s is a directory, it must be a config file like dojoConfig.yml", p)
+	}
+
+	return nil
+}
+
 // printHelp takes no arguements and prints godojo's help content to stdout
 func printHelp() {
 	// Output the help info
@@ -59,23 +149,136 @@ func printHelp() {
 	fmt.Println("Usage of godojo")
 	fmt.Println("")
 	fmt.Println("./godojo [optional arguments]")
+	fmt.Println("./godojo uninstall [optional arguments]")
+	fmt.Println("./godojo check [optional arguments]")
+	fmt.Println("./godojo db-only [optional arguments]")
+	fmt.Println("./godojo list-distros")
 	fmt.Println("")
 	fmt.Println("  [No arguments]")
 	fmt.Println("        Check for a dojoConfig.yml file in the current working directory")
 	fmt.Println("        If found, use those values to configure the installation")
 	fmt.Println("        If NOT found, create a default dojoConfig.yml in the current working directory and exit")
+	fmt.Println("  check")
+	fmt.Println("        Run the install preflight checks without installing, see ./godojo check -help for its arguments")
+	fmt.Println("  db-only")
+	fmt.Println("        Re-run only the database setup for an existing install, see ./godojo db-only -help for its arguments")
+	fmt.Println("  list-distros")
+	fmt.Println("        List the distro releases godojo can install DefectDojo on and the commands each uses")
+	fmt.Println("  uninstall")
+	fmt.Println("        Remove what godojo installed, see ./godojo uninstall -help for its arguments")
+	fmt.Println("  -allow-unprivileged")
+	fmt.Println("        OPTIONAL - Don't exit when godojo isn't run as root or with sudo")
+	fmt.Println("                   For containers or other setups where the user is already root-equivalent")
+	fmt.Println("  -config=/path/to/dojoConfig.yml")
+	fmt.Println("        OPTIONAL - Use the config file at the path provided instead of dojoConfig.yml in the")
+	fmt.Println("                   current working directory, exits if the file doesn't exist")
 	fmt.Println("  -default")
 	fmt.Println("        OPTIONAL - Do an install based on the default dojoConfig.yml values")
 	fmt.Println("                   Must be used alone and without other arguments")
+	fmt.Println("  -dry-run")
+	fmt.Println("        OPTIONAL - Print the commands that would be run and the files that would be downloaded")
+	fmt.Println("                   without running or downloading them")
+	fmt.Println("  -force-extract")
+	fmt.Println("        OPTIONAL - Extract the release tarball even if the same tarball was already extracted")
+	fmt.Println("                   into the source directory by an earlier run")
 	fmt.Println("  -help, -h")
 	fmt.Println("        Print this help message and exit, ignoring all other arguments")
-	fmt.Println("  -version, -v")
-	fmt.Println("        Print the version and exit, ignoring all other arguments")
+	fmt.Println("  -insecure-skip-verify")
+	fmt.Println("        OPTIONAL - Don't verify the TLS certificates of the release, clone or PyPI servers.  For lab")
+	fmt.Println("                   use only, use CABundle in the config to trust a private CA instead")
+	fmt.Println("  -log-file=/path/to/godojo.log")
+	fmt.Println("        OPTIONAL - Also write every log message and all OS command output to the file provided")
+	fmt.Println("                   with timestamps, creating any missing parent directories")
+	fmt.Println("  -log-format=[text|json]")
+	fmt.Println("        OPTIONAL - Format of the entries in the install log file, defaults to text")
+	fmt.Println("                   With json, each entry is an object with timestamp, level and message fields")
+	fmt.Println("  -metrics=/path/to/metrics.csv")
+	fmt.Println("        OPTIONAL - Write the start and end timestamps and duration of each phase that ran to the file")
+	fmt.Println("                   provided when godojo exits, even if the install fails.  Each row also has the result,")
+	fmt.Println("                   the distro, its release and the DefectDojo version so runs across hosts can be compared")
+	fmt.Println("                   It's written as CSV with a header row, or as a JSON array if the file ends in .json")
+	fmt.Println("  -no-color")
+	fmt.Println("        OPTIONAL - Turn off the ANSI colors used for section, status, warning and error messages")
+	fmt.Println("                   and the progress spinner.  Colors are off by default when NO_COLOR is set,")
+	fmt.Println("                   TERM is dumb or output isn't a terminal, e.g. log aggregators and CI logs")
+	fmt.Println("  -no-rollback")
+	fmt.Println("        OPTIONAL - Leave what a failed install changed in place for debugging.  By default the changes")
+	fmt.Println("                   made by this run, e.g. the extracted source, a newly created database and systemd")
+	fmt.Println("                   units, are undone in reverse order when a phase fails")
+	fmt.Println("  -offline")
+	fmt.Println("        OPTIONAL - Install without godojo making any HTTP or git network call, failing if one would be")
+	fmt.Println("                   made.  Release installs need LocalTarball and source installs an existing clone")
+	fmt.Println("                   Note: OS packages and Python requirements still need reachable mirrors")
+	fmt.Println("  -phase=bootstrap,download")
+	fmt.Println("        OPTIONAL - Run only the install phases listed, in install order, even if an earlier run")
+	fmt.Println("                   completed them.  Phases they depend on must be listed too or already done")
+	for _, p := range installPhases {
+		fmt.Printf("                     %-13s %s\n", p.name, p.desc)
+	}
+	fmt.Println("  -quiet")
+	fmt.Println("        OPTIONAL - Replace the progress spinner with plain start and end status lines")
+	fmt.Println("                   This is the default when output isn't a terminal, e.g. CI logs")
+	fmt.Println("                   With -trace-to-syslog nothing is shown on the console, the install is only")
+	fmt.Println("                   recorded in syslog and the log files")
+	fmt.Println("  -report=/path/to/report.json")
+	fmt.Println("        OPTIONAL - Write a JSON summary of the run to the file provided when godojo exits, even if the")
+	fmt.Println("                   install fails, is interrupted or times out.  It has the status, exit code and first error,")
+	fmt.Println("                   the DefectDojo version or commit, the distro and each phase's result and duration")
+	fmt.Println("  -restart")
+	fmt.Println("        OPTIONAL - Run every install phase from the start, by default phases like bootstrap and")
+	fmt.Println("                   download completed by an earlier failed run are skipped")
+	fmt.Println("  -skip-bootstrap")
+	fmt.Println("        OPTIONAL - Skip installing the OS packages godojo needs to bootstrap the install, for hosts")
+	fmt.Println("                   or images where they are already installed.  All later phases still run")
+	fmt.Println("  -skip-mirror-check")
+	fmt.Println("        OPTIONAL - Don't check the apt, dnf, zypper, pacman or portage package mirror can be reached")
+	fmt.Println("                   quickly before the OS package commands run.  By default a slow or unreachable")
+	fmt.Println("                   mirror is warned about with the connect time, e.g. for hosts using a proxy")
+	fmt.Println("  -syslog-all")
+	fmt.Println("        OPTIONAL - With -trace-to-syslog, send status, warning and error messages to syslog as well")
+	fmt.Println("  -syslog-facility=[user|daemon|local0...local7]")
+	fmt.Println("        OPTIONAL - Syslog facility used with -trace-to-syslog, defaults to user")
+	fmt.Println("  -syslog-tag=godojo")
+	fmt.Println("        OPTIONAL - Syslog tag used with -trace-to-syslog, defaults to godojo")
+	fmt.Println("  -timeout-overall=90m")
+	fmt.Println("        OPTIONAL - Stop the install and exit non-zero if it's still running after the duration provided,")
+	fmt.Println("                   cancelling any running command, download or clone and removing what it partially wrote")
+	fmt.Println("                   Each command's own timeout from CmdTimeoutMinutes still applies.  Defaults to no limit")
+	fmt.Println("  -trace-redact")
+	fmt.Println("        OPTIONAL - Replace the configured passwords, tokens and keys with *** in the console output,")
+	fmt.Println("                   log files and command output log even if Install.Redact is false")
+	fmt.Println("  -trace-to-syslog")
+	fmt.Println("        OPTIONAL - Also send trace messages to the local syslog daemon, console output is unchanged")
+	fmt.Println("                   unless -quiet or Install.Quiet is also set, then nothing is shown on the console")
+	fmt.Println("                   If syslog isn't available, godojo warns and continues without it")
+	fmt.Println("  -upgrade")
+	fmt.Println("        OPTIONAL - Replace an existing install in Install.Root of a different DefectDojo version")
+	fmt.Println("                   than Install.Version, by default godojo exits rather than mix the two versions")
+	fmt.Println("                   Running DefectDojo services are stopped first and started again once it's in place")
+	fmt.Println("  -verbose, -v")
+	fmt.Println("        OPTIONAL - Show verbose messages like the URLs used, each download attempt and each command")
+	fmt.Println("                   run between the status messages.  Give it twice (-v -v) or use -vv to also")
+	fmt.Println("                   show the trace messages that are otherwise only written to the log")
+	fmt.Println("  -version")
+	fmt.Println("        Print the version, git commit and build date then exit, ignoring all other arguments")
+	fmt.Println("  -yes")
+	fmt.Println("        OPTIONAL - Don't prompt for confirmation before destructive steps like replacing the existing")
+	fmt.Println("                   source in Install.Root or dropping an existing database.  Needed when stdin")
+	fmt.Println("                   isn't a terminal as godojo aborts rather than take those steps unconfirmed")
 	fmt.Println("")
 	fmt.Println("  Note #1: GNU-style arguments like --name are also supported")
 	fmt.Println("")
 	fmt.Println("  Note #2: Any of the configuration values can be overridden with an environmental variable")
 	fmt.Println("")
+	fmt.Println("Exit codes:")
+	fmt.Println("   0  Success")
+	fmt.Println("   1  Unexpected or uncategorized error")
+	fmt.Println("  10  The OS or distro isn't supported")
+	fmt.Println("  20  Downloading DefectDojo failed, including a checksum mismatch or no checksum to verify it against")
+	fmt.Println("  30  A supported Python version wasn't found")
+	fmt.Println("  40  Installing, setting up or connecting to the database failed")
+	fmt.Println(" 130  The install was interrupted")
+	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("$ ./godojo")
 	fmt.Println("     (Either creates a default config file or installs based on the config file in the same directory)")
@@ -84,3 +287,34 @@ func printHelp() {
 	// TODO Consider an example of overriding with an env variable
 	fmt.Println("")
 }
+
+// verbosity is a flag.Value that raises the -verbose level by step each time
+// the flag is given so -v can be stacked, e.g. -v -v
+type verbosity struct {
+	level *int
+	step  int
+}
+
+func (v verbosity) String() string {
+	if v.level == nil {
+		return "0"
+	}
+	return strconv.Itoa(*v.level)
+}
+
+func (v verbosity) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if on {
+		*v.level += v.step
+	}
+
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value like a bool flag
+func (v verbosity) IsBoolFlag() bool {
+	return true
+}
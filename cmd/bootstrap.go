@@ -2,237 +2,691 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/defectdojo/godojo/distros"
 	c "github.com/mtesauro/commandeer"
 	"gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// defaultCloneDepth is the history depth for shallow commit installs when CloneDepth isn't set
+const defaultCloneDepth = 50
+
+// Errors returned by bootstrapInstall, each is wrapped with the target OS details
+var (
+	errBootstrapLookup = errors.New("unable to find commands to bootstrap the target OS")
+	errBootstrapCmds   = errors.New("unable to get the list of commands to bootstrap the target OS")
+	errBootstrapRun    = errors.New("a command to bootstrap the target OS failed")
 )
 
 // bootstrapInstall takes a pointer to a DDConfig struct and a targetOS struct
-// to run the commands necessary to bootstrap the installation
-func bootstrapInstall(d *DDConfig, t *targetOS) {
+// to run the commands necessary to bootstrap the installation.  The returned
+// error wraps one of ErrUnsupportedDistro, errBootstrapLookup, errBootstrapCmds
+// or errBootstrapRun
+func bootstrapInstall(d *DDConfig, t *targetOS) error {
 	d.sectionMsg("Bootstrapping the godojo installer")
 
+	// Get commands for the right distro
+	cBootstrap, err := bootstrapPkg(d, t)
+	if err != nil {
+		return err
+	}
+
+	// Start the spinner
+	d.spin = d.newSpinner("Bootstrapping...")
+	d.spin.Start()
+	// Run the boostrapping commands for the target OS
+	d.traceMsg(fmt.Sprintf("Getting commands to bootstrap %s", t.id))
+	tCmds, err := distros.CmdsForTarget(cBootstrap, t.id)
+	if err != nil {
+		d.spin.Fail()
+		d.traceMsg(fmt.Sprintf("Error getting bootstrap commands was: %+v", err))
+		return fmt.Errorf("%w %s: %v", errBootstrapCmds, t.id, err)
+	}
+
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return fmt.Errorf("%w on %s: %v", errBootstrapRun, t.id, err)
+	}
+	d.spin.Stop()
+	d.statusMsg("Boostraping godojo installer complete")
+
+	return nil
+}
+
+// bootstrapPkg returns the bootstrap command package for the target OS t.  The
+// returned error wraps either ErrUnsupportedDistro or errBootstrapLookup
+func bootstrapPkg(d *DDConfig, t *targetOS) (*c.CmdPkg, error) {
 	// Create new boostrap command package
 	cBootstrap := c.NewPkg("bootstrap")
 
 	// Get commands for the right distro
+	var err error
+	var set string // Command set used for the target, only traced once the lookup succeeds
 	switch {
 	case strings.ToLower(t.distro) == "ubuntu":
 		d.traceMsg("Searching for commands for bootstrapping Ubuntu")
-		err := distros.GetUbuntu(cBootstrap, t.id)
-		if err != nil {
-			fmt.Printf("Error searching for commands to bootstrap target OS %s\n", t.id)
-			os.Exit(1)
-		}
+		err = distros.GetUbuntu(cBootstrap, t.id)
+		set = "Ubuntu apt"
+	case strings.ToLower(t.distro) == "debian":
+		d.traceMsg("Searching for commands for bootstrapping Debian")
+		err = distros.GetDebian(cBootstrap, t.id)
+		set = "Debian apt"
 	case strings.ToLower(t.distro) == "rhel":
 		d.traceMsg("Searching for commands for bootstrapping RHEL")
-		err := distros.GetRHEL(cBootstrap, t.id)
-		if err != nil {
-			fmt.Printf("Error searching for commands to bootstrap target OS %s\n", t.id)
-			os.Exit(1)
-		}
+		err = distros.GetRHEL(cBootstrap, t.id)
+		set = "RHEL family dnf"
+	case strings.ToLower(t.distro) == "amazon":
+		d.traceMsg("Searching for commands for bootstrapping Amazon Linux")
+		err = distros.GetAmazon(cBootstrap, t.id)
+		set = "Amazon Linux yum/dnf"
+	case strings.ToLower(t.distro) == "fedora":
+		d.traceMsg("Searching for commands for bootstrapping Fedora")
+		err = distros.GetFedora(cBootstrap, t.id)
+		set = "Fedora dnf"
+	case strings.ToLower(t.distro) == "arch":
+		d.traceMsg("Searching for commands for bootstrapping Arch Linux")
+		err = distros.GetArch(cBootstrap, t.id)
+		set = "Arch pacman"
+	case strings.ToLower(t.distro) == "gentoo":
+		d.traceMsg("Searching for commands for bootstrapping Gentoo")
+		err = distros.GetGentoo(cBootstrap, t.id)
+		set = "Gentoo emerge"
+	case strings.ToLower(t.distro) == "suse":
+		d.traceMsg("Searching for commands for bootstrapping SUSE Linux")
+		err = distros.GetSUSE(cBootstrap, t.id)
+		set = "SUSE zypper"
 	default:
 		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
 	}
-
-	// Start the spinner
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Bootstrapping..."
-	d.spin.Start()
-	// Run the boostrapping commands for the target OS
-	d.traceMsg(fmt.Sprintf("Getting commands to bootstrap %s", t.id))
-	tCmds, err := distros.CmdsForTarget(cBootstrap, t.id)
 	if err != nil {
-		fmt.Printf("Error getting commands to bootstrap target OS %s\n", t.id)
-		os.Exit(1)
+		d.traceMsg(fmt.Sprintf("Error searching for bootstrap commands was: %+v", err))
+		return nil, fmt.Errorf("%w %s: %v", errBootstrapLookup, t.id, err)
 	}
-
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+	d.traceMsg(fmt.Sprintf("Using the %s command set for %s", set, t.id))
+	if t.distro == "gentoo" {
+		d.traceMsg("Portage builds packages from source so bootstrapping Gentoo may take a while")
 	}
-	d.spin.Stop()
-	d.statusMsg("Boostraping godojo installer complete")
 
+	return cBootstrap, nil
 }
 
-// validPython checks to ensure the correct version of Python is available
-func validPython(d *DDConfig) {
-	d.sectionMsg("Checking for Python 3.11")
-	if checkPythonVersion(d) {
-		d.statusMsg("Python 3.11 found, install can continue")
-	} else {
-		d.errorMsg("Python 3.11 wasn't found, quitting installer\n" +
-			"         Please set PYPATH to a Python 3.11.x installation\n" +
-			"         And re-run godojo like: 'PYPATH=\"/path/to/python3.11\" ./godojo'")
-		os.Exit(1)
+// Errors returned by checkPythonVersion, each matches ErrPythonVersion
+var (
+	errPythonNotFound    = &InstallError{Kind: ErrPythonVersion, Err: errors.New("unable to find the python binary")}
+	errPythonCmd         = &InstallError{Kind: ErrPythonVersion, Err: errors.New("failed to run the python binary")}
+	errPythonParse       = &InstallError{Kind: ErrPythonVersion, Err: errors.New("unable to parse the python version")}
+	errPythonUnsupported = &InstallError{Kind: ErrPythonVersion, Err: errors.New("unsupported python version")}
+)
+
+// validPython checks to ensure the correct version of Python is available,
+// returning an error matching ErrPythonVersion if it isn't
+func validPython(d *DDConfig) error {
+	d.sectionMsg(fmt.Sprintf("Checking for Python %s", pythonRange(d)))
+	ok, err := checkPythonVersion(d)
+	if !ok {
+		return &InstallError{Kind: ErrPythonVersion, Err: fmt.Errorf("A supported Python version wasn't found, quitting installer\n"+
+			"         Error was: %+v\n"+
+			"         Please set PYPATH or PythonCandidates to a Python %s installation\n"+
+			"         And re-run godojo like: 'PYPATH=\"/path/to/python3\" ./godojo'", err, pythonRange(d))}
 	}
+	d.statusMsg(fmt.Sprintf("Python %s found, install can continue", pythonRange(d)))
+
+	return nil
 }
 
 // checkPythonVersion verifies that python3 is availble on the install target
-func checkPythonVersion(d *DDConfig) bool {
+// and returns true if it is within the configured PythonMin and PythonMax.
+// With PythonCandidates set, PyPath is set to the first candidate that is.
+// The returned error wraps one of errPythonNotFound, errPythonCmd,
+// errPythonParse or errPythonUnsupported
+func checkPythonVersion(d *DDConfig) (bool, error) {
+	if len(d.conf.Install.PythonCandidates) > 0 {
+		return selectPython(d)
+	}
+
 	// DefectDojo is now Python 3+, lets make sure that's installed
-	_, err := exec.LookPath("python3")
+	pyPath, err := exec.LookPath(d.conf.Options.PyPath)
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to find python binary in the path. Error was: %+v", err))
-		os.Exit(1)
+		return false, fmt.Errorf("%w at %s: %v", errPythonNotFound, d.conf.Options.PyPath, err)
+	}
+	comparePyPath(d, pyPath)
+	_, err = pythonVersion(d, pyPath)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// selectPython takes a pointer to a DDConfig struct and sets PyPath to the
+// first of the PythonCandidates, followed by the common Python install
+// locations, that is a supported Python version.  Every candidate tried is
+// logged and returned in the error if none of them are supported.
+func selectPython(d *DDConfig) (bool, error) {
+	var tried []string
+	var last error
+	for _, c := range pythonCandidates(d) {
+		pyPath, err := exec.LookPath(c)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Python candidate %+v not found: %+v", c, err))
+			continue
+		}
+		v, err := pythonVersion(d, pyPath)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Python candidate %+v skipped: %+v", c, err))
+			if last != nil {
+				tried = append(tried, fmt.Sprintf("%v; ", last))
+			}
+			last = fmt.Errorf("%s: %w", c, err)
+			continue
+		}
+
+		d.conf.Options.PyPath = pyPath
+		d.statusMsg(fmt.Sprintf("Using Python %s at %s, the first supported candidate", v, pyPath))
+		return true, nil
+	}
+
+	if last == nil {
+		return false, fmt.Errorf("%w, none of the PythonCandidates or common Python locations exist", errPythonNotFound)
+	}
+	// Wrap the last candidate's error so its kind is kept
+	return false, fmt.Errorf("none of the Python candidates found are supported: %s%w", strings.Join(tried, ""), last)
+}
+
+// pythonCandidates returns the PythonCandidates followed by the common Python
+// install locations, newest Python first, without duplicates
+func pythonCandidates(d *DDConfig) []string {
+	l := append([]string{}, d.conf.Install.PythonCandidates...)
+	for _, v := range []string{"3.13", "3.12", "3.11"} {
+		l = append(l, "/usr/bin/python"+v, "/usr/local/bin/python"+v)
+	}
+	l = append(l, "/usr/local/bin/python3", "/usr/bin/python3")
+	pyenv := os.Getenv("PYENV_ROOT")
+	if home, err := os.UserHomeDir(); len(pyenv) == 0 && err == nil {
+		pyenv = filepath.Join(home, ".pyenv")
+	}
+	if len(pyenv) > 0 {
+		l = append(l, filepath.Join(pyenv, "shims", "python3"))
+	}
+
+	var out []string
+	for _, c := range l {
+		if !inList(out, c) {
+			out = append(out, c)
+		}
 	}
 
-	// Execute the python3 command with --version to get the version
-	runCmd := exec.Command(d.conf.Options.PyPath, "--version")
+	return out
+}
+
+// pythonVersion runs the Python interpreter at pyPath and returns its version
+// if it is within the configured PythonMin and PythonMax
+func pythonVersion(d *DDConfig, pyPath string) (string, error) {
+	// Execute the exact interpreter the install will use with --version to get the version
+	runCmd := exec.CommandContext(d.ctx, pyPath, "--version")
 
 	// Run command and gather its output
 	cmdOut, err := runCmd.CombinedOutput()
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Failed to run python3 command, error was: %+v", err))
-		os.Exit(1)
+		return "", fmt.Errorf("%w %s: %v", errPythonCmd, pyPath, err)
 	}
 
-	// Parse command output for the strings we need
+	// Parse command output for the strings we need, which looks like "Python 3.11.4"
 	lines := bytes.Split(cmdOut, []byte("\n"))
-	line := strings.Split(string(lines[0]), " ")
+	line := strings.Fields(string(lines[0]))
+	if len(line) < 2 || line[0] != "Python" {
+		return "", fmt.Errorf("%w from output %q", errPythonParse, string(lines[0]))
+	}
 	pyVer := line[1]
+	d.traceMsg(fmt.Sprintf("Python version found at %+v was %+v", pyPath, pyVer))
+	major, minor, err := parsePyVer(pyVer)
+	if err != nil {
+		return "", fmt.Errorf("%w from output %q: %v", errPythonParse, string(lines[0]), err)
+	}
+
+	// Compare the version found to the supported range
+	minMaj, minMin, err := parsePyVer(d.conf.Install.PythonMin)
+	if err != nil {
+		return "", fmt.Errorf("invalid PythonMin of %q configured: %w", d.conf.Install.PythonMin, err)
+	}
+	if major < minMaj || (major == minMaj && minor < minMin) {
+		return "", fmt.Errorf("%w %s found, supported versions are %s", errPythonUnsupported, pyVer, pythonRange(d))
+	}
+	if len(d.conf.Install.PythonMax) > 0 {
+		maxMaj, maxMin, err := parsePyVer(d.conf.Install.PythonMax)
+		if err != nil {
+			return "", fmt.Errorf("invalid PythonMax of %q configured: %w", d.conf.Install.PythonMax, err)
+		}
+		if major > maxMaj || (major == maxMaj && minor > maxMin) {
+			return "", fmt.Errorf("%w %s found, supported versions are %s", errPythonUnsupported, pyVer, pythonRange(d))
+		}
+	}
+
+	return pyVer, nil
+}
+
+// comparePyPath takes a pointer to a DDConfig struct and the path PyPath was
+// found at and warns if it isn't the same interpreter as the python3 on PATH,
+// resolving symlinks for both so the real targets are reported
+func comparePyPath(d *DDConfig, pyPath string) {
+	target, err := filepath.EvalSymlinks(pyPath)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to resolve symlinks for %+v, error was: %+v", pyPath, err))
+		target = pyPath
+	}
+	d.traceMsg(fmt.Sprintf("PyPath %+v resolves to %+v", d.conf.Options.PyPath, target))
+
+	onPath, err := exec.LookPath("python3")
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("No python3 found on PATH, error was: %+v", err))
+		return
+	}
+	targetOnPath, err := filepath.EvalSymlinks(onPath)
+	if err != nil {
+		targetOnPath = onPath
+	}
+	if targetOnPath != target {
+		d.warnMsg(fmt.Sprintf("PyPath %s (%s) isn't the python3 on PATH %s (%s), the install will use PyPath",
+			d.conf.Options.PyPath, target, onPath, targetOnPath))
+	}
+}
+
+// parsePyVer returns the major and minor numbers from a Python version string
+// like 3.11 or 3.11.4
+func parsePyVer(v string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimSpace(v), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("version %q isn't in major.minor form", v)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("major version of %q isn't a number", v)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("minor version of %q isn't a number", v)
+	}
+
+	return major, minor, nil
+}
+
+// pythonRange returns a readable version of the supported Python versions
+func pythonRange(d *DDConfig) string {
+	if len(d.conf.Install.PythonMax) == 0 {
+		return ">=" + d.conf.Install.PythonMin
+	}
 
-	// Return true or false depending on Python version
-	return strings.HasPrefix(pyVer, "3.11")
+	return ">=" + d.conf.Install.PythonMin + ", <=" + d.conf.Install.PythonMax
 }
 
-// downloadDojo takes a ponter to DDConfig and downloads a release or source
-// code depending on the configuration of dojoConfig.yml
-func downloadDojo(d *DDConfig) {
+// getDojo takes a ponter to DDConfig and downloads a release or source code
+// depending on the configuration of dojoConfig.yml
+func getDojo(d *DDConfig) error {
 	d.sectionMsg("Downloading the source for DefectDojo")
 
 	// Determine if a release or Dojo source will be installed
 	d.traceMsg(fmt.Sprintf("Determining if this is a source or release install: SourceInstall is %+v", d.conf.Install.SourceInstall))
-	if d.conf.Install.PullSource {
-		// TODO: Move this to a separate function
-		if d.conf.Install.SourceInstall {
-			// Checkout the Dojo source directly from Github
-			d.traceMsg("Dojo will be installed from source")
-
-			err := getDojoSource(d)
-			if err != nil {
-				d.errorMsg(fmt.Sprintf("Error attempting to install Dojo source was:\n    %+v", err))
-				os.Exit(1)
-			}
-		} else {
-			// Download Dojo source as a Github release tarball
-			d.traceMsg("Dojo will be installed from a release tarball")
-
-			err := getDojoRelease(d)
-			if err != nil {
-				d.errorMsg(fmt.Sprintf("Error attempting to install Dojo from a release tarball was:\n    %+v", err))
-				os.Exit(1)
-			}
+	if d.offline {
+		err := offlineSource(d)
+		if err != nil {
+			return err
 		}
-	} else {
+	}
+	if !d.conf.Install.PullSource {
 		d.statusMsg("No source for DefectDojo downloaded per configuration")
 		d.traceMsg("Source NOT downloaded as PullSource is false")
+		return nil
+	}
+
+	if d.conf.Install.SourceInstall {
+		// Checkout the Dojo source directly from Github
+		d.traceMsg("Dojo will be installed from source")
+		err := getDojoSource(d)
+		if err != nil {
+			return fmt.Errorf("installing the DefectDojo source failed: %w", err)
+		}
+		// There's nothing to extract for source installs so both hooks run once the source is checked out
+		err = runHooks(d, "post-download", d.conf.Install.Hooks.PostDownload)
+		if err != nil {
+			return err
+		}
+		return runHooks(d, "post-extract", d.conf.Install.Hooks.PostExtract)
+	}
+
+	// Download Dojo source as a Github release tarball
+	d.traceMsg("Dojo will be installed from a release tarball")
+	err := getDojoRelease(d)
+	if err != nil {
+		return fmt.Errorf("installing DefectDojo from a release tarball failed: %w", err)
+	}
+
+	// Dry runs return before extractRelease runs the hooks so list them here
+	if d.dryRun {
+		err = runHooks(d, "post-download", d.conf.Install.Hooks.PostDownload)
+		if err != nil {
+			return err
+		}
+		return runHooks(d, "post-extract", d.conf.Install.Hooks.PostExtract)
 	}
+
+	return nil
+}
+
+// offlineSource checks an -offline install has a source that doesn't need the
+// network, either a LocalTarball or already extracted release for release
+// installs or an existing clone for source installs, returning an error if it would need to download DefectDojo
+func offlineSource(d *DDConfig) error {
+	if !d.conf.Install.PullSource {
+		return nil
+	}
+	d.traceMsg("-offline set, checking DefectDojo can be installed without the network")
+	if d.conf.Install.SourceInstall {
+		srcPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+		existing, err := existingSource(srcPath)
+		if err != nil || !existing {
+			return fmt.Errorf("-offline is set but there's no existing DefectDojo clone at %s for a source install", srcPath)
+		}
+		return nil
+	}
+	if len(d.conf.Install.LocalTarball) > 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(d.conf.Install.Root, d.conf.Install.Source)); err != nil {
+		return fmt.Errorf("-offline is set so LocalTarball must be the path to a pre-staged DefectDojo release tarball "+
+			"or the release must already be extracted to %s", filepath.Join(d.conf.Install.Root, d.conf.Install.Source))
+	}
+
+	return nil
 }
 
 // getDojoRelease retrives the supplied version of DefectDojo from the Git repo
 // and places it in the specified dojoSource directory (default is /opt/dojo)
 func getDojoRelease(d *DDConfig) error {
 	d.statusMsg(fmt.Sprintf("Downloading the configured release of DefectDojo => version %+v", d.conf.Install.Version))
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Downloading release..."
+	d.spin = d.newSpinner("Downloading release...")
+
+	// Use the already extracted release for offline installs without a LocalTarball
+	if d.offline && len(d.conf.Install.LocalTarball) == 0 {
+		d.statusMsg("-offline is set, installing the already extracted release at " + filepath.Join(d.conf.Install.Root, d.conf.Install.Source))
+		return nil
+	}
+
+	// Don't mix the files of two versions by extracting over a different one
+	err := checkInstalledVersion(d)
+	if err != nil {
+		return err
+	}
+
+	// Only describe the download for dry runs
+	if d.dryRun && len(d.conf.Install.LocalTarball) > 0 {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would verify the SHA256 checksum of %s", d.conf.Install.LocalTarball))
+		if d.conf.Install.VerifySignature {
+			d.statusMsg(fmt.Sprintf("[dry-run] Would verify the GPG signature %s.asc", d.conf.Install.LocalTarball))
+		}
+		d.statusMsg(fmt.Sprintf("[dry-run] Would extract %s to %s", d.conf.Install.LocalTarball, filepath.Join(d.conf.Install.Root, d.conf.Install.Source)))
+		return nil
+	}
+	if d.dryRun {
+		dwnURL := d.releaseURL + d.conf.Install.Version + ".tar.gz"
+		if d.conf.Install.ReleaseAPI {
+			d.statusMsg(fmt.Sprintf("[dry-run] Would look up release %s at %s", d.conf.Install.Version, d.conf.Install.ReleaseAPIURL))
+			dwnURL = "the release's source tarball"
+			if len(d.conf.Install.ReleaseAsset) > 0 {
+				dwnURL = "the release asset matching " + d.conf.Install.ReleaseAsset
+			}
+		}
+		tarball := d.conf.Install.Root + "/dojo-v" + d.conf.Install.Version + ".tar.gz"
+		d.statusMsg(fmt.Sprintf("[dry-run] Would download %s to %s", dwnURL, tarball))
+		d.statusMsg(fmt.Sprintf("[dry-run] Would verify the SHA256 checksum of %s", tarball))
+		if d.conf.Install.VerifySignature && d.conf.Install.ReleaseAPI {
+			d.statusMsg(fmt.Sprintf("[dry-run] Would verify the GPG signature from the .asc next to %s", dwnURL))
+		} else if d.conf.Install.VerifySignature {
+			d.statusMsg(fmt.Sprintf("[dry-run] Would verify the GPG signature from %s.asc", dwnURL))
+		}
+		d.statusMsg(fmt.Sprintf("[dry-run] Would extract %s to %s", tarball, filepath.Join(d.conf.Install.Root, d.conf.Install.Source)))
+		if !d.conf.Install.KeepTarball {
+			d.statusMsg(fmt.Sprintf("[dry-run] Would remove %s once it's extracted as KeepTarball is false", tarball))
+		}
+		return nil
+	}
 	d.spin.Start()
 
 	// Create the directory to clone the source into if it doesn't exist already
+	// but never somewhere surprising if validateConfig was skipped
+	err = checkRoot(d.conf.Install.Root)
+	if err != nil {
+		return err
+	}
 	d.traceMsg("Creating the Dojo root directory if it doesn't exist already")
-	_, err := os.Stat(d.conf.Install.Root)
+	err = ensureDir(d.conf.Install.Root)
 	if err != nil {
-		// Source directory doesn't exist
-		err = os.MkdirAll(d.conf.Install.Root, 0755)
-		if err != nil {
-			d.traceMsg(fmt.Sprintf("Error creating Dojo root directory was: %+v", err))
-			// TODO: Better handle the case when the repo already exists at that path - maybe?
-			return err
-		}
+		d.traceMsg(fmt.Sprintf("Error creating Dojo root directory was: %+v", err))
+		return err
+	}
+
+	// Use a pre-staged tarball instead of downloading one if configured
+	if len(d.conf.Install.LocalTarball) > 0 {
+		return localRelease(d, d.conf.Install.LocalTarball)
 	}
 
 	// Setup needed info
-	dwnURL := d.releaseURL + d.conf.Install.Version + ".tar.gz"
+	dwnURL, err := releaseDownloadURL(d)
+	if err != nil {
+		return err
+	}
 	tarball := d.conf.Install.Root + "/dojo-v" + d.conf.Install.Version + ".tar.gz"
 	d.traceMsg(fmt.Sprintf("Relese download list is %+v", dwnURL))
 	d.traceMsg(fmt.Sprintf("File path to write tarball is %+v", tarball))
 
+	// Setup a custom http client for downloading the Dojo release
+	// A timeout of 0 means the client will never time out
+	timeout := time.Duration(d.conf.Install.DownloadTimeoutSeconds) * time.Second
+	ddClient, err := newHTTPClient(d, timeout)
+	if err != nil {
+		return err
+	}
+	if timeout == 0 {
+		d.traceMsg("http.Client timeout disabled for release download")
+	} else {
+		d.traceMsg(fmt.Sprintf("http.Client timeout set to %v for release download", timeout))
+	}
+
 	// Check for existing tarball before downloading, might be a re-run of godojo
 	_, err = os.Stat(tarball)
 	if err == nil {
-		// File already downloaded so return early
-		err = extractRelease(d, tarball)
+		// File already downloaded so verify it and return early
+		err = verifyRelease(d, ddClient, dwnURL, tarball, "")
+		if errors.Is(err, ErrChecksumMismatch) {
+			// verifyRelease removed it, likely left by an older godojo that didn't verify before renaming
+			d.statusMsg(fmt.Sprintf("Existing tarball %s didn't match the release checksum, downloading it again", tarball))
+			return downloadTarball(d, ddClient, dwnURL, tarball)
+		}
+		if err != nil {
+			return err
+		}
+		if d.conf.Install.VerifySignature {
+			err = verifySignature(d, ddClient, dwnURL, tarball)
+			if err != nil {
+				return err
+			}
+		}
+		err = extractRelease(d, tarball, "")
 		if err != nil {
 			return err
 		}
 		d.spin.Stop()
 		d.statusMsg("Tarball already downloaded and extracted the DefectDojo release file")
+		cleanTarball(d, tarball)
 		return nil
 	}
 
-	// Setup a custom http client for downloading the Dojo release
-	var ddClient = &http.Client{
-		// Set time to a max of 120 seconds
-		Timeout: time.Second * 120,
+	return downloadTarball(d, ddClient, dwnURL, tarball)
+}
+
+// downloadTarball downloads the release at dwnURL to tarball+".part" and only
+// renames it to tarball once its checksum, and signature if VerifySignature is
+// set, are verified, then extracts it.  On an error the partial file is
+// removed unless ResumeDownload is set and the server supports resuming it.
+func downloadTarball(d *DDConfig, ddClient *http.Client, dwnURL string, tarball string) error {
+	// Make sure the release exists before committing to the full download
+	_, err := headRelease(d, dwnURL)
+	if err != nil {
+		return err
+	}
+
+	// Resume a download that failed partway through on an earlier run
+	part := tarball + ".part"
+	var offset int64
+	if fi, err := os.Stat(part); err == nil && fi.Size() > 0 {
+		if d.conf.Install.ResumeDownload {
+			offset = fi.Size()
+			d.traceMsg(fmt.Sprintf("Found partial download %+v with %d bytes", part, offset))
+		} else {
+			d.traceMsg(fmt.Sprintf("ResumeDownload is false, starting over instead of resuming %+v", part))
+			removePart(d, part)
+		}
 	}
-	d.traceMsg("http.Client timeout set to 120 seconds for release download")
 
 	// Download requested release from Dojo's Github repo
 	d.traceMsg(fmt.Sprintf("Downloading release from %+v", dwnURL))
-	resp, err := ddClient.Get(dwnURL)
-	if resp != nil {
-		defer func() {
-			err := resp.Body.Close()
-			if err != nil {
-				d.traceMsg(fmt.Sprintf("Error closing response.\nError was: %v", err))
-				os.Exit(1)
-			}
-		}()
-	}
+	resp, err := downloadRelease(d, ddClient, dwnURL, offset)
 	if err != nil {
 		d.traceMsg(fmt.Sprintf("Error downloading from %+v", dwnURL))
 		d.traceMsg(fmt.Sprintf("Error downloading was: %+v", err))
 		return err
 	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error closing response.\nError was: %v", err))
+		}
+	}()
+
+	// Append to the partial download if the server honored the Range request,
+	// otherwise start the file over with the full release
+	var out *os.File
+	total := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent && total > 0 {
+		total += offset
+	}
 
-	// TODO: Check for 200 status before moving on
-	d.traceMsg(fmt.Sprintf("Status of http.Client response was %+v", resp.Status))
+	// Make sure the download and extracted release will fit before writing anything
+	remaining := resp.ContentLength
+	if resp.StatusCode != http.StatusPartialContent {
+		// The whole file is downloaded again so the partial file's space is reclaimed
+		remaining -= offset
+	}
+	err = checkDiskSpace(d, d.conf.Install.Root, remaining, total)
+	if err != nil {
+		return err
+	}
 
-	// Create the file handle
-	d.traceMsg("Creating file for downloaded tarball")
-	out, err := os.Create(tarball)
+	if resp.StatusCode == http.StatusPartialContent {
+		d.statusMsg(fmt.Sprintf("Resuming the release download from %s", humanBytes(offset)))
+		out, err = os.OpenFile(part, os.O_WRONLY|os.O_APPEND, 0644)
+	} else {
+		d.traceMsg("Creating file for downloaded tarball")
+		offset = 0
+		out, err = os.Create(part)
+	}
 	if err != nil {
-		d.traceMsg(fmt.Sprintf("Error creating tarball was: %+v", err))
+		d.traceMsg(fmt.Sprintf("Error opening tarball was: %+v", err))
 		return err
 	}
+	d.setPartial(part)
+
+	// Hash the release as it's written so verifying it doesn't need another
+	// pass over the file, a resumed download hashes the bytes already on disk first
+	sum := sha256.New()
+	if offset > 0 {
+		err = hashFile(sum, part)
+		if err != nil {
+			out.Close()
+			d.traceMsg(fmt.Sprintf("Error hashing partial download was: %+v", err))
+			removePart(d, part)
+			return err
+		}
+	}
 
 	// Write the content downloaded into the file
 	d.traceMsg("Writing downloaded content to tarball file")
-	_, err = io.Copy(out, resp.Body)
+	start := time.Now()
+	body := io.TeeReader(resp.Body, sum)
+	cr := newCountingReader(d, newRateLimitedReader(d, body, d.conf.Install.MaxDownloadRate), total)
+	cr.n = offset
+	n, err := io.Copy(out, cr)
 	if err != nil {
 		d.traceMsg(fmt.Sprintf("Error writing file contents was: %+v", err))
+		out.Close()
+		if canResume(d, resp, offset+n) {
+			// Keep the partial download so the next run can resume it
+			d.setPartial("")
+			op := fmt.Sprintf("download stopped after %s, re-run godojo to resume it", humanBytes(offset+n))
+			return &InstallError{Kind: ErrDownloadFailed, Op: op, Err: err}
+		}
+		removePart(d, part)
+		op := fmt.Sprintf("download stopped after %s", humanBytes(offset+n))
+		return &InstallError{Kind: ErrDownloadFailed, Op: op, Err: err}
+	}
+	d.traceMsg(fmt.Sprintf("Downloaded %d bytes in %v", n, time.Since(start).Round(time.Millisecond)))
+	err = out.Close()
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error closing tarball was: %+v", err))
+		removePart(d, part)
+		return err
+	}
+
+	// Verify the download against its SHA256 checksum before it gets the
+	// tarball's name so a re-run never finds a bad tarball, which also catches
+	// a resumed download that didn't line up.  A mismatch removes the file.
+	got := hex.EncodeToString(sum.Sum(nil))
+	err = verifyRelease(d, ddClient, dwnURL, part, got)
+	if err != nil {
+		if !errors.Is(err, ErrChecksumMismatch) {
+			removePart(d, part)
+		}
+		d.setPartial("")
+		return err
+	}
+	if d.conf.Install.VerifySignature {
+		err = verifySignature(d, ddClient, dwnURL, part)
+		if err != nil {
+			removePart(d, part)
+			return err
+		}
+	}
+	err = os.Rename(part, tarball)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error renaming %+v to %+v was: %+v", part, tarball, err))
+		removePart(d, part)
 		return err
 	}
+	d.setPartial("")
 
 	// Extract the tarball to create the Dojo source directory
-	err = extractRelease(d, tarball)
+	err = extractRelease(d, tarball, got)
 	if err != nil {
 		return err
 	}
@@ -240,93 +694,528 @@ func getDojoRelease(d *DDConfig) error {
 	// Successfully extracted the file, return nil
 	d.spin.Stop()
 	d.statusMsg("Successfully downloaded and extracted the DefectDojo release file")
+	cleanTarball(d, tarball)
 	return nil
 }
 
-func extractRelease(d *DDConfig, t string) error {
-	// Extract the tarball to create the Dojo source directory
-	d.traceMsg("Extracting tarball into the Dojo source directory")
-	tb, err := os.Open(t)
+// cleanTarball removes the downloaded release tarball at t unless KeepTarball
+// is set.  It's only called once t is verified and extracted so a failed
+// extraction always leaves the tarball for the next run.
+func cleanTarball(d *DDConfig, t string) {
+	if d.conf.Install.KeepTarball {
+		d.statusMsg("Kept the release tarball " + t)
+		return
+	}
+	d.traceMsg(fmt.Sprintf("KeepTarball is false, removing the release tarball %+v", t))
+	err := os.Remove(t)
+	if err != nil && !os.IsNotExist(err) {
+		// The install itself is fine so only warn
+		d.warnMsg(fmt.Sprintf("Unable to remove the release tarball %s, error was: %+v", t, err))
+		return
+	}
+	d.statusMsg("Removed the release tarball " + t + " as KeepTarball is false")
+}
+
+// canResume returns true if the partial download of n bytes from the response
+// resp is worth keeping for the next run to resume, which needs ResumeDownload
+// set and a server that supports Range requests
+func canResume(d *DDConfig, resp *http.Response, n int64) bool {
+	if !d.conf.Install.ResumeDownload || n <= 0 {
+		return false
+	}
+
+	return resp.StatusCode == http.StatusPartialContent || strings.Contains(resp.Header.Get("Accept-Ranges"), "bytes")
+}
+
+// removePart removes the partial download at part so a later run starts the
+// download over instead of using it
+func removePart(d *DDConfig, part string) {
+	d.traceMsg(fmt.Sprintf("Removing the partial download %+v", part))
+	err := os.Remove(part)
+	if err != nil && !os.IsNotExist(err) {
+		d.traceMsg(fmt.Sprintf("Error removing %+v was: %+v", part, err))
+	}
+	d.setPartial("")
+}
+
+// localRelease verifies and extracts the pre-staged release tarball at path t
+// without downloading anything
+func localRelease(d *DDConfig, t string) error {
+	d.traceMsg(fmt.Sprintf("Using local tarball %+v instead of downloading the release", t))
+	f, err := os.Open(t)
 	if err != nil {
-		d.traceMsg(fmt.Sprintf("Error openging tarball was: %+v", err))
-		return err
+		d.traceMsg(fmt.Sprintf("Error opening local tarball was: %+v", err))
+		return fmt.Errorf("LocalTarball %s doesn't exist or isn't readable: %w", t, err)
 	}
-	err = untar(d, d.conf.Install.Root, tb)
+	f.Close()
+
+	err = verifyLocalRelease(d, t)
 	if err != nil {
-		d.traceMsg(fmt.Sprintf("Error extracting tarball was: %+v", err))
 		return err
 	}
-
-	// Remane source directory to the non-versioned name
-	d.traceMsg("Renaming source directory to the non-versioned name")
-	oldPath := filepath.Join(d.conf.Install.Root, "django-DefectDojo-"+d.conf.Install.Version)
-	newPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
-	err = os.Rename(oldPath, newPath)
+	if d.conf.Install.VerifySignature {
+		err = verifyLocalSignature(d, t)
+		if err != nil {
+			return err
+		}
+	}
+	err = extractRelease(d, t, "")
 	if err != nil {
-		d.traceMsg(fmt.Sprintf("Error renaming Dojo source directory was: %+v", err))
 		return err
 	}
+
+	d.spin.Stop()
+	d.statusMsg("Successfully extracted the local DefectDojo release file")
 	return nil
 }
 
-// Use go-git to checkout latest source - either from a specific commit or HEAD
-// on a branch and places it in the specified dojoSource directory
+// extractRelease extracts the release tarball at path t into the Dojo source
+// directory unless that same tarball was already extracted there by an earlier
+// run.  sum is the SHA256 of t if it was computed while downloading it, or ""
+// to compute it from t for a local or already downloaded tarball.
+func extractRelease(d *DDConfig, t string, sum string) (err error) {
+	if len(sum) == 0 {
+		sum, err = fileSHA256(t)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error computing SHA256 of %+v was: %+v", t, err))
+			return err
+		}
+	}
+	newPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+	if !d.forceExtract && alreadyExtracted(d, sum, newPath) {
+		d.statusMsg("Release tarball already extracted to " + newPath + ", skipping extraction (use -force-extract to override)")
+		return nil
+	}
+
+	// An existing source directory would block the rename below so move it
+	// aside, it's put back if the extraction or a later phase fails
+	saved := ""
+	if _, statErr := os.Stat(newPath); statErr == nil {
+		err = confirmDestructive(d, "replace the existing DefectDojo source at "+newPath)
+		if err != nil {
+			return err
+		}
+		saved, err = saveSource(d, newPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err == nil {
+				return
+			}
+			d.traceMsg(fmt.Sprintf("Putting the existing Dojo source back from %+v", saved))
+			if rsErr := restoreSource(saved, newPath); rsErr != nil {
+				d.errorMsg(fmt.Sprintf("Unable to put the existing DefectDojo source back from %s, error was: %+v", saved, rsErr))
+			}
+		}()
+	}
+
+	// Make sure the extracted release will fit before extracting anything
+	fi, err := os.Stat(t)
+	if err != nil {
+		return err
+	}
+	err = checkDiskSpace(d, d.conf.Install.Root, 0, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	// The tarball is in place and verified but not extracted yet
+	err = runHooks(d, "post-download", d.conf.Install.Hooks.PostDownload)
+	if err != nil {
+		return err
+	}
+
+	// Extract the tarball to create the Dojo source directory
+	d.traceMsg("Extracting tarball into the Dojo source directory")
+	oldPath := filepath.Join(d.conf.Install.Root, extractedDir(d, t))
+	d.setPartial(oldPath)
+	defer d.setPartial("")
+	tb, err := os.Open(t)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error openging tarball was: %+v", err))
+		return err
+	}
+	err = untar(d, d.conf.Install.Root, tb)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error extracting tarball was: %+v", err))
+		return err
+	}
+
+	// Make sure the tarball held DefectDojo before putting it in place
+	err = checkExtractedLayout(oldPath)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Extracted layout check failed: %+v", err))
+		if oldPath != filepath.Clean(d.conf.Install.Root) {
+			if rmErr := os.RemoveAll(oldPath); rmErr != nil {
+				d.traceMsg(fmt.Sprintf("Error removing %+v was: %+v", oldPath, rmErr))
+			}
+		}
+		return err
+	}
+
+	// Remane source directory to the non-versioned name unless the tarball's
+	// top-level directory already is Install.Source
+	if oldPath == newPath {
+		d.traceMsg("Tarball's top-level directory is already the source directory, nothing to rename")
+	} else {
+		d.traceMsg("Renaming source directory to the non-versioned name")
+		err = moveDir(oldPath, newPath)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error renaming Dojo source directory was: %+v", err))
+			return err
+		}
+	}
+	undo := func() error {
+		err := os.Remove(filepath.Join(d.conf.Install.Root, d.extractState))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.RemoveAll(newPath)
+	}
+	if len(saved) > 0 {
+		d.addRollbackDone("put back the DefectDojo source replaced at "+newPath, func() error {
+			err := undo()
+			if err != nil {
+				return err
+			}
+			return restoreSource(saved, newPath)
+		}, func() error { return os.RemoveAll(saved) })
+	} else {
+		d.addRollback("remove the extracted DefectDojo source at "+newPath, undo)
+	}
+
+	// Record the extracted tarball so re-runs can skip extracting it again
+	recordExtract(d, sum)
+
+	return runHooks(d, "post-extract", d.conf.Install.Hooks.PostExtract)
+}
+
+// savedSourceSuffix is added to the name of an existing source directory while
+// a release replaces it
+const savedSourceSuffix = ".godojo-old"
+
+// saveSource moves the existing source directory src aside so a release can be
+// extracted in its place, returning where it was moved to.  A copy left by an
+// earlier run is removed first as src is the newer one.
+func saveSource(d *DDConfig, src string) (string, error) {
+	saved := src + savedSourceSuffix
+	if _, err := os.Lstat(saved); err == nil {
+		d.traceMsg(fmt.Sprintf("Removing %+v left by an earlier run", saved))
+		err = os.RemoveAll(saved)
+		if err != nil {
+			return "", fmt.Errorf("unable to remove %s left by an earlier run: %w", saved, err)
+		}
+	}
+	d.traceMsg(fmt.Sprintf("Moving the existing Dojo source directory to %+v before extracting", saved))
+	err := os.Rename(src, saved)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error moving existing Dojo source directory was: %+v", err))
+		if errors.Is(err, os.ErrPermission) {
+			return "", fmt.Errorf("permission denied moving the existing DefectDojo source at %s aside: %w", src, err)
+		}
+		return "", fmt.Errorf("unable to move the existing DefectDojo source at %s aside: %w", src, err)
+	}
+
+	return saved, nil
+}
+
+// restoreSource replaces the source directory src with the copy saveSource
+// moved to saved
+func restoreSource(saved string, src string) error {
+	err := os.RemoveAll(src)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(saved, src)
+}
+
+// checkExtractedLayout returns an error unless the directory p extracted from
+// a release tarball exists, isn't empty and has DefectDojo's manage.py or dojo
+// directory so an empty or wrong tarball fails before the rename instead of
+// leaving something that isn't DefectDojo as the source directory
+func checkExtractedLayout(p string) error {
+	ents, err := os.ReadDir(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("extracted archive doesn't look like DefectDojo, it didn't contain %s", filepath.Base(p))
+	}
+	if err != nil {
+		return fmt.Errorf("extracted archive doesn't look like DefectDojo, unable to read %s: %w", p, err)
+	}
+	if len(ents) == 0 {
+		return fmt.Errorf("extracted archive doesn't look like DefectDojo, %s is empty", p)
+	}
+	for _, e := range ents {
+		if (e.Name() == "manage.py" && !e.IsDir()) || (e.Name() == "dojo" && e.IsDir()) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("extracted archive doesn't look like DefectDojo, %s has no manage.py or dojo directory", p)
+}
+
+// checkInstalledVersion returns an error if the source directory in
+// Install.Root already holds a different DefectDojo version than
+// Install.Version, unless -upgrade is set to replace it
+func checkInstalledVersion(d *DDConfig) error {
+	src := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+	have := installedVersion(d, src)
+	if len(have) == 0 {
+		return nil
+	}
+	if strings.TrimPrefix(have, "v") == strings.TrimPrefix(d.conf.Install.Version, "v") {
+		d.traceMsg(fmt.Sprintf("Existing install at %+v is the configured version %+v", src, have))
+		return nil
+	}
+	if d.upgrade {
+		d.statusMsg(fmt.Sprintf("-upgrade is set, replacing DefectDojo %s at %s with version %s", have, src, d.conf.Install.Version))
+		return nil
+	}
+
+	return fmt.Errorf("%s already holds DefectDojo %s but Install.Version is %s.  Re-run godojo with -upgrade "+
+		"to replace it or set Install.Version to %s", src, have, d.conf.Install.Version, have)
+}
+
+// dojoVersion matches the version set in the DefectDojo app's __init__.py
+var dojoVersion = regexp.MustCompile(`(?m)^__version__\s*=\s*['"]([^'"]+)['"]`)
+
+// installedVersion returns the version of the DefectDojo source at src read
+// from the app's __init__.py, or "" if there's no install there or it has no version
+func installedVersion(d *DDConfig, src string) string {
+	p := filepath.Join(src, d.conf.Install.App, "__init__.py")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("No existing DefectDojo version found, reading %+v returned: %+v", p, err))
+		return ""
+	}
+	m := dojoVersion.FindSubmatch(b)
+	if m == nil {
+		d.traceMsg(fmt.Sprintf("No __version__ found in %+v", p))
+		return ""
+	}
+
+	return string(m[1])
+}
+
+// extractedDir returns the name of the top-level directory in the tarball t,
+// falling back to the django-DefectDojo-<version> GitHub has historically used
+// if the tarball doesn't have exactly one top-level directory
+func extractedDir(d *DDConfig, t string) string {
+	exp := "django-DefectDojo-" + d.conf.Install.Version
+	tb, err := os.Open(t)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to open %+v to find its top-level directory, error was: %+v", t, err))
+		return exp
+	}
+	defer tb.Close()
+
+	top, err := tarTopDir(tb)
+	if err != nil || len(top) == 0 {
+		d.traceMsg(fmt.Sprintf("No single top-level directory found in the tarball (error was %+v), using %+v", err, exp))
+		return exp
+	}
+	if top != exp {
+		d.traceMsg(fmt.Sprintf("Tarball's top-level directory is %+v instead of %+v", top, exp))
+	}
+
+	return top
+}
+
+// alreadyExtracted returns true if the state file in Install.Root records the
+// checksum sum as the last extracted tarball and the source directory src exists
+func alreadyExtracted(d *DDConfig, sum string, src string) bool {
+	b, err := os.ReadFile(filepath.Join(d.conf.Install.Root, d.extractState))
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("No extraction state found, error was: %+v", err))
+		return false
+	}
+	if strings.TrimSpace(string(b)) != sum {
+		d.traceMsg("Extraction state is for a different tarball, extracting")
+		return false
+	}
+	_, err = os.Stat(src)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Extraction state matches but %+v doesn't exist, extracting", src))
+		return false
+	}
+
+	return true
+}
+
+// recordExtract writes the checksum sum of the extracted tarball to the state
+// file in Install.Root.  Failing to write it only means the next run re-extracts.
+func recordExtract(d *DDConfig, sum string) {
+	p := filepath.Join(d.conf.Install.Root, d.extractState)
+	err := os.WriteFile(p, []byte(sum+"\n"), 0644)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to write extraction state to %+v, error was: %+v", p, err))
+	}
+}
+
+// Use go-git to checkout latest source - either from a specific commit, a tag or
+// HEAD on a branch and places it in the specified dojoSource directory
 // (default is /opt/dojo)
 func getDojoSource(d *DDConfig) error {
-	d.statusMsg("Downloading DefectDojo source as a branch or commit from the repo directly")
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Downloading DefectDojo source..."
+	d.statusMsg("Downloading DefectDojo source as a branch, tag or commit from the repo directly")
+	d.spin = d.newSpinner("Downloading DefectDojo source...")
+
+	// Use the existing clone as is for offline installs, offlineSource checked it exists
+	if d.offline {
+		srcPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+		d.statusMsg(fmt.Sprintf("-offline is set, installing the existing DefectDojo source at %s without updating it", srcPath))
+		if d.dryRun {
+			return nil
+		}
+		return writeSourceManifest(d, srcPath)
+	}
+
+	// Only describe the clone for dry runs
+	if d.dryRun {
+		err := checkSourceRef(d)
+		if err != nil {
+			return err
+		}
+		ref := "branch " + d.conf.Install.SourceBranch
+		switch {
+		case len(d.conf.Install.SourceCommit) > 0:
+			ref = "commit " + d.conf.Install.SourceCommit
+		case len(d.conf.Install.SourceTag) > 0:
+			ref = "tag " + d.conf.Install.SourceTag
+		}
+		d.statusMsg(fmt.Sprintf("[dry-run] Would clone %s at %s into %s", d.cloneURL, ref,
+			filepath.Join(d.conf.Install.Root, d.conf.Install.Source)))
+		return nil
+	}
 
 	// Create the directory to clone the source into if it doesn't exist already
 	d.traceMsg("Creating source directory if it doesn't exist already")
 	srcPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
-	_, err := os.Stat(srcPath)
+	err := ensureDir(srcPath)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error creating Dojo source directory was: %+v", err))
+		return err
+	}
+
+	// Setup go-git to use any configured proxy
+	err = setGitProxy(d)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error setting up the proxy for git was: %+v", err))
+		return err
+	}
+
+	// Use credentials for private forks if any are configured
+	auth, err := gitAuth(d)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error setting up git credentials was: %+v", err))
+		return err
+	}
+
+	// Check out a specific branch, tag or commit - but only one of those
+	// Precedence is commit > tag > branch though setting more than one is an error
+	err = checkSourceRef(d)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error checking out Dojo source was: %+v", err))
+		return err
+	}
+
+	// Make sure the branch or tag exists before cloning
+	err = checkRemoteRef(d, auth)
 	if err != nil {
-		// Source directory doesn't exist
-		err = os.MkdirAll(srcPath, 0755)
+		d.traceMsg(fmt.Sprintf("Error checking the Dojo source ref was: %+v", err))
+		return err
+	}
+
+	// Update an existing clone instead of cloning again, e.g. on a re-run of godojo
+	existing, err := existingSource(srcPath)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error checking for existing Dojo source was: %+v", err))
+		return err
+	}
+	if existing {
+		d.statusMsg(fmt.Sprintf("Existing DefectDojo source found at %s, updating it", srcPath))
+		d.spin.Start()
+		err = updateDojoSource(d, srcPath, auth)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error updating existing Dojo source was: %+v", err))
+			return err
+		}
+		d.spin.Stop()
+		err = writeSourceManifest(d, srcPath)
 		if err != nil {
-			d.traceMsg(fmt.Sprintf("Error creating Dojo source directory was: %+v", err))
-			// TODO: Better handle the case when the repo already exists at that path - maybe?
 			return err
 		}
+		d.statusMsg("Successfully updated the existing DefectDojo source")
+		return nil
 	}
 
-	// Check out a specific branch or commit - but only one of those
-	// In the case that both commit and branch are set to non-empty strings,
-	// the configured commit will win (aka only the commit alone will be done)
-	d.traceMsg("Determining if a commit or branch will be checked out of the repo")
-	if len(d.conf.Install.SourceCommit) > 0 {
-		// Commit is set, so it will be used and branch ignored
+	d.traceMsg("Determining if a commit, tag or branch will be checked out of the repo")
+	depth := 0
+	if d.conf.Install.ShallowClone {
+		depth = 1
+	}
+	d.traceMsg(fmt.Sprintf("ShallowClone is %+v", d.conf.Install.ShallowClone))
+	switch {
+	case len(d.conf.Install.SourceCommit) > 0:
+		// Commit is set, so clone the repo and checkout that commit
 		d.statusMsg(fmt.Sprintf("Dojo will be installed from commit %+v", d.conf.Install.SourceCommit))
 		d.spin.Start()
 
+		// Shallow clones for a commit need enough history to reach that commit
+		if depth > 0 {
+			depth = d.conf.Install.CloneDepth
+			if depth < 1 {
+				depth = defaultCloneDepth
+			}
+		}
+
 		// Do the initial clone of DefectDojo from Github
-		d.traceMsg(fmt.Sprintf("Initial clone of %+v", d.cloneURL))
-		repo, err := git.PlainClone(srcPath, false, &git.CloneOptions{URL: d.cloneURL})
+		d.traceMsg(fmt.Sprintf("Initial clone of %+v with depth %d (0 is full history)", d.cloneURL, depth))
+		repo, err := cloneWithRetry(d, srcPath, &git.CloneOptions{URL: d.cloneURL, Auth: auth, Depth: depth})
 		if err != nil {
 			d.traceMsg(fmt.Sprintf("Error cloning the DefectDojo repo was: %+v", err))
 			return err
 		}
 
+		// Make sure the commit was cloned before checking it out
+		err = checkCommit(repo, d.conf.Install.SourceCommit, d.cloneURL, depth)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error finding the commit in the clone was: %+v", err))
+			return err
+		}
+
 		// Setup the working tree for checking out a particular commit
 		d.traceMsg("Setting up the working tree to checkout the commit")
-		wk, _ := repo.Worktree()
-		// TODO: consider checking the err above that is removed with _
+		wk, err := repo.Worktree()
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error getting the working tree was: %+v", err))
+			return err
+		}
 		err = wk.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(d.conf.Install.SourceCommit)})
 		if err != nil {
-			fmt.Printf("Error checking out was %+v\n", err)
 			d.traceMsg(fmt.Sprintf("Error checking out was: %+v", err))
 			return err
 		}
 
-	} else {
-		if len(d.conf.Install.SourceBranch) == 0 {
-			// Handle the case that both source commit and branch are wonky
-			err = fmt.Errorf("Both source commit and branch have empty or nonsensical values configured.\n"+
-				"  Source commit was configured as %s and branch was configured as %s", d.conf.Install.SourceCommit, d.conf.Install.SourceBranch)
-			d.traceMsg(fmt.Sprintf("Error checking out Dojo source was: %+v", err))
+	case len(d.conf.Install.SourceTag) > 0:
+		// Tag is set, so clone just that tag
+		d.statusMsg(fmt.Sprintf("DefectDojo will be installed from tag %+v", d.conf.Install.SourceTag))
+		d.spin.Start()
+
+		d.traceMsg(fmt.Sprintf("Checking out tag %+v", d.conf.Install.SourceTag))
+		_, err = cloneWithRetry(d, srcPath, &git.CloneOptions{
+			URL:           d.cloneURL,
+			Auth:          auth,
+			ReferenceName: plumbing.ReferenceName("refs/tags/" + d.conf.Install.SourceTag),
+			SingleBranch:  true,
+			Depth:         depth,
+		})
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error checking out tag was: %+v", err))
 			return err
 		}
+
+	default:
 		d.statusMsg(fmt.Sprintf("DefectDojo will be installed from %+v branch", d.conf.Install.SourceBranch))
 		d.spin.Start()
 
@@ -334,10 +1223,12 @@ func getDojoSource(d *DDConfig) error {
 		// Note: Branch and tag references are a bit odd, see https://github.com/src-d/go-git/blob/master/_examples/branch/main.go#L33
 		//       However, the installer appends the necessary string to the 'normal' branch name
 		d.traceMsg(fmt.Sprintf("Checking out branch %+v", d.conf.Install.SourceBranch))
-		_, err = git.PlainClone(srcPath, false, &git.CloneOptions{
+		_, err = cloneWithRetry(d, srcPath, &git.CloneOptions{
 			URL:           d.cloneURL,
+			Auth:          auth,
 			ReferenceName: plumbing.ReferenceName("refs/heads/" + d.conf.Install.SourceBranch),
 			SingleBranch:  true,
+			Depth:         depth,
 		})
 		if err != nil {
 			d.traceMsg(fmt.Sprintf("Error checking out branch was: %+v", err))
@@ -346,8 +1237,404 @@ func getDojoSource(d *DDConfig) error {
 
 	}
 
-	// Successfully checked out the configured source, return nil
+	// Report the size of the git data when a shallow clone was done
+	if d.conf.Install.ShallowClone {
+		size, err := dirSize(filepath.Join(srcPath, ".git"))
+		if err == nil {
+			d.traceMsg(fmt.Sprintf("Shallow clone with depth %d downloaded %.1f MB of git data instead of the full history", depth, float64(size)/1024/1024))
+		}
+	}
+
+	// Successfully checked out the configured source, record what was checked out
 	d.spin.Stop()
+	err = writeSourceManifest(d, srcPath)
+	if err != nil {
+		return err
+	}
 	d.statusMsg("Successfully checked out the configured DefectDojo source")
 	return nil
 }
+
+// checkCommit returns an error if the commit c isn't in repo, cloned from u
+// with depth (0 is full history).  A commit from a fork or one force-pushed
+// away from u won't be in the clone, so a branch install is suggested instead.
+func checkCommit(repo *git.Repository, c string, u string, depth int) error {
+	_, err := repo.CommitObject(plumbing.NewHash(c))
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return fmt.Errorf("unable to look up commit %s in the clone of %s: %w", c, u, err)
+	}
+	if depth > 0 {
+		return fmt.Errorf("commit %s is not reachable in a shallow clone with depth %d, "+
+			"increase CloneDepth or set ShallowClone to false: %w", c, depth, err)
+	}
+
+	return fmt.Errorf("commit %s not present in %s, it may be from a fork or have been force-pushed away, "+
+		"check the commit or set SourceBranch and remove SourceCommit to install from a branch instead: %w", c, u, err)
+}
+
+// cloneWithRetry clones the repo described by o into p, retrying with the
+// same attempts and backoff as release downloads.  A failed clone can leave a
+// partial repo behind that would make the next attempt fail so p is emptied
+// between attempts.
+func cloneWithRetry(d *DDConfig, p string, o *git.CloneOptions) (*git.Repository, error) {
+	var repo *git.Repository
+	d.setPartial(p)
+	defer d.setPartial("")
+	err := retryGit(d, "clone of "+d.cloneURL, func() error {
+		var err error
+		repo, err = git.PlainCloneContext(d.ctx, p, false, o)
+		return err
+	}, func() {
+		d.traceMsg(fmt.Sprintf("Removing the partial clone at %+v before retrying", p))
+		err := os.RemoveAll(p)
+		if err == nil {
+			err = ensureDir(p)
+		}
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Unable to clean up the partial clone, error was: %+v", err))
+		}
+	})
+	if err != nil {
+		return nil, &InstallError{Kind: ErrDownloadFailed, Op: "clone of " + d.cloneURL, Err: err}
+	}
+	d.addRollback("remove the DefectDojo source cloned to "+p, func() error { return os.RemoveAll(p) })
+
+	return repo, nil
+}
+
+// retryGit runs the git operation op up to DownloadAttempts times, waiting
+// DownloadDelay before the first retry and doubling it for each retry after.
+// cleanup runs before each retry.  Errors retrying won't fix, like bad
+// credentials or a ref that doesn't exist, are returned straight away.  The
+// spinner shows the attempt count.
+func retryGit(d *DDConfig, what string, op func() error, cleanup func()) error {
+	attempts, delay := retrySettings(d)
+	d.traceMsg(fmt.Sprintf("Git %s will be attempted up to %d times with a base delay of %v", what, attempts, delay))
+
+	var err error
+	for i := 1; i <= attempts; i++ {
+		if d.spin != nil {
+			d.spin.setPrefix(fmt.Sprintf("Downloading DefectDojo source (attempt %d of %d)...", i, attempts))
+		}
+		err = op()
+		if err == nil {
+			return nil
+		}
+		d.traceMsg(fmt.Sprintf("Git %s attempt %d of %d failed: %+v", what, i, attempts, err))
+		if !gitRetryable(err) || d.ctx.Err() != nil {
+			d.waitIfStopping()
+			return err
+		}
+
+		if i < attempts {
+			cleanup()
+			d.traceMsg(fmt.Sprintf("Waiting %v before retrying", delay))
+			select {
+			case <-time.After(delay):
+			case <-d.ctx.Done():
+				d.waitIfStopping()
+				return fmt.Errorf("%s cancelled: %w", what, d.ctx.Err())
+			}
+			delay *= 2
+		}
+	}
+
+	return fmt.Errorf("%s failed after %d attempts: %w", what, attempts, err)
+}
+
+// gitRetryable returns false for git errors that will fail the same way on
+// every attempt so only network problems are retried
+func gitRetryable(err error) bool {
+	for _, e := range []error{
+		transport.ErrAuthenticationRequired,
+		transport.ErrAuthorizationFailed,
+		transport.ErrInvalidAuthMethod,
+		transport.ErrRepositoryNotFound,
+		transport.ErrEmptyRemoteRepository,
+		plumbing.ErrReferenceNotFound,
+		git.ErrRepositoryAlreadyExists,
+	} {
+		if errors.Is(err, e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sourceManifest is the record of the DefectDojo source checked out for a
+// source install written to Install.Root
+type sourceManifest struct {
+	Commit    string `json:"commit"`    // Commit HEAD resolved to after the checkout
+	RefType   string `json:"ref_type"`  // branch, tag or commit
+	Ref       string `json:"ref"`       // Configured branch, tag or commit
+	Remote    string `json:"remote"`    // URL the source was cloned from
+	Timestamp string `json:"timestamp"` // When the source was checked out
+	Godojo    string `json:"godojo"`    // Version of godojo that did the checkout
+}
+
+// writeSourceManifest records the commit HEAD of the checked out source at p
+// points to, along with the configured ref and remote, in Install.Root so
+// there is a record of exactly what was installed even for a branch or tag
+func writeSourceManifest(d *DDConfig, p string) error {
+	repo, err := git.PlainOpen(p)
+	if err != nil {
+		return fmt.Errorf("unable to open the DefectDojo source to record its commit: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("unable to resolve HEAD of the DefectDojo source: %w", err)
+	}
+
+	m := sourceManifest{
+		Commit:    head.Hash().String(),
+		RefType:   "branch",
+		Ref:       d.conf.Install.SourceBranch,
+		Remote:    d.redactatron(redactURL(d.cloneURL), d.redact),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Godojo:    d.ver,
+	}
+	switch {
+	case len(d.conf.Install.SourceCommit) > 0:
+		m.RefType, m.Ref = "commit", d.conf.Install.SourceCommit
+	case len(d.conf.Install.SourceTag) > 0:
+		m.RefType, m.Ref = "tag", d.conf.Install.SourceTag
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	d.commit = m.Commit
+	mp := filepath.Join(d.conf.Install.Root, d.sourceManifest)
+	err = os.WriteFile(mp, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write the source manifest %s: %w", mp, err)
+	}
+	d.statusMsg(fmt.Sprintf("DefectDojo %s %s is at commit %s, recorded in %s", m.RefType, m.Ref, m.Commit, mp))
+
+	return nil
+}
+
+// redactURL removes any password from the URL u, e.g. a token embedded in
+// an https CloneURL.  URLs that don't parse, like git@host:repo, are returned as is.
+func redactURL(u string) string {
+	p, err := url.Parse(u)
+	if err != nil || p.User == nil {
+		return u
+	}
+
+	return p.Redacted()
+}
+
+// existingSource returns true if path p is a git repo from an earlier run.  An
+// error is returned if p exists but isn't an empty directory or a git repo
+func existingSource(p string) (bool, error) {
+	_, err := os.Stat(filepath.Join(p, ".git"))
+	if err == nil {
+		return true, nil
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) > 0 {
+		return false, fmt.Errorf("%s already exists but isn't a git repo.\n"+
+			"  Please remove it and re-run godojo", p)
+	}
+
+	return false, nil
+}
+
+// updateDojoSource takes a pointer to a DDConfig struct and the path to an
+// existing clone of DefectDojo and the git credentials, if any, then fetches
+// the configured commit, tag or branch and checks it out
+func updateDojoSource(d *DDConfig, p string, auth transport.AuthMethod) error {
+	err := requireOnline(d, "fetch from "+d.cloneURL)
+	if err != nil {
+		return err
+	}
+	d.traceMsg(fmt.Sprintf("Opening existing git repo at %+v", p))
+	repo, err := git.PlainOpen(p)
+	if err != nil {
+		return err
+	}
+
+	// Only fetch what is needed for the configured ref
+	depth := 0
+	if d.conf.Install.ShallowClone {
+		depth = 1
+	}
+	var spec gitconfig.RefSpec
+	switch {
+	case len(d.conf.Install.SourceCommit) > 0:
+		spec = "+refs/heads/*:refs/remotes/origin/*"
+		if depth > 0 {
+			depth = d.conf.Install.CloneDepth
+			if depth < 1 {
+				depth = defaultCloneDepth
+			}
+		}
+	case len(d.conf.Install.SourceTag) > 0:
+		spec = gitconfig.RefSpec("+refs/tags/" + d.conf.Install.SourceTag + ":refs/tags/" + d.conf.Install.SourceTag)
+	default:
+		spec = gitconfig.RefSpec("+refs/heads/" + d.conf.Install.SourceBranch + ":refs/remotes/origin/" + d.conf.Install.SourceBranch)
+	}
+	d.traceMsg(fmt.Sprintf("Fetching %+v with depth %d (0 is full history)", spec, depth))
+	err = retryGit(d, "fetch from "+d.cloneURL, func() error {
+		err := repo.FetchContext(d.ctx, &git.FetchOptions{
+			RemoteName: "origin",
+			Auth:       auth,
+			RefSpecs:   []gitconfig.RefSpec{spec},
+			Depth:      depth,
+			Force:      true,
+		})
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil
+		}
+		return err
+	}, func() {})
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error fetching was: %+v", err))
+		return err
+	}
+
+	// Determine the commit to checkout
+	var h plumbing.Hash
+	switch {
+	case len(d.conf.Install.SourceCommit) > 0:
+		// Make sure the fetch brought in the commit before checking it out
+		err = checkCommit(repo, d.conf.Install.SourceCommit, d.cloneURL, depth)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error finding the commit in the existing clone was: %+v", err))
+			return err
+		}
+		h = plumbing.NewHash(d.conf.Install.SourceCommit)
+	case len(d.conf.Install.SourceTag) > 0:
+		ref, err := repo.Tag(d.conf.Install.SourceTag)
+		if err != nil {
+			return fmt.Errorf("unable to find tag %s: %w", d.conf.Install.SourceTag, err)
+		}
+		h = ref.Hash()
+		// Annotated tags point to a tag object rather than a commit
+		tag, err := repo.TagObject(h)
+		if err == nil {
+			cm, err := tag.Commit()
+			if err != nil {
+				return err
+			}
+			h = cm.Hash
+		}
+	default:
+		ref, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", d.conf.Install.SourceBranch), true)
+		if err != nil {
+			return fmt.Errorf("unable to find branch %s: %w", d.conf.Install.SourceBranch, err)
+		}
+		h = ref.Hash()
+	}
+
+	d.traceMsg(fmt.Sprintf("Checking out %+v", h))
+	wk, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	err = wk.Checkout(&git.CheckoutOptions{Hash: h, Force: true})
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error checking out was: %+v", err))
+		return err
+	}
+
+	return nil
+}
+
+// checkSourceRef takes a pointer to a DDConfig struct and returns an error
+// unless exactly one of SourceCommit, SourceTag or SourceBranch is set
+func checkSourceRef(d *DDConfig) error {
+	set := make([]string, 0, 3)
+	if len(d.conf.Install.SourceCommit) > 0 {
+		set = append(set, "SourceCommit")
+	}
+	if len(d.conf.Install.SourceTag) > 0 {
+		set = append(set, "SourceTag")
+	}
+	if len(d.conf.Install.SourceBranch) > 0 {
+		set = append(set, "SourceBranch")
+	}
+
+	switch len(set) {
+	case 0:
+		return fmt.Errorf("One of source commit, tag or branch must be configured for a source install")
+	case 1:
+		if len(d.conf.Install.SourceCommit) > 0 && !fullHash.MatchString(d.conf.Install.SourceCommit) {
+			return fmt.Errorf("Source commit %s isn't a full 40 character commit hash", d.conf.Install.SourceCommit)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Only one of source commit, tag or branch can be configured for a source install, but %s are all set.\n"+
+		"  Source commit was configured as %s, tag as %s and branch as %s", strings.Join(set, ", "),
+		d.conf.Install.SourceCommit, d.conf.Install.SourceTag, d.conf.Install.SourceBranch)
+}
+
+// fullHash matches a full 40 character hex git commit hash
+var fullHash = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// maxRefsListed is the most branches or tags listed when a SourceBranch or
+// SourceTag isn't found on the remote
+const maxRefsListed = 20
+
+// checkRemoteRef takes a pointer to a DDConfig struct and the git credentials,
+// if any, and confirms the configured SourceBranch or SourceTag exists on the
+// remote before cloning so a typo gets a clear error listing what is available.
+// Commits can't be listed from the remote so only their format is checked by
+// checkSourceRef.
+func checkRemoteRef(d *DDConfig, auth transport.AuthMethod) error {
+	var kind, kinds, want string
+	switch {
+	case len(d.conf.Install.SourceCommit) > 0:
+		return nil
+	case len(d.conf.Install.SourceTag) > 0:
+		kind, kinds, want = "tag", "tags", d.conf.Install.SourceTag
+	default:
+		kind, kinds, want = "branch", "branches", d.conf.Install.SourceBranch
+	}
+
+	err := requireOnline(d, "list the refs at "+d.cloneURL)
+	if err != nil {
+		return err
+	}
+	d.traceMsg(fmt.Sprintf("Listing the refs at %+v to check %s %+v exists", d.cloneURL, kind, want))
+	rem := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{d.cloneURL}})
+	refs, err := rem.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return fmt.Errorf("Unable to list the branches and tags at %s: %w", d.cloneURL, err)
+	}
+
+	found := make([]string, 0, len(refs))
+	for _, r := range refs {
+		n := r.Name()
+		if (kind == "branch" && !n.IsBranch()) || (kind == "tag" && !n.IsTag()) {
+			continue
+		}
+		if n.Short() == want {
+			d.traceMsg(fmt.Sprintf("Found %s %+v at %+v", kind, want, d.cloneURL))
+			return nil
+		}
+		found = append(found, n.Short())
+	}
+	sort.Strings(found)
+
+	avail := strings.Join(found, ", ")
+	if len(found) > maxRefsListed {
+		avail = strings.Join(found[:maxRefsListed], ", ") + fmt.Sprintf(" and %d more", len(found)-maxRefsListed)
+	}
+	if len(found) == 0 {
+		avail = "none"
+	}
+
+	return fmt.Errorf("%s %s not found at %s; available %s are %s", kind, want, d.cloneURL, kinds, avail)
+}
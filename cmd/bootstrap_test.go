@@ -0,0 +1,720 @@
+package cmd
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defectdojo/godojo/distros"
+	c "github.com/mtesauro/commandeer"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestExtractReleaseDiscoversTopDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		top      string
+		existing bool // An earlier install is in the source directory
+	}{
+		{name: "expected directory", top: "django-DefectDojo-2.30.0"},
+		{name: "different directory", top: "DefectDojo-django-DefectDojo-1a2b3c4"},
+		{name: "source directory", top: "django-DefectDojo"},
+		{name: "source directory replacing an install", top: "django-DefectDojo", existing: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+			entries := []tarEntry{
+				{name: tc.top + "/", kind: tar.TypeDir},
+				{name: tc.top + "/manage.py", kind: tar.TypeReg, body: "# manage.py\n"},
+			}
+			if err := os.WriteFile(tarball, makeTarball(t, entries).Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.existing {
+				makeTree(t, filepath.Join(root, "django-DefectDojo"), map[string]string{"manage.py": "# old\n"})
+			}
+
+			d := &DDConfig{quiet: true, yes: true, extractState: ".godojo-extracted"}
+			d.Info = log.New(io.Discard, "", 0)
+			d.conf.Install.Root = root
+			d.conf.Install.Source = "django-DefectDojo"
+			d.conf.Install.Version = "2.30.0"
+
+			if err := extractRelease(d, tarball, ""); err != nil {
+				t.Fatalf("Expected no error extracting a tarball with top-level directory %s, got %v", tc.top, err)
+			}
+			if b, _ := os.ReadFile(filepath.Join(root, "django-DefectDojo", "manage.py")); string(b) != "# manage.py\n" {
+				t.Errorf("Expected manage.py from the release, got %q", string(b))
+			}
+		})
+	}
+}
+
+func TestTarTopDirAmbiguous(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "two top-level directories",
+			entries: []tarEntry{
+				{name: "one/file.txt", kind: tar.TypeReg, body: "one"},
+				{name: "two/file.txt", kind: tar.TypeReg, body: "two"},
+			},
+		},
+		{
+			name: "top-level file",
+			entries: []tarEntry{
+				{name: "django-DefectDojo-2.30.0/manage.py", kind: tar.TypeReg, body: "# manage.py\n"},
+				{name: "README.md", kind: tar.TypeReg, body: "readme"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			top, err := tarTopDir(makeTarball(t, tc.entries))
+			if err != nil {
+				t.Fatalf("Expected no error reading the tarball, got %v", err)
+			}
+			if top != "" {
+				t.Errorf("Expected no top-level directory, got %q", top)
+			}
+		})
+	}
+}
+
+func TestExtractReleaseReplacesExistingSource(t *testing.T) {
+	root := t.TempDir()
+	tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+	entries := []tarEntry{
+		{name: "django-DefectDojo-2.30.0/", kind: tar.TypeDir},
+		{name: "django-DefectDojo-2.30.0/manage.py", kind: tar.TypeReg, body: "# new\n"},
+	}
+	if err := os.WriteFile(tarball, makeTarball(t, entries).Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	makeTree(t, filepath.Join(root, "django-DefectDojo"), map[string]string{"manage.py": "# old\n", "stale.py": "# stale\n"})
+
+	d := &DDConfig{quiet: true, yes: true, extractState: ".godojo-extracted"}
+	d.Info = log.New(io.Discard, "", 0)
+	d.conf.Install.Root = root
+	d.conf.Install.Source = "django-DefectDojo"
+	d.conf.Install.Version = "2.30.0"
+
+	if err := extractRelease(d, tarball, ""); err != nil {
+		t.Fatalf("Expected the existing source to be replaced, got %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, "django-DefectDojo", "manage.py")); string(b) != "# new\n" {
+		t.Errorf("Expected manage.py from the release, got %q", string(b))
+	}
+	if _, err := os.Stat(filepath.Join(root, "django-DefectDojo", "stale.py")); !os.IsNotExist(err) {
+		t.Errorf("Expected files from the old source to be removed, got %v", err)
+	}
+
+	// The old source is kept for a rollback until the install succeeds
+	saved := filepath.Join(root, "django-DefectDojo"+savedSourceSuffix)
+	if _, err := os.Stat(filepath.Join(saved, "stale.py")); err != nil {
+		t.Errorf("Expected the old source to be kept at %s until the install succeeds, got %v", saved, err)
+	}
+	finishRollback(d)
+	if _, err := os.Stat(saved); !os.IsNotExist(err) {
+		t.Errorf("Expected the old source to be removed once the install succeeded, got %v", err)
+	}
+}
+
+func TestExtractReleaseRestoresExistingSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		fail    bool // Roll back after a later phase fails instead of failing the extraction
+	}{
+		{
+			name:    "bad layout",
+			entries: []tarEntry{{name: "django-DefectDojo-2.30.0/README.md", kind: tar.TypeReg, body: "not DefectDojo\n"}},
+		},
+		{
+			name:    "later phase fails",
+			entries: []tarEntry{{name: "django-DefectDojo-2.30.0/manage.py", kind: tar.TypeReg, body: "# new\n"}},
+			fail:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+			if err := os.WriteFile(tarball, makeTarball(t, tc.entries).Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			src := filepath.Join(root, "django-DefectDojo")
+			makeTree(t, src, map[string]string{"manage.py": "# old\n"})
+
+			d := &DDConfig{quiet: true, yes: true, extractState: ".godojo-extracted", phaseState: ".godojo-phases"}
+			d.Info = log.New(io.Discard, "", 0)
+			d.conf.Install.Root = root
+			d.conf.Install.Source = "django-DefectDojo"
+			d.conf.Install.Version = "2.30.0"
+
+			err := extractRelease(d, tarball, "")
+			if tc.fail {
+				if err != nil {
+					t.Fatalf("Expected no error extracting the release, got %v", err)
+				}
+				rollback(d)
+			} else if err == nil {
+				t.Fatal("Expected the extraction to fail")
+			}
+			if b, _ := os.ReadFile(filepath.Join(src, "manage.py")); string(b) != "# old\n" {
+				t.Errorf("Expected the old source to be put back, got manage.py %q", string(b))
+			}
+			if _, err := os.Stat(src + savedSourceSuffix); !os.IsNotExist(err) {
+				t.Errorf("Expected no saved copy once the old source is put back, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractReleaseUsesDownloadSum(t *testing.T) {
+	root := t.TempDir()
+	tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+	entries := []tarEntry{
+		{name: "django-DefectDojo-2.30.0/", kind: tar.TypeDir},
+		{name: "django-DefectDojo-2.30.0/manage.py", kind: tar.TypeReg, body: "# manage.py\n"},
+	}
+	if err := os.WriteFile(tarball, makeTarball(t, entries).Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DDConfig{quiet: true, extractState: ".godojo-extracted"}
+	d.Info = log.New(io.Discard, "", 0)
+	d.conf.Install.Root = root
+	d.conf.Install.Source = "django-DefectDojo"
+	d.conf.Install.Version = "2.30.0"
+
+	// The sum from the download is recorded as is instead of hashing the tarball again
+	sum := strings.Repeat("ab", sha256.Size)
+	if err := extractRelease(d, tarball, sum); err != nil {
+		t.Fatalf("Expected no error extracting the release, got %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(root, d.extractState)); strings.TrimSpace(string(b)) != sum {
+		t.Errorf("Expected the download's SHA256 %s in the extraction state, got %q", sum, string(b))
+	}
+}
+
+func TestExtractReleaseRejectsBadLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{name: "empty tarball", entries: nil},
+		{
+			name: "empty top-level directory",
+			entries: []tarEntry{
+				{name: "django-DefectDojo-2.30.0/", kind: tar.TypeDir},
+			},
+		},
+		{
+			name: "bogus tarball",
+			entries: []tarEntry{
+				{name: "something-else-1.0/", kind: tar.TypeDir},
+				{name: "something-else-1.0/README.md", kind: tar.TypeReg, body: "not DefectDojo\n"},
+				{name: "something-else-1.0/setup.py", kind: tar.TypeReg, body: "# setup.py\n"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+			if err := os.WriteFile(tarball, makeTarball(t, tc.entries).Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d := &DDConfig{quiet: true, extractState: ".godojo-extracted"}
+			d.Info = log.New(io.Discard, "", 0)
+			d.conf.Install.Root = root
+			d.conf.Install.Source = "django-DefectDojo"
+			d.conf.Install.Version = "2.30.0"
+
+			err := extractRelease(d, tarball, "")
+			if err == nil || !strings.Contains(err.Error(), "extracted archive doesn't look like DefectDojo") {
+				t.Fatalf("Expected the layout check to fail, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(root, "django-DefectDojo")); !os.IsNotExist(err) {
+				t.Errorf("Expected no source directory after a failed layout check, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(root, d.extractState)); !os.IsNotExist(err) {
+				t.Errorf("Expected no extraction state after a failed layout check, got %v", err)
+			}
+		})
+	}
+}
+
+// failingRelease returns a handler serving a release of size bytes that
+// drops the connection after sending half of it, advertising Range support
+// if ranges is true
+func failingRelease(t *testing.T, size int, ranges bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		if ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(make([]byte, size/2))
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Unable to hijack the connection: %v", err)
+			return
+		}
+		conn.Close()
+	}
+}
+
+func TestDownloadTarballMidCopyFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		resume bool
+		ranges bool
+		keep   bool
+	}{
+		{name: "resume disabled", resume: false, ranges: true, keep: false},
+		{name: "server can't resume", resume: true, ranges: false, keep: false},
+		{name: "resumable", resume: true, ranges: true, keep: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(failingRelease(t, 64*1024, tc.ranges))
+			defer srv.Close()
+
+			d := newTestConfig()
+			d.conf.Install.DownloadAttempts = 1
+			d.conf.Install.ResumeDownload = tc.resume
+			tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+
+			err := downloadTarball(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", tarball)
+			if !errors.Is(err, ErrDownloadFailed) {
+				t.Fatalf("Expected ErrDownloadFailed for a dropped download, got %v", err)
+			}
+			if _, err := os.Stat(tarball); !os.IsNotExist(err) {
+				t.Errorf("Expected no tarball after a failed download, got %v", err)
+			}
+			_, err = os.Stat(tarball + ".part")
+			if tc.keep && err != nil {
+				t.Errorf("Expected the partial download to be kept for resuming, got %v", err)
+			}
+			if !tc.keep && !os.IsNotExist(err) {
+				t.Errorf("Expected the partial download to be removed, got %v", err)
+			}
+			if len(d.partialFile()) > 0 {
+				t.Errorf("Expected no partial file left to clean up on interrupt, got %s", d.partialFile())
+			}
+		})
+	}
+}
+
+func TestDownloadTarballChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not the release"))
+	}))
+	defer srv.Close()
+
+	d := newTestConfig()
+	d.conf.Install.DownloadAttempts = 1
+	d.conf.Install.ResumeDownload = true
+	d.conf.Install.Checksum = strings.Repeat("0", 64)
+	tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+
+	err := downloadTarball(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", tarball)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch, got %v", err)
+	}
+	for _, f := range []string{tarball, tarball + ".part"} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed after a checksum mismatch, got %v", filepath.Base(f), err)
+		}
+	}
+}
+
+func TestCleanTarball(t *testing.T) {
+	for _, keep := range []bool{true, false} {
+		t.Run("KeepTarball "+strconv.FormatBool(keep), func(t *testing.T) {
+			d := newTestConfig()
+			d.conf.Install.KeepTarball = keep
+			tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+			if err := os.WriteFile(tarball, []byte("the release"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cleanTarball(d, tarball)
+			_, err := os.Stat(tarball)
+			if keep && err != nil {
+				t.Errorf("Expected the tarball to be kept, got %v", err)
+			}
+			if !keep && !os.IsNotExist(err) {
+				t.Errorf("Expected the tarball to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadTarballKeepsTarballOnFailedExtract(t *testing.T) {
+	entries := []tarEntry{
+		{name: "something-else-1.0/", kind: tar.TypeDir},
+		{name: "something-else-1.0/README.md", kind: tar.TypeReg, body: "not DefectDojo\n"},
+	}
+	body := makeTarball(t, entries).Bytes()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	sum := sha256.Sum256(body)
+	d := newTestConfig()
+	d.extractState = ".godojo-extracted"
+	d.conf.Install.Root = root
+	d.conf.Install.Source = "django-DefectDojo"
+	d.conf.Install.Version = "2.30.0"
+	d.conf.Install.DownloadAttempts = 1
+	d.conf.Install.Checksum = hex.EncodeToString(sum[:])
+	d.conf.Install.KeepTarball = false
+	tarball := filepath.Join(root, "dojo-v2.30.0.tar.gz")
+
+	err := downloadTarball(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", tarball)
+	if err == nil || !strings.Contains(err.Error(), "extracted archive doesn't look like DefectDojo") {
+		t.Fatalf("Expected the layout check to fail, got %v", err)
+	}
+	if _, err := os.Stat(tarball); err != nil {
+		t.Errorf("Expected the tarball to be kept after a failed extraction, got %v", err)
+	}
+}
+
+func TestDownloadReleaseRateLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		retried bool
+	}{
+		{name: "429 with Retry-After", headers: map[string]string{"Retry-After": "0"}, status: http.StatusTooManyRequests, retried: true},
+		{name: "403 with rate limit reset", headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1"},
+			status: http.StatusForbidden, retried: true},
+		{name: "reset too far away", headers: map[string]string{"Retry-After": "3600"}, status: http.StatusTooManyRequests, retried: false},
+		{name: "403 that isn't a rate limit", headers: map[string]string{}, status: http.StatusForbidden, retried: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					for k, v := range tc.headers {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tc.status)
+					return
+				}
+				_, _ = w.Write([]byte("release"))
+			}))
+			defer srv.Close()
+
+			d := newTestConfig()
+			d.conf.Install.DownloadAttempts = 2
+			resp, err := downloadRelease(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", 0)
+			if !tc.retried {
+				if !errors.Is(err, ErrDownloadFailed) {
+					t.Fatalf("Expected ErrDownloadFailed without a retry, got %v", err)
+				}
+				if calls != 1 {
+					t.Errorf("Expected 1 request, got %d", calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the rate limited download to be retried, got %v", err)
+			}
+			resp.Body.Close()
+			if calls != 2 {
+				t.Errorf("Expected 2 requests, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestGithubAuthOnlyForGithub(t *testing.T) {
+	d := newTestConfig()
+	d.conf.Install.GithubToken = "gh-token"
+	for u, want := range map[string]string{
+		"https://github.com/DefectDojo/django-DefectDojo/archive/2.30.0.tar.gz": "Bearer gh-token",
+		"https://api.github.com/repos/DefectDojo/django-DefectDojo/releases":    "Bearer gh-token",
+		"https://mirror.example.com/github.com/2.30.0.tar.gz":                   "",
+		"https://notgithub.com/2.30.0.tar.gz":                                   "",
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := githubAuth(d, req); err != nil {
+			t.Fatalf("Expected no error adding the Github token, got %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("Expected an Authorization header of %q for %s, got %q", want, u, got)
+		}
+	}
+}
+
+func TestReleaseAuthOnlyForReleaseHost(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		if !ok || u != "dojo" || p != "mirror-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		got = append(got, r.Method+" "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	d := newTestConfig()
+	d.releaseURL = srv.URL + "/releases/"
+	d.conf.Install.ReleaseUser = "dojo"
+	d.conf.Install.ReleasePassEnv = "GODOJO_TEST_RELEASE_PASS"
+	t.Setenv("GODOJO_TEST_RELEASE_PASS", "mirror-pass")
+
+	u := d.releaseURL + "2.30.0.tar.gz"
+	if _, err := headRelease(d, u); err != nil {
+		t.Fatalf("Expected the HEAD check to send the credentials, got %v", err)
+	}
+	for _, f := range []string{u, u + ".sha256", u + ".asc"} {
+		resp, err := getWithContext(d, srv.Client(), f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected the credentials to be sent for %s, got %s", f, resp.Status)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("Expected 4 authenticated requests, got %v", got)
+	}
+	if !strings.Contains(d.redactatron("pass is mirror-pass", true), "***") {
+		t.Error("Expected the release password to be redacted")
+	}
+
+	// The credentials are only for the ReleaseURL host
+	req, err := http.NewRequest(http.MethodGet, "https://other.example.com/2.30.0.tar.gz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := requestAuth(d, req); err != nil {
+		t.Fatal(err)
+	}
+	if h := req.Header.Get("Authorization"); len(h) > 0 {
+		t.Errorf("Expected no credentials for another host, got %q", h)
+	}
+
+	// A missing password is an error rather than an unauthenticated request
+	t.Setenv("GODOJO_TEST_RELEASE_PASS", "")
+	_, err = headRelease(d, u)
+	if err == nil || !strings.Contains(err.Error(), "ReleasePassEnv") {
+		t.Errorf("Expected an error for the empty ReleasePassEnv, got %v", err)
+	}
+}
+
+func TestReleaseDownloadURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the v prefixed tag exists to check both are tried
+		if r.URL.Path != "/tags/v2.30.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name": "v2.30.0", "tarball_url": "https://api.github.com/tarball/v2.30.0",
+			"assets": [{"name": "dojo-2.30.0.zip", "browser_download_url": "https://github.com/dl/dojo-2.30.0.zip"},
+			{"name": "dojo-2.30.0.tar.gz", "browser_download_url": "https://github.com/dl/dojo-2.30.0.tar.gz"}]}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name  string
+		api   bool
+		asset string
+		want  string
+		err   bool
+	}{
+		{name: "API disabled", api: false, want: "https://example.com/archive/2.30.0.tar.gz"},
+		{name: "source tarball", api: true, want: "https://api.github.com/tarball/v2.30.0"},
+		{name: "asset pattern", api: true, asset: "*.tar.gz", want: "https://github.com/dl/dojo-2.30.0.tar.gz"},
+		{name: "no matching asset", api: true, asset: "*.rpm", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newTestConfig()
+			d.releaseURL = "https://example.com/archive/"
+			d.conf.Install.Version = "2.30.0"
+			d.conf.Install.ReleaseAPI = tc.api
+			d.conf.Install.ReleaseAPIURL = srv.URL + "/tags/"
+			d.conf.Install.ReleaseAsset = tc.asset
+
+			got, err := releaseDownloadURL(d)
+			if tc.err {
+				if !errors.Is(err, ErrDownloadFailed) {
+					t.Fatalf("Expected ErrDownloadFailed, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// initRepo returns a new git repo in dir with a single commit and its hash
+func initRepo(t *testing.T, dir string) (*git.Repository, plumbing.Hash) {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "README.md"), []byte("dojo\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wk, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wk.Add("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := wk.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "dojo", Email: "dojo@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repo, h
+}
+
+func TestCheckCommit(t *testing.T) {
+	repo, h := initRepo(t, t.TempDir())
+
+	err := checkCommit(repo, h.String(), "https://example.com/dojo.git", 0)
+	if err != nil {
+		t.Fatalf("expected commit %s to be found, got %v", h, err)
+	}
+
+	bogus := "0123456789abcdef0123456789abcdef01234567"
+	err = checkCommit(repo, bogus, "https://example.com/dojo.git", 0)
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound for a bogus commit, got %v", err)
+	}
+	for _, want := range []string{"commit " + bogus + " not present in https://example.com/dojo.git", "SourceBranch"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the error to contain %q, got %v", want, err)
+		}
+	}
+
+	err = checkCommit(repo, bogus, "https://example.com/dojo.git", 50)
+	if err == nil || !strings.Contains(err.Error(), "shallow clone with depth 50") {
+		t.Fatalf("expected a shallow clone error, got %v", err)
+	}
+}
+
+func TestUpdateDojoSourceMissingCommit(t *testing.T) {
+	upstream := t.TempDir()
+	_, h := initRepo(t, upstream)
+	clone := t.TempDir()
+	_, err := git.PlainClone(clone, false, &git.CloneOptions{URL: upstream})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestConfig()
+	d.cloneURL = upstream
+	d.conf.Install.DownloadAttempts = 1
+	bogus := "0123456789abcdef0123456789abcdef01234567"
+	d.conf.Install.SourceCommit = bogus
+	err = updateDojoSource(d, clone, nil)
+	if !errors.Is(err, plumbing.ErrObjectNotFound) || !strings.Contains(err.Error(), "commit "+bogus+" not present in "+upstream) {
+		t.Fatalf("Expected the missing commit error for the existing clone, got %v", err)
+	}
+
+	d.conf.Install.SourceCommit = h.String()
+	err = updateDojoSource(d, clone, nil)
+	if err != nil {
+		t.Fatalf("Expected commit %s to be checked out of the existing clone, got %v", h, err)
+	}
+}
+
+func TestParallelCmdsForTarget(t *testing.T) {
+	cp := c.NewPkg("installerprep")
+	if err := distros.GetDebian(cp, "debian:12"); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := distros.CmdsForTarget(cp, "debian:12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, cmd := range cmds {
+		if want := i < 2; cmd.Parallel != want {
+			t.Errorf("Expected Parallel %v for %q, got %v", want, cmd.Cmd, cmd.Parallel)
+		}
+	}
+
+	// The returned commands are copies so marking one doesn't change the command set
+	cmds[2].Parallel = true
+	again, err := distros.CmdsForTarget(cp, "debian:12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[2].Parallel {
+		t.Error("Expected changing a returned command not to change the built-in command set")
+	}
+
+	// Command sets without parallel commands have none marked
+	bp := c.NewPkg("bootstrap")
+	if err := distros.GetDebian(bp, "debian:12"); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err = distros.CmdsForTarget(bp, "debian:12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Parallel {
+			t.Errorf("Expected no parallel bootstrap commands, got %q", cmd.Cmd)
+		}
+	}
+}
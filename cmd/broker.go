@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defectdojo/godojo/distros"
+	c "github.com/mtesauro/commandeer"
+)
+
+// Celery brokers godojo can install with Install.Broker.Engine
+const (
+	brokerRedis    = "Redis"
+	brokerRabbitMQ = "RabbitMQ"
+)
+
+// brokerWait is how long an installed broker has to start answering
+const brokerWait = 30 * time.Second
+
+// brokerPkg is how an installed broker runs on a distro
+type brokerPkg struct {
+	service string // Service the broker runs as
+	conf    string // Main config file of the broker
+}
+
+// brokerLookups are the functions returning the broker install commands for
+// each distro
+var brokerLookups = map[string]func(*c.CmdPkg, string, string) error{
+	"ubuntu": distros.GetUbuntuBroker,
+	"debian": distros.GetDebianBroker,
+	"rhel":   distros.GetRHELBroker,
+	"amazon": distros.GetAmazonBroker,
+	"fedora": distros.GetFedoraBroker,
+	"arch":   distros.GetArchBroker,
+	"gentoo": distros.GetGentooBroker,
+	"suse":   distros.GetSUSEBroker,
+}
+
+// setupBroker takes a pointer to a DDConfig struct and a pointer to the target
+// OS struct and installs, configures and starts the celery broker set in
+// Install.Broker.Engine, then checks it answers.  For an External broker only
+// the check is done.  It's skipped when Engine is blank, leaving the broker to
+// the Settings.CeleryBroker values.
+func setupBroker(d *DDConfig, t *targetOS) error {
+	b := d.conf.Install.Broker
+	if len(b.Engine) == 0 {
+		d.verboseMsg("Install.Broker.Engine is blank, leaving the celery broker to the Settings.CeleryBroker values")
+		return nil
+	}
+	u, err := celeryBroker(d)
+	if err != nil {
+		return err
+	}
+	if b.External {
+		d.sectionMsg("Checking the external " + b.Engine + " celery broker")
+		err = waitForBroker(d, u, 0)
+		if err != nil {
+			return fmt.Errorf("%w\n  Check the broker is running and Install.Broker is correct", err)
+		}
+		return nil
+	}
+
+	d.sectionMsg("Installing the " + b.Engine + " celery broker")
+	tCmds, err := brokerCmds(d, t)
+	if err != nil {
+		return err
+	}
+	d.spin = d.newSpinner("Installing " + b.Engine + "...")
+	d.spin.Start()
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return err
+	}
+	d.spin.Stop()
+
+	pkg := brokerPackage(b.Engine, t)
+	err = configureBroker(d, pkg)
+	if err != nil {
+		return fmt.Errorf("Unable to configure %s in %s, error was: %w", b.Engine, pkg.conf, err)
+	}
+	err = sendCmd(d, d.cmdLogger, "if [ -d /run/systemd/system ]; then systemctl enable "+pkg.service+" && systemctl restart "+pkg.service+
+		"; else rc-update add "+pkg.service+" default && rc-service "+pkg.service+" restart; fi",
+		"Unable to start "+b.Engine, true)
+	if err != nil {
+		return err
+	}
+	if b.Engine == brokerRabbitMQ {
+		err = addRabbitUser(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = waitForBroker(d, u, brokerWait)
+	if err != nil {
+		return fmt.Errorf("%w\n  Check why with: journalctl -u %s", err, pkg.service)
+	}
+	d.statusMsg(fmt.Sprintf("%s installed as the celery broker, listening on %s", b.Engine, brokerListen(d)))
+
+	return nil
+}
+
+// brokerCmds returns the commands installing the broker in
+// Install.Broker.Engine on the target OS t, the built-in ones merged with any
+// external commands for the installbroker label.  External commands can add a
+// broker godojo has no built-in commands for.
+func brokerCmds(d *DDConfig, t *targetOS) ([]distros.Cmd, error) {
+	engine := d.conf.Install.Broker.Engine
+	p := c.NewPkg("installbroker")
+	if get, ok := brokerLookups[t.distro]; ok {
+		d.traceMsg(fmt.Sprintf("Searching for commands to install %s on %s", engine, t.id))
+		err := get(p, t.id, engine)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("No built-in commands to install %s on %s: %+v", engine, t.id, err))
+		}
+	}
+	tCmds, err := distros.CmdsForTarget(p, t.id)
+	if err != nil {
+		return nil, fmt.Errorf("godojo doesn't know the %s packages for %s, install it by hand and set Install.Broker.External to true, "+
+			"use %s instead or add installbroker commands in DistroCmdsDir", engine, t.id, otherBroker(engine))
+	}
+
+	return tCmds, nil
+}
+
+// brokerPackage returns how the broker engine runs on the target OS t once
+// its packages are installed
+func brokerPackage(engine string, t *targetOS) brokerPkg {
+	redis := brokerPkg{service: "redis", conf: "/etc/redis/redis.conf"}
+	rabbit := brokerPkg{service: "rabbitmq-server", conf: "/etc/rabbitmq/rabbitmq.conf"}
+	switch {
+	case engine == brokerRedis && (t.distro == "ubuntu" || t.distro == "debian"):
+		redis.service = "redis-server"
+	case engine == brokerRedis && t.distro == "rhel" && t.release == "8":
+		redis.conf = "/etc/redis.conf"
+	case engine == brokerRedis && t.distro == "amazon" && t.release != "2":
+		redis.service = "redis6"
+		redis.conf = "/etc/redis6/redis6.conf"
+	case engine == brokerRedis && t.distro == "suse":
+		// openSUSE runs an instance of redis@ per config in /etc/redis
+		redis.service = "redis@default"
+		redis.conf = "/etc/redis/default.conf"
+	case engine == brokerRedis && t.distro == "arch":
+		// Arch replaced Redis with Valkey, which speaks the same protocol
+		return brokerPkg{service: "valkey", conf: "/etc/valkey/valkey.conf"}
+	case engine == brokerRabbitMQ && (t.distro == "arch" || t.distro == "gentoo"):
+		rabbit.service = "rabbitmq"
+	}
+	if engine == brokerRabbitMQ {
+		return rabbit
+	}
+
+	return redis
+}
+
+// otherBroker returns the broker engine that isn't engine
+func otherBroker(engine string) string {
+	if engine == brokerRedis {
+		return brokerRabbitMQ
+	}
+
+	return brokerRedis
+}
+
+// configureBroker sets the bind address, port and, for Redis, the password in
+// the broker's config file.  The password for RabbitMQ is set on its user by
+// addRabbitUser instead.
+func configureBroker(d *DDConfig, pkg brokerPkg) error {
+	b := d.conf.Install.Broker
+	port := brokerPort(d)
+	lines := map[string]string{
+		"listeners.tcp.default": "listeners.tcp.default = " + net.JoinHostPort(b.Host, port),
+	}
+	if b.Engine == brokerRedis {
+		lines = map[string]string{
+			"bind":        "bind " + b.Host,
+			"port":        "port " + port,
+			"requirepass": "requirepass " + b.Pass,
+		}
+	}
+	if d.dryRun {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would set %s to listen on %s in %s", b.Engine, net.JoinHostPort(b.Host, port), pkg.conf))
+		return nil
+	}
+
+	d.traceMsg(fmt.Sprintf("Setting the %s bind address and port in %+v", b.Engine, pkg.conf))
+	return setConfLines(pkg.conf, lines)
+}
+
+// addRabbitUser creates the Install.Broker.User in RabbitMQ with the
+// configured password, or resets its password if it already exists, and gives
+// it access to the default vhost
+func addRabbitUser(d *DDConfig) error {
+	b := d.conf.Install.Broker
+	d.addRedact(b.Pass)
+	err := sendCmd(d, d.cmdLogger, "rabbitmqctl add_user "+shellQuote(b.User)+" "+shellQuote(b.Pass)+" || rabbitmqctl change_password "+
+		shellQuote(b.User)+" "+shellQuote(b.Pass), "Unable to create the RabbitMQ user "+b.User, true)
+	if err != nil {
+		return err
+	}
+
+	return sendCmd(d, d.cmdLogger, "rabbitmqctl set_permissions -p / "+shellQuote(b.User)+" '.*' '.*' '.*'",
+		"Unable to give the RabbitMQ user "+b.User+" access", true)
+}
+
+// shellQuote returns s single quoted for bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// setConfLines replaces the first line of the config file at p setting each
+// key in lines, commented out or not, with the line for that key and appends
+// the ones not found.  The file's permissions are kept.
+func setConfLines(p string, lines map[string]string) error {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	out := strings.Split(string(b), "\n")
+	for k, l := range lines {
+		re := regexp.MustCompile(`^#?\s*` + regexp.QuoteMeta(k) + `\s`)
+		found := false
+		for i := range out {
+			if re.MatchString(out[i]) {
+				out[i] = l
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, l)
+		}
+	}
+
+	return os.WriteFile(p, []byte(strings.Join(out, "\n")), fi.Mode().Perm())
+}
+
+// brokerPort returns Install.Broker.Port or the broker engine's default port
+func brokerPort(d *DDConfig) string {
+	if d.conf.Install.Broker.Port > 0 {
+		return strconv.Itoa(d.conf.Install.Broker.Port)
+	}
+	if d.conf.Install.Broker.Engine == brokerRabbitMQ {
+		return brokerPorts["amqp"]
+	}
+
+	return brokerPorts["redis"]
+}
+
+// brokerListen returns the address the installed broker listens on
+func brokerListen(d *DDConfig) string {
+	return net.JoinHostPort(d.conf.Install.Broker.Host, brokerPort(d))
+}
+
+// brokerURL returns the celery broker URL for Install.Broker, connecting to an
+// installed broker over loopback when it's bound to every address
+func brokerURL(d *DDConfig) string {
+	b := d.conf.Install.Broker
+	host := b.Host
+	if !b.External && (host == "0.0.0.0" || host == "::") {
+		host = "127.0.0.1"
+	}
+	u := url.URL{Scheme: "redis", Host: net.JoinHostPort(host, brokerPort(d)), Path: "/0"}
+	if len(b.Pass) > 0 {
+		u.User = url.UserPassword("", b.Pass)
+	}
+	if b.Engine == brokerRabbitMQ {
+		u.Scheme = "amqp"
+		u.Path = "//"
+		u.User = url.UserPassword(b.User, b.Pass)
+	}
+	d.addRedact(b.Pass)
+	d.addRedact(u.String())
+
+	return u.String()
+}
+
+// waitForBroker checks the broker at the URL b answers, retrying for up to
+// wait so a broker that was just started has time to come up
+func waitForBroker(d *DDConfig, b string, wait time.Duration) error {
+	if d.dryRun {
+		return checkBroker(d, b)
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		err := checkBroker(d, b)
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		d.traceMsg(fmt.Sprintf("Broker not answering yet: %+v", err))
+		select {
+		case <-time.After(time.Second):
+		case <-d.ctx.Done():
+			return err
+		}
+	}
+}
+
+// redisPing sends a PING, after an AUTH if the Redis URL u has a password,
+// over conn and returns an error unless Redis answers both
+func redisPing(conn net.Conn, u *url.URL) error {
+	_ = conn.SetDeadline(time.Now().Add(brokerDialTimeout))
+	r := bufio.NewReader(conn)
+	if p, ok := u.User.Password(); ok && len(p) > 0 {
+		fmt.Fprintf(conn, "*2\r\n$4\r\nAUTH\r\n$%d\r\n%s\r\n", len(p), p)
+		l, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(l, "+OK") {
+			return fmt.Errorf("Redis refused the password, it answered %s", strings.TrimSpace(l))
+		}
+	}
+	fmt.Fprint(conn, "*1\r\n$4\r\nPING\r\n")
+	l, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(l, "+PONG") {
+		return fmt.Errorf("Redis didn't answer the PING, it answered %s", strings.TrimSpace(l))
+	}
+
+	return nil
+}
@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Handles setting up the celery worker and beat DefectDojo runs its
+// background tasks with, either as systemd units or supervisor programs
+
+// How the celery worker and beat are run
+const (
+	celerySystemd    = "systemd"    // systemd units next to the DefectDojo app unit
+	celerySupervisor = "supervisor" // supervisor programs in Celery.SupervisorDir
+)
+
+const (
+	brokerDialTimeout = 5 * time.Second                       // How long connecting to the celery broker can take
+	supervisorConf    = "defectdojo-celery.conf"              // Name of the supervisor config godojo writes
+	celeryEnvFile     = "defectdojo-celery.env"               // EnvironmentFile in Install.Root with Celery.Env for the celery units
+	workerProgram     = "defectdojo-celery-worker"            // Supervisor program for the celery worker
+	beatProgram       = "defectdojo-celery-beat"              // Supervisor program for the celery beat
+	defaultBrokerPath = "/dojo.celerydb.sqlite"               // DefectDojo's default path for the sqlite broker
+	defaultBrokerURL  = "sqla+sqlite:///dojo.celerydb.sqlite" // DefectDojo's broker when none is configured
+)
+
+// brokerPorts are the default ports of the brokers celery can use over the network
+var brokerPorts = map[string]string{
+	"redis":  "6379",
+	"rediss": "6379",
+	"amqp":   "5672",
+	"amqps":  "5671",
+	"pyamqp": "5672",
+}
+
+// supervisorTmpl is the supervisor config for the celery worker and beat
+const supervisorTmpl = `[program:{{.Worker}}]
+command={{.Bin}}/celery --app=dojo worker --loglevel={{.LogLevel}} --pool={{.Pool}} --concurrency={{.Concurrency}}
+directory={{.Source}}
+user={{.User}}
+environment={{.Env}}
+autostart=true
+autorestart=true
+stopasgroup=true
+killasgroup=true
+stopwaitsecs=600
+
+[program:{{.Beat}}]
+command={{.Bin}}/celery --app=dojo beat --loglevel={{.LogLevel}} --schedule={{.Root}}/celerybeat-schedule
+directory={{.Source}}
+user={{.User}}
+environment={{.Env}}
+autostart=true
+autorestart=true
+stopasgroup=true
+`
+
+// supervisorVals holds the values substituted into supervisorTmpl
+type supervisorVals struct {
+	unitVals
+	Worker string // Supervisor program name for the worker
+	Beat   string // Supervisor program name for the beat
+}
+
+// setupCelery takes a pointer to a DDConfig struct and sets up the celery
+// worker and beat with the configured broker, concurrency and environment as
+// systemd units or supervisor programs depending on Celery.Manager.  The
+// broker is checked first so the workers aren't set up against one that
+// can't be reached.  It's skipped when Install.Celery.Manage is false.
+func setupCelery(d *DDConfig) error {
+	cc := d.conf.Install.Celery
+	if !cc.Manage {
+		d.statusMsg("Install.Celery.Manage is false, the celery worker and beat won't be set up")
+		return nil
+	}
+
+	d.sectionMsg("Setting up the DefectDojo celery worker and beat")
+	broker, err := celeryBroker(d)
+	if err != nil {
+		return err
+	}
+	err = checkBroker(d, broker)
+	if err != nil {
+		return fmt.Errorf("%w\n  Start the broker or fix the Celery broker settings then re-run godojo", err)
+	}
+	if brokerSet(d) {
+		err = setBrokerURL(d, broker)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch cc.Manager {
+	case celerySupervisor:
+		err = setupSupervisor(d)
+	default:
+		if _, err := os.Stat("/run/systemd/system"); err != nil && !d.dryRun {
+			d.warnMsg("systemd isn't running on this host, skipping creating the celery systemd units\n" +
+				"  Set Install.Celery.Manager to supervisor to run them with supervisor instead")
+			return nil
+		}
+		err = setupCeleryUnits(d)
+	}
+	if err != nil {
+		return err
+	}
+
+	n := workerCount(d)
+	procs := "processes"
+	if n == 1 {
+		procs = "process"
+	}
+	d.statusMsg(fmt.Sprintf("Configured %d celery worker %s using the %s pool, managed by %s", n, procs, celeryPool(n), cc.Manager))
+
+	return nil
+}
+
+// setupCeleryUnits writes and enables the systemd units for the celery worker
+// and beat from their templates, removing any created if the install fails
+func setupCeleryUnits(d *DDConfig) error {
+	s := d.conf.Install.Systemd
+	units := []unit{
+		{name: workerUnitName, tmpl: workerUnit, override: s.WorkerTemplate},
+		{name: beatUnitName, tmpl: beatUnit, override: s.BeatTemplate},
+	}
+	err := writeCeleryEnv(d)
+	if err != nil {
+		return err
+	}
+	paths, names, created, err := writeUnits(d, units)
+	if len(created) > 0 {
+		d.addRollback("disable and remove the celery systemd units "+strings.Join(created, ", ")+" created by this run", func() error {
+			return removeUnits(d, created)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return enableUnits(d, paths, names)
+}
+
+// writeCeleryEnv writes Install.Celery.Env to the EnvironmentFile of the celery
+// systemd units.  The environment may hold credentials so only root can read
+// it, unlike the units themselves.
+func writeCeleryEnv(d *DDConfig) error {
+	p := filepath.Join(d.conf.Install.Root, celeryEnvFile)
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would write the celery environment file " + p)
+		return nil
+	}
+
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		d.addRollback("remove the celery environment file "+p+" created by this run", func() error {
+			err := os.Remove(p)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		})
+	}
+	d.traceMsg(fmt.Sprintf("Writing celery environment file %+v", p))
+	err := os.WriteFile(p, renderCeleryEnv(d.conf.Install.Celery.Env), 0600)
+	if err == nil {
+		// WriteFile keeps the mode of a file that already exists
+		err = os.Chmod(p, 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write the celery environment file %s, error was: %w", p, err)
+	}
+
+	return nil
+}
+
+// renderCeleryEnv returns env as the lines of a systemd EnvironmentFile with
+// each value double quoted so spaces, quotes and backslashes survive
+func renderCeleryEnv(env map[string]string) []byte {
+	esc := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	var lines []string
+	for k, v := range env {
+		lines = append(lines, k+`="`+esc.Replace(v)+`"`+"\n")
+	}
+	sort.Strings(lines)
+
+	return []byte(strings.Join(lines, ""))
+}
+
+// setupSupervisor writes the supervisor config for the celery worker and beat
+// and has supervisor load it.  The config has the environment, which may hold
+// credentials, so only root can read it.
+func setupSupervisor(d *DDConfig) error {
+	p := filepath.Join(d.conf.Install.Celery.SupervisorDir, supervisorConf)
+	b, err := renderSupervisor(d)
+	if err != nil {
+		return fmt.Errorf("Unable to create the supervisor config %s, error was: %w", p, err)
+	}
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would write the supervisor config " + p)
+		return sendCmd(d, d.cmdLogger, "supervisorctl update", "Unable to load the celery supervisor config", true)
+	}
+	if _, err := exec.LookPath("supervisorctl"); err != nil {
+		return errors.New("supervisorctl wasn't found, install supervisor or set Install.Celery.Manager to systemd")
+	}
+
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		d.addRollback("remove the supervisor config "+p+" created by this run", func() error {
+			err := os.Remove(p)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return execCmd(d, d.cmdLogger, "supervisorctl update", "Unable to unload the celery supervisor config", 0)
+		})
+	}
+	d.traceMsg(fmt.Sprintf("Writing supervisor config %+v", p))
+	err = ensureDir(filepath.Dir(p))
+	if err != nil {
+		return fmt.Errorf("Unable to create the supervisor config directory, error was: %w", err)
+	}
+	err = os.WriteFile(p, b, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to write the supervisor config %s, error was: %w", p, err)
+	}
+	err = sendCmd(d, d.cmdLogger, "supervisorctl update", "Unable to load the celery supervisor config", true)
+	if err != nil {
+		return err
+	}
+	d.statusMsg("Loaded the supervisor programs " + workerProgram + ", " + beatProgram)
+
+	return nil
+}
+
+// renderSupervisor returns the supervisor config for the celery worker and beat
+func renderSupervisor(d *DDConfig) ([]byte, error) {
+	v := supervisorVals{unitVals: newUnitVals(d), Worker: workerProgram, Beat: beatProgram}
+	env := map[string]string{"DJANGO_SETTINGS_MODULE": "dojo.settings.settings"}
+	for k, val := range d.conf.Install.Celery.Env {
+		env[k] = val
+	}
+	var pairs []string
+	for k, val := range env {
+		// supervisor expands %(name)s in its config so a literal % is doubled
+		val = strings.ReplaceAll(strings.ReplaceAll(val, `"`, `\"`), "%", "%%")
+		pairs = append(pairs, k+`="`+val+`"`)
+	}
+	sort.Strings(pairs)
+	v.Env = strings.Join(pairs, ",")
+
+	t, err := template.New(supervisorConf).Option("missingkey=error").Parse(supervisorTmpl)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	err = t.Execute(&b, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// setBrokerURL sets DD_CELERY_BROKER_URL in DefectDojo's .env.prod to the
+// broker URL b so the app queues tasks on the same broker the workers use
+func setBrokerURL(d *DDConfig, b string) error {
+	p := filepath.Join(d.conf.Install.Root, d.conf.Install.Source, d.conf.Install.App, "settings", ".env.prod")
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would set DD_CELERY_BROKER_URL in " + p)
+		return nil
+	}
+	d.traceMsg(fmt.Sprintf("Setting DD_CELERY_BROKER_URL in %+v", p))
+	err := setEnvProd(p, "DD_CELERY_BROKER_URL", b)
+	if err != nil {
+		return fmt.Errorf("Unable to set the celery broker in %s, error was: %w", p, err)
+	}
+
+	return nil
+}
+
+// startCelery starts the celery worker and beat supervisor programs for the
+// health check, the systemd units are started with the app's unit
+func startCelery(d *DDConfig) error {
+	if !d.conf.Install.Celery.Manage || d.conf.Install.Celery.Manager != celerySupervisor {
+		return nil
+	}
+
+	return sendCmd(d, d.cmdLogger, "supervisorctl start "+workerProgram+" "+beatProgram,
+		"Unable to start the celery supervisor programs", true)
+}
+
+// celeryUnits returns true if the celery worker and beat run as systemd units
+func celeryUnits(d *DDConfig) bool {
+	return d.conf.Install.Celery.Manage && d.conf.Install.Celery.Manager != celerySupervisor
+}
+
+// workerCount returns the number of celery worker processes, Celery.Concurrency
+// or one per CPU when it's 0
+func workerCount(d *DDConfig) int {
+	if d.conf.Install.Celery.Concurrency > 0 {
+		return d.conf.Install.Celery.Concurrency
+	}
+
+	return runtime.NumCPU()
+}
+
+// celeryPool returns the celery pool for n worker processes, a single worker
+// runs tasks in the worker process itself
+func celeryPool(n int) string {
+	if n == 1 {
+		return "solo"
+	}
+
+	return "prefork"
+}
+
+// brokerSet returns true if a celery broker is configured rather than
+// DefectDojo's default
+func brokerSet(d *DDConfig) bool {
+	return len(d.conf.Install.Broker.Engine) > 0 || len(d.conf.Settings.CeleryBrokerURL) > 0 ||
+		len(d.conf.Settings.CeleryBrokerHost) > 0
+}
+
+// celeryBroker returns the celery broker URL, the one for Install.Broker,
+// Settings.CeleryBrokerURL or one built from the CeleryBroker settings the same
+// way DefectDojo's settings.py does, and DefectDojo's default sqlite broker if
+// none are set
+func celeryBroker(d *DDConfig) (string, error) {
+	if len(d.conf.Install.Broker.Engine) > 0 {
+		return brokerURL(d), nil
+	}
+	s := d.conf.Settings
+	if len(s.CeleryBrokerURL) > 0 {
+		u, err := url.Parse(s.CeleryBrokerURL)
+		if err != nil || len(u.Scheme) == 0 {
+			return "", fmt.Errorf("Settings.CeleryBrokerURL isn't a valid URL like redis://host:6379/0")
+		}
+		d.addRedact(s.CeleryBrokerURL)
+		return s.CeleryBrokerURL, nil
+	}
+	if len(s.CeleryBrokerHost) == 0 {
+		return defaultBrokerURL, nil
+	}
+
+	// The config uses sqla_sqlite for DefectDojo's sqla+sqlite scheme
+	scheme := strings.Replace(s.CeleryBrokerScheme, "sqla_", "sqla+", 1)
+	if len(scheme) == 0 {
+		return "", fmt.Errorf("Settings.CeleryBrokerScheme must be set with CeleryBrokerHost, e.g. redis or amqp")
+	}
+	u := url.URL{Scheme: scheme, Host: s.CeleryBrokerHost, Path: s.CeleryBrokerPath}
+	if s.CeleryBrokerPort >= 0 {
+		u.Host = net.JoinHostPort(s.CeleryBrokerHost, fmt.Sprint(s.CeleryBrokerPort))
+	}
+	if u.Path == defaultBrokerPath && !strings.Contains(scheme, "sqlite") {
+		u.Path = ""
+	}
+	if len(s.CeleryBrokerUser) > 0 || len(s.CeleryBrokerPassword) > 0 {
+		u.User = url.UserPassword(s.CeleryBrokerUser, s.CeleryBrokerPassword)
+	}
+	d.addRedact(u.String())
+
+	return u.String(), nil
+}
+
+// brokerAddr returns the host:port to connect to for the broker URL b, false
+// if the broker isn't reached over the network like the sqlite broker
+func brokerAddr(b string) (string, bool) {
+	u, err := url.Parse(b)
+	if err != nil {
+		return "", false
+	}
+	port, ok := brokerPorts[strings.ToLower(u.Scheme)]
+	if !ok || len(u.Hostname()) == 0 {
+		return "", false
+	}
+	if len(u.Port()) > 0 {
+		port = u.Port()
+	}
+
+	return net.JoinHostPort(u.Hostname(), port), true
+}
+
+// checkBroker returns an error if the Redis or RabbitMQ broker at the broker
+// URL b can't be connected to, or for Redis doesn't answer a PING with the
+// URL's password.  Brokers that aren't reached over the network aren't checked.
+func checkBroker(d *DDConfig, b string) error {
+	addr, ok := brokerAddr(b)
+	if !ok {
+		d.verboseMsg("The celery broker isn't reached over the network, not checking it")
+		return nil
+	}
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would check the celery broker at " + addr + " can be reached")
+		return nil
+	}
+
+	d.verboseMsg(fmt.Sprintf("Checking the celery broker at %+v can be reached", addr))
+	dl := net.Dialer{Timeout: brokerDialTimeout}
+	conn, err := dl.DialContext(d.ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to reach the celery broker at %s: %w", addr, err)
+	}
+	defer conn.Close()
+	if u, err := url.Parse(b); err == nil && u.Scheme == "redis" {
+		err = redisPing(conn, u)
+		if err != nil {
+			return fmt.Errorf("the celery broker at %s didn't answer: %w", addr, err)
+		}
+	}
+	d.statusMsg("The celery broker at " + addr + " can be reached")
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defectdojo/godojo/distros"
+)
+
+// minFreeSpace is the free space required in Install.Root by the check
+// subcommand when the size of the release can't be determined
+const minFreeSpace = 1 << 30
+
+// checkResult is the outcome of a single preflight check
+type checkResult struct {
+	name   string // Short name of the check
+	err    error  // Why the check failed, nil if it passed
+	detail string // What was found when the check passed
+}
+
+// selfCheck takes a pointer to a DDConfig struct and the arguments after the
+// check subcommand and runs the install preflight checks without changing
+// anything on the host, printing a pass/fail report and exiting with a 1 if
+// any check failed
+func selfCheck(d *DDConfig, args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.StringVar(&d.cfPath, "config", "", "Path to the config file to check instead of ./dojoConfig.yml")
+	fs.BoolVar(&d.allowUnpriv, "allow-unprivileged", false, "Don't fail the privilege check when godojo isn't run as root")
+	fs.Usage = printCheckHelp
+	_ = fs.Parse(args)
+	if len(d.cfPath) > 0 {
+		err := checkConfigPath(d.cfPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	// Read the config the install would use, only the report is printed
+	readConfigFile(d)
+	if err := readEnvVars(&d.conf); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	d.initRedact()
+	d.quiet = true
+
+	results := []checkResult{checkConfig(d)}
+	setSourceURLs(d)
+	setDBEngine(d)
+	if err := resolveVersion(d); err != nil {
+		results = append(results, checkResult{name: "version", err: err})
+	}
+	results = append(results,
+		checkDistro(d),
+		checkPython(d),
+		checkPrivileges(d),
+		checkSpace(d),
+		checkNetwork(d),
+	)
+
+	// Print the report
+	fmt.Println("")
+	fmt.Printf("godojo preflight check using %s\n", configName(d))
+	fmt.Println("")
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Printf("  FAIL  %-10s %s\n", r.name, d.redactatron(r.err.Error(), d.redact))
+			d.traceMsg(fmt.Sprintf("Check %s failed: %+v", r.name, r.err))
+			continue
+		}
+		fmt.Printf("  PASS  %-10s %s\n", r.name, d.redactatron(r.detail, d.redact))
+		d.traceMsg(fmt.Sprintf("Check %s passed: %+v", r.name, r.detail))
+	}
+	fmt.Println("")
+	fmt.Printf("%d of %d checks passed\n", len(results)-failed, len(results))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkConfig checks the config the same way an install does
+func checkConfig(d *DDConfig) checkResult {
+	r := checkResult{name: "config"}
+	if !d.conf.Install.DB.Local && !d.conf.Install.DB.Exists {
+		r.err = fmt.Errorf("a remote database that doesn't exist is configured, install the remote DB first")
+		return r
+	}
+	r.err = validateConfig(d)
+	r.detail = configName(d) + " is valid"
+
+	return r
+}
+
+// checkDistro checks godojo has bootstrap commands for the host's distro
+func checkDistro(d *DDConfig) checkResult {
+	r := checkResult{name: "distro"}
+	t := targetOS{}
+	err := determineOS(d, &t)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	cBootstrap, err := bootstrapPkg(d, &t)
+	if err == nil {
+		_, err = distros.CmdsForTarget(cBootstrap, t.id)
+	}
+	r.err = err
+	r.detail = t.id + " on " + t.arch + " is supported"
+
+	return r
+}
+
+// checkPython checks PyPath is a supported Python version
+func checkPython(d *DDConfig) checkResult {
+	r := checkResult{name: "python"}
+	ok, err := checkPythonVersion(d)
+	if !ok {
+		r.err = fmt.Errorf("%v, set PYPATH to a Python %s install (some distros get one during bootstrap)", err, pythonRange(d))
+	}
+	r.detail = fmt.Sprintf("%s is Python %s", d.conf.Options.PyPath, pythonRange(d))
+
+	return r
+}
+
+// checkPrivileges checks godojo has the privileges the install needs
+func checkPrivileges(d *DDConfig) checkResult {
+	r := checkResult{name: "privileges"}
+	euid := os.Geteuid()
+	switch {
+	case euid == -1:
+		r.detail = "privilege check doesn't apply on this platform"
+	case euid == 0:
+		r.detail = "running as root"
+	case d.conf.Options.UsrInst || d.allowUnpriv:
+		r.detail = fmt.Sprintf("running as UID %d, allowed by UsrInst or -allow-unprivileged", euid)
+	default:
+		r.err = fmt.Errorf("running as UID %d, root is needed %s, re-run with sudo", euid, installPrivReason)
+	}
+
+	return r
+}
+
+// checkSpace checks the filesystem holding Install.Root has room for the
+// release, using its size from the release URL when the server reports it
+func checkSpace(d *DDConfig) checkResult {
+	r := checkResult{name: "disk"}
+
+	// Root may not exist yet so check the closest directory that does
+	dir := d.conf.Install.Root
+	for {
+		if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	avail, err := availableSpace(dir)
+	if err != nil {
+		r.err = fmt.Errorf("unable to check the free space in %s: %v", dir, err)
+		return r
+	}
+
+	size := int64(-1)
+	if !d.conf.Install.SourceInstall && len(d.conf.Install.LocalTarball) == 0 {
+		if u, err := releaseDownloadURL(d); err == nil {
+			size, _ = headRelease(d, u)
+		}
+	}
+	if size > 0 {
+		r.err = checkDiskSpace(d, dir, size, size)
+		r.detail = fmt.Sprintf("%s available in %s for a %s release", humanBytes(avail), dir, humanBytes(size))
+		return r
+	}
+	if avail < minFreeSpace {
+		r.err = fmt.Errorf("only %s available in %s, at least %s is needed", humanBytes(avail), dir, humanBytes(minFreeSpace))
+	}
+	r.detail = fmt.Sprintf("%s available in %s", humanBytes(avail), dir)
+
+	return r
+}
+
+// checkNetwork checks the release URL, or the clone URL and configured ref for
+// source installs, can be reached
+func checkNetwork(d *DDConfig) checkResult {
+	r := checkResult{name: "network"}
+	if !d.conf.Install.PullSource {
+		r.detail = "PullSource is false, nothing is downloaded"
+		return r
+	}
+
+	if d.conf.Install.SourceInstall {
+		err := setGitProxy(d)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		auth, err := gitAuth(d)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.err = checkRemoteRef(d, auth)
+		r.detail = "reached " + d.cloneURL + " and found the configured ref"
+		return r
+	}
+
+	if len(d.conf.Install.LocalTarball) > 0 {
+		_, r.err = os.Stat(d.conf.Install.LocalTarball)
+		r.detail = "LocalTarball " + d.conf.Install.LocalTarball + " exists, nothing is downloaded"
+		return r
+	}
+
+	u, err := releaseDownloadURL(d)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	_, r.err = headRelease(d, u)
+	r.detail = "reached " + u
+
+	return r
+}
+
+// printCheckHelp prints the help for the check subcommand to stdout
+func printCheckHelp() {
+	fmt.Println("")
+	fmt.Println("Usage of godojo check")
+	fmt.Println("")
+	fmt.Println("./godojo check [optional arguments]")
+	fmt.Println("")
+	fmt.Println("  Runs the install preflight checks using the dojoConfig.yml in the current working directory")
+	fmt.Println("  without changing anything and exits with a 1 if any check fails.  The checks are the config,")
+	fmt.Println("  distro support, Python version, privileges, disk space and reaching the release or clone URL")
+	fmt.Println("  -allow-unprivileged")
+	fmt.Println("        OPTIONAL - Pass the privilege check when godojo isn't run as root or with sudo")
+	fmt.Println("  -config=/path/to/dojoConfig.yml")
+	fmt.Println("        OPTIONAL - Check the config file at the path provided instead of ./dojoConfig.yml")
+	fmt.Println("")
+}
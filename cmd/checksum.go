@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// verifyRelease takes a pointer to a DDConfig struct, an http client, the URL the
+// release was downloaded from, the path to the downloaded tarball and its SHA256
+// if computed during the download or "" otherwise.  It compares
+// the SHA256 of the tarball against the configured checksum or, if none is
+// configured, the checksum published next to the release as <release>.sha256.
+// On a mismatch the tarball is removed so a re-run will download it again.
+// Having no checksum to compare against is an error unless AllowUnverified is
+// set, or the release is from the default Github archive which never publishes
+// one so only a warning is logged.
+func verifyRelease(d *DDConfig, cl *http.Client, dwnURL string, tarball string, got string) error {
+	want := strings.ToLower(strings.TrimSpace(d.conf.Install.Checksum))
+	if want == "" {
+		if strings.HasPrefix(dwnURL, defaultReleaseURL) {
+			d.warnMsg("Github doesn't publish checksums for " + dwnURL + ", the release wasn't verified\n" +
+				"  Set Install.Checksum to the release's SHA256 to verify it")
+			return nil
+		}
+		var err error
+		want, err = publishedChecksum(d, cl, dwnURL+".sha256")
+		if err != nil {
+			return err
+		}
+		if want == "" {
+			return unverified(d, "no SHA256 checksum is configured or published at "+dwnURL+".sha256")
+		}
+	}
+
+	return compareChecksum(d, tarball, want, got, true)
+}
+
+// verifyLocalRelease takes a pointer to a DDConfig struct and the path to a
+// pre-staged release tarball and compares its SHA256 against the configured
+// checksum or, if none is configured, a <tarball>.sha256 file next to it.
+// Unlike a downloaded tarball, a local tarball isn't removed on a mismatch.
+func verifyLocalRelease(d *DDConfig, tarball string) error {
+	want := strings.ToLower(strings.TrimSpace(d.conf.Install.Checksum))
+	if want == "" {
+		b, err := os.ReadFile(tarball + ".sha256")
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Unable to read %+v.sha256, error was: %+v", tarball, err))
+			return unverified(d, "no SHA256 checksum is configured or found at "+tarball+".sha256")
+		}
+		want, err = parseChecksum(string(b))
+		if err != nil {
+			return fmt.Errorf("invalid checksum file %s.sha256: %w", tarball, err)
+		}
+	}
+
+	return compareChecksum(d, tarball, want, "", false)
+}
+
+// unverified returns an error as there's no checksum to verify the release
+// against for the reason why, or logs a warning and returns nil if
+// AllowUnverified is set to install it anyway
+func unverified(d *DDConfig, why string) error {
+	if d.conf.Install.AllowUnverified {
+		d.warnMsg(fmt.Sprintf("AllowUnverified is set and %s, skipping checksum verification", why))
+		return nil
+	}
+
+	return &InstallError{Kind: ErrDownloadFailed, Op: "verifying the release", Err: fmt.Errorf("%s, set Install.Checksum "+
+		"to the release's SHA256 or set Install.AllowUnverified to install it without verifying it", why)}
+}
+
+// compareChecksum returns an error if the SHA256 of the tarball doesn't match
+// the wanted checksum, removing the tarball on a mismatch when rm is true.  If
+// got is "" the SHA256 is computed from the tarball, otherwise got is used as
+// the SHA256 already computed while downloading it.
+func compareChecksum(d *DDConfig, tarball string, want string, got string, rm bool) error {
+	d.traceMsg(fmt.Sprintf("Expected SHA256 checksum of the release is %+v", want))
+
+	if got == "" {
+		var err error
+		got, err = fileSHA256(tarball)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Error computing SHA256 of %+v was: %+v", tarball, err))
+			return err
+		}
+	}
+	d.traceMsg(fmt.Sprintf("Computed SHA256 checksum of the release is %+v", got))
+
+	if got != want {
+		if rm {
+			d.traceMsg(fmt.Sprintf("Removing tarball %+v after checksum mismatch", tarball))
+			if rmErr := os.Remove(tarball); rmErr != nil {
+				d.traceMsg(fmt.Sprintf("Error removing tarball was: %+v", rmErr))
+			}
+		}
+		return &ChecksumError{File: tarball, Want: want, Got: got}
+	}
+
+	d.verboseMsg("SHA256 checksum of the release tarball verified")
+	return nil
+}
+
+// publishedChecksum downloads a .sha256 file from the provided URL and returns
+// the hex encoded checksum it contains.  An empty string and nil error are
+// returned if no checksum is published at that URL.
+func publishedChecksum(d *DDConfig, cl *http.Client, u string) (string, error) {
+	d.verboseMsg(fmt.Sprintf("Downloading published checksum from %+v", u))
+	resp, err := getWithContext(d, cl, u)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error downloading checksum was: %+v", err))
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	d.traceMsg(fmt.Sprintf("Status of checksum download was %+v", resp.Status))
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to download checksum from %s, status was %s", u, resp.Status)
+	}
+
+	// Limit the read, a .sha256 file is a single line of "<checksum>  <filename>"
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	sum, err := parseChecksum(string(body))
+	if err != nil {
+		return "", fmt.Errorf("invalid checksum file at %s: %w", u, err)
+	}
+
+	return sum, nil
+}
+
+// parseChecksum returns the checksum from the contents of a .sha256 file which may
+// be either a bare checksum or the "<checksum>  <filename>" output of sha256sum
+func parseChecksum(s string) (string, error) {
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return "", fmt.Errorf("checksum file is empty")
+	}
+	sum := strings.ToLower(f[0])
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("%q is not a SHA256 checksum", f[0])
+	}
+
+	return sum, nil
+}
+
+// fileSHA256 returns the hex encoded SHA256 of the file at path p
+func fileSHA256(p string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, p); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile writes the contents of the file at path p to the hash h
+func hashFile(h hash.Hash, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+	return err
+}
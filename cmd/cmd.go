@@ -1,10 +1,33 @@
 package cmd
 
+import "os"
+
 func Main() {
 	// Set godojo defaults
 	defaults := DDConfig{}
 	defaults.setGodojoDefaults()
 
+	// Cancel running commands and downloads on Ctrl-C
+	defaults.ctx = handleInterrupt(&defaults)
+
+	// Handle subcommands
+	if len(os.Args) > 1 && os.Args[1] == "uninstall" {
+		uninstall(&defaults, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		selfCheck(&defaults, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "db-only" {
+		dbOnly(&defaults, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "list-distros" {
+		listDistros(os.Args[2:])
+		return
+	}
+
 	// Prepeare the installer
 	prepInstaller(&defaults)
 
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ANSI colors for each level of console message
+const (
+	colorReset   = "\033[0m"
+	colorSection = "\033[1;36m" // Bold cyan
+	colorStatus  = "\033[32m"   // Green
+	colorWarn    = "\033[1;33m" // Bold yellow
+	colorError   = "\033[1;31m" // Bold red
+)
+
+// colorDisabled returns true if the console output shouldn't be colored,
+// either NO_COLOR is set (see https://no-color.org), TERM is dumb or stdout
+// isn't a terminal so log aggregators and CI logs don't get escape codes
+func colorDisabled() bool {
+	if len(os.Getenv("NO_COLOR")) > 0 || os.Getenv("TERM") == "dumb" {
+		return true
+	}
+
+	return !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// paint returns s wrapped in the ANSI color c unless colors are disabled
+func (gd *DDConfig) paint(c string, s string) string {
+	if gd.noColor {
+		return s
+	}
+
+	return c + s + colorReset
+}
@@ -2,14 +2,17 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log"
-	"os"
 	"os/exec"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/defectdojo/godojo/distros"
 )
 
 // OS commands to perform an action e.g. install DB from OS packages
@@ -20,38 +23,170 @@ type osCmds struct {
 	hard   []bool   // Flag to know if an error on the matching command is fatal
 }
 
-// TODO: Document this and/or move it to a separate package
-func sendCmd(d *DDConfig, o *log.Logger, cmd string, lerr string, hard bool) {
-	// Setup command
+// sendCmd runs cmd with execCmd and the global CmdTimeoutMinutes timeout,
+// returning an error if it fails and hard is true.  A failure with hard false
+// is only logged.
+func sendCmd(d *DDConfig, o *log.Logger, cmd string, lerr string, hard bool) error {
+	err := execCmd(d, o, cmd, lerr, 0)
+	if err != nil && hard {
+		// Return hard aka fatal errors
+		return fmt.Errorf("%s: %w", lerr, err)
+	}
+
+	return nil
+}
+
+// execCmd runs cmd in bash, logging its output to the command log.  The
+// timeout t overrides the global CmdTimeoutMinutes when it's greater than 0.
+// If the command fails or times out the error is returned.  On a timeout the
+// whole process group is killed so children like a package manager waiting on
+// a prompt don't linger.
+func execCmd(d *DDConfig, o *log.Logger, cmd string, lerr string, t time.Duration) error {
+	// Only show the command for dry runs
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would run: " + cmd)
+		return nil
+	}
+	d.verboseMsg("Running: " + cmd)
+
+	// Setup the timeout, 0 means the command can run as long as it needs
+	if t <= 0 {
+		t = time.Duration(d.conf.Install.CmdTimeoutMinutes) * time.Minute
+	}
+	ctx := d.ctx
+	if t > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(d.ctx, t)
+		defer cancel()
+	}
+
+	// Setup command in its own process group so it can be killed with its children
 	runCmd := exec.Command("bash", "-c", cmd)
+	runCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var cmdOut bytes.Buffer
+	runCmd.Stdout = &cmdOut
+	runCmd.Stderr = &cmdOut
 	d.cmdLogger.Printf("[godojo] # %s\n", d.redactatron(cmd, d.redact))
 
-	// Run and gather its output
-	cmdOut, err := runCmd.CombinedOutput()
+	// Run and gather its output, killing the process group if the context ends first
+	err := runCmd.Start()
+	if err == nil {
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				_ = syscall.Kill(-runCmd.Process.Pid, syscall.SIGKILL)
+			case <-done:
+			}
+		}()
+		err = runCmd.Wait()
+		close(done)
+	}
+	if err != nil {
+		d.waitIfStopping()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && d.ctx.Err() == nil {
+		d.errorMsg(fmt.Sprintf("%s - OS command %+v timed out after %v and was killed",
+			timeStamp(), d.redactatron(cmd, d.redact), t))
+		err = fmt.Errorf("%s: command timed out after %v", lerr, t)
+	}
+	d.cmdLogger.Printf("%s\n", cmdOut.String())
 	if err != nil {
 		d.errorMsg(fmt.Sprintf("%s - Failed to run OS command %+v, error was: %+v",
 			timeStamp(), d.redactatron(cmd, d.redact), err))
-		if hard {
-			// Exit on hard aka fatal errors
-			os.Exit(1)
+		return err
+	}
+
+	return nil
+}
+
+// runCmds runs the commands for a target OS in order.  Consecutive commands
+// marked parallel in the distro command definitions are run at the same time,
+// up to ParallelCmds at once, and all of them finish before the next command
+// starts.  The first command with Hard set that fails stops the run and its
+// error is returned, for a parallel group that's after the whole group ran.
+func runCmds(d *DDConfig, cmds []distros.Cmd) error {
+	for i := 0; i < len(cmds); {
+		// Find the run of parallel commands starting at i, if any
+		j := i + 1
+		if cmds[i].Parallel && d.conf.Install.ParallelCmds > 1 {
+			for j < len(cmds) && cmds[j].Parallel {
+				j++
+			}
+		}
+		if j-i == 1 {
+			err := execCmd(d, d.cmdLogger, cmds[i].Cmd, cmds[i].Errmsg, cmds[i].Timeout)
+			if err != nil && cmds[i].Hard {
+				return fmt.Errorf("%s: %w", cmds[i].Errmsg, err)
+			}
+			i = j
+			continue
+		}
+
+		err := runParallelCmds(d, cmds[i:j])
+		if err != nil {
+			return err
 		}
+		i = j
 	}
-	d.cmdLogger.Printf("%s\n", string(cmdOut))
-	if err != nil {
-		d.errorMsg(fmt.Sprintf("Failed to write to OS command log file, error was: %+v", err))
+
+	return nil
+}
+
+// runParallelCmds runs the commands with a pool of ParallelCmds workers and
+// waits for all of them, returning an error if any command with Hard set failed
+func runParallelCmds(d *DDConfig, cmds []distros.Cmd) error {
+	d.traceMsg(fmt.Sprintf("Running %d commands in parallel with up to %d at once", len(cmds), d.conf.Install.ParallelCmds))
+	errs := make([]error, len(cmds))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < d.conf.Install.ParallelCmds && w < len(cmds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range work {
+				// Hard is handled below so one failure doesn't stop the others while they're running
+				errs[k] = execCmd(d, d.cmdLogger, cmds[k].Cmd, cmds[k].Errmsg, cmds[k].Timeout)
+			}
+		}()
+	}
+	for k := range cmds {
+		work <- k
+	}
+	close(work)
+	wg.Wait()
+
+	var hard []string
+	for k := range errs {
+		if errs[k] != nil && cmds[k].Hard {
+			hard = append(hard, fmt.Sprintf("%s: %v", cmds[k].Errmsg, errs[k]))
+		}
+	}
+	if len(hard) > 0 {
+		d.errorMsg(fmt.Sprintf("%d parallel OS commands failed:\n    %s", len(hard), strings.Join(hard, "\n    ")))
+		return fmt.Errorf("%d parallel OS commands failed: %s", len(hard), strings.Join(hard, "; "))
 	}
+
+	return nil
 }
 
 // TODO: Document this and/or move it to a separate package
 func tryCmd(d *DDConfig, cmd string, lerr string, hard bool) error {
 	d.traceMsg("Entering tryCmd")
+	// Only show the command for dry runs
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would run: " + cmd)
+		return nil
+	}
+
 	// Setup command
-	runCmd := exec.Command("bash", "-c", cmd)
+	runCmd := exec.CommandContext(d.ctx, "bash", "-c", cmd)
 	d.cmdLogger.Printf("[godojo] # " + d.redactatron(cmd, d.redact) + "\n")
 
-	// Hook up stdout and strerr
-	runCmd.Stdout = d.cmdLogger.Writer()
-	runCmd.Stderr = d.cmdLogger.Writer()
+	// Hook up stdout and strerr, the output is logged whole so secrets can be redacted
+	var cmdOut bytes.Buffer
+	runCmd.Stdout = &cmdOut
+	runCmd.Stderr = &cmdOut
 
 	// Start the command
 	err := runCmd.Start()
@@ -62,6 +197,7 @@ func tryCmd(d *DDConfig, cmd string, lerr string, hard bool) error {
 
 	// Wait for command to exit, then check the exit code
 	err = runCmd.Wait()
+	d.cmdLogger.Printf("%s\n", cmdOut.String())
 	if err != nil {
 		// Check if the error is a ExitError
 		if exiterr, ok := err.(*exec.ExitError); ok {
@@ -103,15 +239,14 @@ func tryCmds(d *DDConfig, c osCmds) error {
 func inspectCmd(d *DDConfig, cmd string, lerr string, hard bool) (string, error) {
 	d.traceMsg("Inside inspectCmd")
 	// Setup command
-	runCmd := exec.Command("bash", "-c", cmd)
+	runCmd := exec.CommandContext(d.ctx, "bash", "-c", cmd)
 	d.cmdLogger.Printf("[godojo] # " + d.redactatron(cmd, d.redact) + "\n")
 	//}
 
-	// Hook up stdout and strerr
-	var tmpBuf bytes.Buffer
-	multi := io.MultiWriter(d.cmdLogger.Writer(), &tmpBuf)
-	runCmd.Stdout = multi
-	runCmd.Stderr = d.cmdLogger.Writer()
+	// Hook up stdout and strerr, the output is logged whole so secrets can be redacted
+	var tmpBuf, errBuf bytes.Buffer
+	runCmd.Stdout = &tmpBuf
+	runCmd.Stderr = &errBuf
 
 	// Start the command
 	err := runCmd.Start()
@@ -124,6 +259,7 @@ func inspectCmd(d *DDConfig, cmd string, lerr string, hard bool) (string, error)
 	d.traceMsg("Before runCmd.Wait()")
 	// Wait for command to exit, then check the exit code
 	err = runCmd.Wait()
+	d.cmdLogger.Printf("%s%s\n", tmpBuf.String(), errBuf.String())
 	if err != nil {
 		// Check if the error is a ExitError
 		if exiterr, ok := err.(*exec.ExitError); ok {
@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"crypto/x509"
 	"embed"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
 
+	"github.com/defectdojo/godojo/distros"
 	"github.com/spf13/viper"
 )
 
@@ -24,32 +31,26 @@ var embd embed.FS
 
 // writeDefaultConfig takes a string for the config filename and a bool to
 // determine if a note about createing the config file should be printed to
-// stdout
-func writeDefaultConfig(c string, printNote bool) {
+// stdout, returning an error if the file couldn't be written
+func writeDefaultConfig(c string, printNote bool) error {
 	// Get the current working directory for future operations
 	path, err := os.Getwd()
 	if err != nil {
-		fmt.Println("Unable to determine current working directory, exiting...")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to determine current working directory\nError: %w", err)
 	}
 
 	// Extract the embedded config file
 	f, err := embd.ReadFile(embdConfig)
 	if err != nil {
 		// file was not found.
-		fmt.Println("Unable to extract embedded config file")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to extract embedded config file\nError: %w", err)
 	}
 
 	// Write out the embedded default dojoConfig.yml
 	err = os.WriteFile(path+"/"+c, f, 0644)
 	if err != nil {
 		// Cannot write config file
-		fmt.Printf("Unable to write configuration file in %s, exiting...\n", path)
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to write configuration file in %s\nError: %w", path, err)
 	}
 
 	if printNote {
@@ -57,96 +58,651 @@ func writeDefaultConfig(c string, printNote bool) {
 		fmt.Printf("\t%s\nA default configuration file was written there.\n\n", path)
 		fmt.Println("Please review the configuration settings, adjusting as needed and")
 		fmt.Println("re-run the godojo installer to begin the install you configured.")
-		os.Exit(0)
 	}
+
+	return nil
 }
 
 // readConfigFile reads the yaml configuration file for godojo to determine
 // runtime configuration.  The file is dojoConfig.yml and is expected to be in
-// the same directory as the godojo binary it returns nohing but will exit
-// early with a exit code of 1 if there are errors reading the file or
-// unmarshialling into a struct
+// the same directory as the godojo binary unless another file was provided
+// with -config.  It returns nohing but will exit
+// early with a exit code of 1 if loadConfig returns an error reading the file
+// or unmarshialling into a struct
 func readConfigFile(d *DDConfig) {
-	// Setup viper config
-	viper.AddConfigPath(".")
-	viper.SetConfigName("dojoConfig")
-	viper.SetConfigType("yml")
-
-	// Read the default config file dojoConfig.yml
-	err := viper.ReadInConfig()
+	err := loadConfig(d)
 	if err != nil {
 		fmt.Println("")
-		fmt.Println("Unable to read the godojo config file (dojoConfig.yml), exiting install")
-		fmt.Printf("Error was: %v\n", err)
+		fmt.Println(err)
+		fmt.Println("Exiting install")
 		os.Exit(1)
 	}
+}
+
+// loadConfig reads the config file for readConfigFile into d.conf and returns
+// any error reading the file or unmarshialling into a struct
+func loadConfig(d *DDConfig) error {
+	// Setup viper config, a new one each time so nothing from an earlier load is kept
+	v := viper.New()
+	d.viper = v
+	v.AddConfigPath(".")
+	v.SetConfigName("dojoConfig")
+	v.SetConfigType("yml")
+	if len(d.cfPath) > 0 {
+		v.SetConfigFile(d.cfPath)
+	}
+
+	// Defaults for values where the zero value has its own meaning
+	v.SetDefault("Install.Redact", true)
+	v.SetDefault("Install.DownloadTimeoutSeconds", 120)
+	v.SetDefault("Install.ResumeDownload", true)
+	v.SetDefault("Install.KeepTarball", true)
+	v.SetDefault("Install.PythonMin", "3.11")
+	v.SetDefault("Install.ExtractMultiplier", 4)
+	v.SetDefault("Install.ReleaseURL", d.releaseURL)
+	v.SetDefault("Install.LatestURL", defaultLatestURL)
+	v.SetDefault("Install.ReleaseAPIURL", defaultReleaseAPIURL)
+	v.SetDefault("Install.CloneURL", d.cloneURL)
+	v.SetDefault("Install.GitUser", "git")
+	v.SetDefault("Install.CmdTimeoutMinutes", 30)
+	v.SetDefault("Install.ParallelCmds", 4)
+	v.SetDefault("Install.DB.ConnectTimeout", 10)
+	v.SetDefault("Install.SELinux.Manage", true)
+	v.SetDefault("Install.SELinux.FileContext", "httpd_sys_content_t")
+	v.SetDefault("Install.SELinux.RWContext", "httpd_sys_rw_content_t")
+	v.SetDefault("Install.SELinux.PortType", "http_port_t")
+	v.SetDefault("Install.Systemd.Manage", true)
+	v.SetDefault("Install.Systemd.UnitDir", "/etc/systemd/system")
+	v.SetDefault("Install.Celery.Manage", true)
+	v.SetDefault("Install.Celery.Manager", celerySystemd)
+	v.SetDefault("Install.Celery.Concurrency", 1)
+	v.SetDefault("Install.Celery.SupervisorDir", "/etc/supervisor/conf.d")
+	v.SetDefault("Install.Broker.Host", "127.0.0.1")
+	v.SetDefault("Install.Broker.User", "defectdojo")
+	v.SetDefault("Install.HealthCheck.Path", "/login")
+	v.SetDefault("Install.HealthCheck.TimeoutSeconds", 180)
+	v.SetDefault("Install.HealthCheck.IntervalSeconds", 3)
+	v.SetDefault("Install.Node.Method", nodeNodesource)
+	v.SetDefault("Install.Node.NVMURL", defaultNVMURL)
+
+	// Read the default config file dojoConfig.yml
+	err := v.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("Unable to read the godojo config file (%s)\nError was: %v", configName(d), err)
+	}
 
 	// Marshall the config values into the DojoConfig struct
-	err = viper.Unmarshal(&d.conf)
+	err = v.Unmarshal(&d.conf)
 	if err != nil {
-		fmt.Println("")
-		fmt.Println("Unable to set the config values based on config file and ENV variables, exiting install")
-		fmt.Printf("Error was: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to set the config values based on config file and ENV variables\nError was: %v", err)
+	}
+
+	// Bring configs written for an older godojo up to date
+	err = migrateConfig(d)
+	if err != nil {
+		return err
 	}
+
+	// Resolve ${VAR} references so secrets can stay out of the config file
+	err = interpolateConfig(&d.conf)
+	if err != nil {
+		return fmt.Errorf("Unable to resolve the env variables referenced in the godojo config file (%s)\n%v", configName(d), err)
+	}
+
+	return nil
+}
+
+// configName returns the config file in use for messages
+func configName(d *DDConfig) string {
+	if len(d.cfPath) > 0 {
+		return d.cfPath
+	}
+	return d.cf
 }
 
 // writeInstallConfig writes the final configuration used for the install taking
 // into account the dojoConfig.yml, any command-line arguments and env variables
-func writeFinalConfig(d *DDConfig) {
+func writeFinalConfig(d *DDConfig) error {
 	d.traceMsg("Writing out the runtime install configuration file")
-	err := viper.WriteConfigAs("runtime-install-config.yml")
+	err := d.viper.WriteConfigAs("runtime-install-config.yml")
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Error from writing the runtime config was: %+v", err))
-		os.Exit(1)
+		return fmt.Errorf("Error from writing the runtime config was: %w", err)
+	}
+
+	return nil
+}
+
+// configErrors holds every problem found in the config by validateConfig
+type configErrors []error
+
+// Error returns the config problems as a numbered list
+func (c configErrors) Error() string {
+	var b strings.Builder
+	for i := range c {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %d. %v", i+1, c[i])
+	}
+	return b.String()
+}
+
+// scpLikeURL matches the git@github.com:org/repo.git form of SSH clone URLs
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/]`)
+
+// versionFormat matches DefectDojo release versions like 2.32.2
+var versionFormat = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+$`)
+
+// seLinuxType matches SELinux type names like httpd_sys_content_t
+var seLinuxType = regexp.MustCompile(`^[a-z0-9_]+_t$`)
+
+// validateConfig takes a pointer to a DDConfig struct and checks the config for
+// problems before anything is changed on the install target.  Rather than
+// stopping at the first problem, every problem found is returned in
+// configErrors or nil if the config is valid.
+func validateConfig(d *DDConfig) error {
+	var errs configErrors
+
+	if d.conf.Install.SourceInstall {
+		err := checkSourceRef(d)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	} else if d.conf.Install.Version == latestVersion {
+		if err := checkURL(d.conf.Install.LatestURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("LatestURL %w", err))
+		}
+	} else if !versionFormat.MatchString(d.conf.Install.Version) {
+		errs = append(errs, fmt.Errorf("Version %q isn't a release version like 2.32.2 or %s", d.conf.Install.Version, latestVersion))
+	}
+	if d.conf.Install.ReleaseAPI && !d.conf.Install.SourceInstall {
+		if err := checkURL(d.conf.Install.ReleaseAPIURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("ReleaseAPIURL %w", err))
+		}
+		if _, err := filepath.Match(d.conf.Install.ReleaseAsset, ""); err != nil {
+			errs = append(errs, fmt.Errorf("ReleaseAsset %q isn't a valid pattern like *.tar.gz: %v", d.conf.Install.ReleaseAsset, err))
+		}
+	}
+
+	if err := checkRoot(d.conf.Install.Root); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSource(d.conf.Install.Source); err != nil {
+		errs = append(errs, err)
+	}
+
+	if d.conf.Install.VerifySignature && !d.conf.Install.SourceInstall {
+		if len(d.conf.Install.SigningKey) == 0 {
+			errs = append(errs, fmt.Errorf("VerifySignature is set so SigningKey must be the path to the release public key"))
+		} else if _, err := os.Stat(d.conf.Install.SigningKey); err != nil {
+			errs = append(errs, fmt.Errorf("SigningKey %s doesn't exist or isn't readable", d.conf.Install.SigningKey))
+		}
+	}
+
+	if d.conf.Install.MaxDownloadRate < 0 {
+		errs = append(errs, fmt.Errorf("MaxDownloadRate %d can't be negative, use 0 for no limit", d.conf.Install.MaxDownloadRate))
+	}
+	if d.conf.Install.CmdTimeoutMinutes < 0 {
+		errs = append(errs, fmt.Errorf("CmdTimeoutMinutes %d can't be negative, use 0 for no timeout", d.conf.Install.CmdTimeoutMinutes))
+	}
+	if d.conf.Install.ParallelCmds < 1 {
+		errs = append(errs, fmt.Errorf("ParallelCmds %d must be 1 or more, use 1 to run every OS command in order", d.conf.Install.ParallelCmds))
+	}
+	if d.conf.Install.ExtractMultiplier < 1 {
+		errs = append(errs, fmt.Errorf("ExtractMultiplier %v must be 1 or more, an extracted release is never smaller than its tarball", d.conf.Install.ExtractMultiplier))
+	}
+	if err := checkURL(d.conf.Install.ReleaseURL, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("ReleaseURL %w", err))
+	}
+	hasPass := len(d.conf.Install.ReleasePass) > 0 || len(d.conf.Install.ReleasePassEnv) > 0
+	if hasPass && len(d.conf.Install.ReleaseUser) == 0 {
+		errs = append(errs, fmt.Errorf("ReleasePass or ReleasePassEnv is set without a ReleaseUser"))
+	}
+	if !scpLikeURL.MatchString(d.conf.Install.CloneURL) {
+		if err := checkURL(d.conf.Install.CloneURL, "http", "https", "ssh", "git", "file"); err != nil {
+			errs = append(errs, fmt.Errorf("CloneURL %w", err))
+		}
+	}
+	hasToken := len(d.conf.Install.GitToken) > 0 || len(d.conf.Install.GitTokenEnv) > 0
+	if hasToken && len(d.conf.Install.GitSSHKey) > 0 {
+		errs = append(errs, fmt.Errorf("only one of GitSSHKey or GitToken/GitTokenEnv can be set"))
+	}
+	if len(d.conf.Install.GitSSHKey) > 0 {
+		if _, err := os.Stat(d.conf.Install.GitSSHKey); err != nil {
+			errs = append(errs, fmt.Errorf("GitSSHKey %s doesn't exist or isn't readable", d.conf.Install.GitSSHKey))
+		}
+	}
+
+	if len(d.conf.Install.CABundle) > 0 {
+		if _, err := loadCABundle(x509.NewCertPool(), d.conf.Install.CABundle); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// The commands themselves are checked when loadDistroCmds loads them at startup
+	if len(d.conf.Install.DistroCmdsDir) > 0 {
+		if _, err := os.ReadDir(d.conf.Install.DistroCmdsDir); err != nil {
+			errs = append(errs, fmt.Errorf("DistroCmdsDir %s isn't a directory that can be read: %v", d.conf.Install.DistroCmdsDir, err))
+		}
+	}
+
+	if len(d.conf.Install.PipVersion) > 0 && !pinFormat.MatchString(d.conf.Install.PipVersion) {
+		errs = append(errs, fmt.Errorf("PipVersion %q isn't an exact version like 23.3.2", d.conf.Install.PipVersion))
+	}
+	if len(d.conf.Install.VirtualenvVersion) > 0 && !pinFormat.MatchString(d.conf.Install.VirtualenvVersion) {
+		errs = append(errs, fmt.Errorf("VirtualenvVersion %q isn't an exact version like 20.25.0", d.conf.Install.VirtualenvVersion))
+	}
+	if len(d.conf.Install.VenvPath) > 0 {
+		if err := checkVenvPath(d.conf.Install.VenvPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(d.conf.Install.Requirements) > 0 {
+		if fi, err := os.Stat(d.conf.Install.Requirements); err != nil {
+			errs = append(errs, fmt.Errorf("Requirements %s doesn't exist or isn't readable", d.conf.Install.Requirements))
+		} else if !fi.Mode().IsRegular() {
+			errs = append(errs, fmt.Errorf("Requirements %s isn't a file, it must be a pip requirements file like requirements.txt", d.conf.Install.Requirements))
+		}
+	}
+
+	s := d.conf.Install.SELinux
+	for _, c := range []struct{ name, val string }{
+		{"SELinux.FileContext", s.FileContext}, {"SELinux.RWContext", s.RWContext}, {"SELinux.PortType", s.PortType},
+	} {
+		if s.Manage && !seLinuxType.MatchString(c.val) {
+			errs = append(errs, fmt.Errorf("%s %q isn't an SELinux type like httpd_sys_content_t", c.name, c.val))
+		}
+	}
+	for _, p := range s.Ports {
+		if p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("SELinux.Ports has %d which isn't a TCP port", p))
+		}
+	}
+
+	sd := d.conf.Install.Systemd
+	for _, c := range []struct{ name, path string }{
+		{"Systemd.AppTemplate", sd.AppTemplate}, {"Systemd.WorkerTemplate", sd.WorkerTemplate}, {"Systemd.BeatTemplate", sd.BeatTemplate},
+	} {
+		if len(c.path) == 0 {
+			continue
+		}
+		if _, err := os.Stat(c.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s doesn't exist or isn't readable", c.name, c.path))
+		}
+	}
+	if sd.Manage && !filepath.IsAbs(sd.UnitDir) {
+		errs = append(errs, fmt.Errorf("Systemd.UnitDir %q must be an absolute path like /etc/systemd/system", sd.UnitDir))
+	}
+
+	cc := d.conf.Install.Celery
+	if cc.Manage {
+		if cc.Manager != celerySystemd && cc.Manager != celerySupervisor {
+			errs = append(errs, fmt.Errorf("Celery.Manager %q must be %s or %s", cc.Manager, celerySystemd, celerySupervisor))
+		}
+		if cc.Concurrency < 0 {
+			errs = append(errs, fmt.Errorf("Celery.Concurrency %d can't be negative, use 0 for one worker process per CPU", cc.Concurrency))
+		}
+		if cc.Manager == celerySupervisor && !filepath.IsAbs(cc.SupervisorDir) {
+			errs = append(errs, fmt.Errorf("Celery.SupervisorDir %q must be an absolute path like /etc/supervisor/conf.d", cc.SupervisorDir))
+		}
+		if _, err := celeryBroker(d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	bk := d.conf.Install.Broker
+	if len(bk.Engine) > 0 {
+		if bk.Engine != brokerRedis && bk.Engine != brokerRabbitMQ {
+			errs = append(errs, fmt.Errorf("Broker.Engine %q must be %s, %s or blank for no broker", bk.Engine, brokerRedis, brokerRabbitMQ))
+		}
+		if len(d.conf.Settings.CeleryBrokerURL) > 0 || len(d.conf.Settings.CeleryBrokerHost) > 0 {
+			errs = append(errs, fmt.Errorf("Broker.Engine can't be used with Settings.CeleryBrokerURL or CeleryBrokerHost, set one or the other"))
+		}
+		if len(bk.Host) == 0 {
+			errs = append(errs, fmt.Errorf("Broker.Host must be set when Broker.Engine is set"))
+		}
+		if bk.Port < 0 || bk.Port > 65535 {
+			errs = append(errs, fmt.Errorf("Broker.Port %d must be between 1 and 65535, or 0 for the default port", bk.Port))
+		}
+		if !bk.External && len(bk.Pass) == 0 {
+			errs = append(errs, fmt.Errorf("Broker.Pass must be set for a broker installed by godojo"))
+		}
+		if bk.Engine == brokerRabbitMQ && len(bk.User) == 0 {
+			errs = append(errs, fmt.Errorf("Broker.User must be set for RabbitMQ"))
+		}
 	}
 
+	hc := d.conf.Install.HealthCheck
+	if hc.Enabled {
+		if len(hc.URL) > 0 {
+			if err := checkURL(hc.URL, "http", "https"); err != nil {
+				errs = append(errs, fmt.Errorf("HealthCheck.URL %w", err))
+			}
+		}
+		if hc.Port < 0 || hc.Port > 65535 {
+			errs = append(errs, fmt.Errorf("HealthCheck.Port %d isn't a TCP port, use 0 for Settings.UwsgiPort", hc.Port))
+		}
+		if len(hc.URL) == 0 && !strings.HasPrefix(hc.Path, "/") {
+			errs = append(errs, fmt.Errorf("HealthCheck.Path %q must start with a / like /login", hc.Path))
+		}
+		if hc.TimeoutSeconds < 1 {
+			errs = append(errs, fmt.Errorf("HealthCheck.TimeoutSeconds %d must be 1 or more", hc.TimeoutSeconds))
+		}
+		if hc.IntervalSeconds < 1 {
+			errs = append(errs, fmt.Errorf("HealthCheck.IntervalSeconds %d must be 1 or more", hc.IntervalSeconds))
+		}
+	}
+
+	nd := d.conf.Install.Node
+	if len(nd.Version) > 0 {
+		switch nd.Method {
+		case nodeDistro, nodeNodesource:
+			if !nodeVersion.MatchString(nd.Version) || strings.Contains(nd.Version, ".") {
+				errs = append(errs, fmt.Errorf("Node.Version %q must be a major version like 20 for the %s Method, "+
+					"use the %s Method for an exact version", nd.Version, nd.Method, nodeNVM))
+			}
+		case nodeNVM:
+			if !nodeVersion.MatchString(nd.Version) {
+				errs = append(errs, fmt.Errorf("Node.Version %q must be a version like 20 or 20.11.1", nd.Version))
+			}
+			if err := checkURL(nd.NVMURL, "https"); err != nil {
+				errs = append(errs, fmt.Errorf("Node.NVMURL %w", err))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("Node.Method %q must be one of %s, %s or %s", nd.Method, nodeDistro, nodeNodesource, nodeNVM))
+		}
+	}
+
+	for _, h := range []struct {
+		name  string
+		hooks []hookCmd
+	}{
+		{"PostDownload", d.conf.Install.Hooks.PostDownload},
+		{"PostExtract", d.conf.Install.Hooks.PostExtract},
+		{"PostInstall", d.conf.Install.Hooks.PostInstall},
+	} {
+		for i := range h.hooks {
+			if len(strings.TrimSpace(h.hooks[i].Cmd)) == 0 {
+				errs = append(errs, fmt.Errorf("Hooks.%s[%d].Cmd can't be empty", h.name, i))
+			}
+		}
+	}
+
+	if _, ok := dbEngines[strings.ToLower(strings.TrimSpace(d.conf.Install.DB.Engine))]; !ok {
+		errs = append(errs, fmt.Errorf("DB.Engine %q isn't supported, it must be PostgreSQL, MySQL, MariaDB or SQLite", d.conf.Install.DB.Engine))
+	}
+
+	_, err := os.Stat(d.conf.Options.PyPath)
+	if err != nil && len(d.conf.Install.PythonCandidates) == 0 {
+		errs = append(errs, fmt.Errorf("PyPath %s doesn't exist, set PYPATH to a Python 3 install", d.conf.Options.PyPath))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// systemDirs are directories Root can't be as installing into them would
+// clobber the OS, directories under the ones mapped to true are also rejected
+var systemDirs = map[string]bool{
+	"/bin": true, "/boot": true, "/dev": true, "/etc": true, "/lib": true, "/lib32": true, "/lib64": true,
+	"/proc": true, "/run": true, "/sbin": true, "/sys": true, "/usr/bin": true, "/usr/include": true,
+	"/usr/lib": true, "/usr/lib64": true, "/usr/sbin": true, "/usr/share": true,
+	"/home": false, "/media": false, "/mnt": false, "/opt": false, "/root": false, "/srv": false,
+	"/tmp": false, "/usr": false, "/usr/local": false, "/var": false, "/var/lib": false, "/var/log": false,
+}
+
+// checkRoot returns an error if root isn't an absolute path that's safe to
+// install DefectDojo into, i.e. not /, a system directory or a path using ..
+func checkRoot(root string) error {
+	if len(strings.TrimSpace(root)) == 0 {
+		return fmt.Errorf("Root can't be empty, it's the directory DefectDojo is installed into")
+	}
+	if !filepath.IsAbs(root) {
+		return fmt.Errorf("Root %q must be an absolute path like /opt/dojo", root)
+	}
+	for _, p := range strings.Split(root, "/") {
+		if p == ".." {
+			return fmt.Errorf("Root %q can't contain .., use the path it resolves to instead", root)
+		}
+	}
+	clean := filepath.Clean(root)
+	if clean == "/" {
+		return fmt.Errorf("Root can't be /, use a directory for DefectDojo like /opt/dojo")
+	}
+	if _, ok := systemDirs[clean]; ok {
+		return fmt.Errorf("Root %q is a system directory, use a directory for DefectDojo like %s/dojo", root, clean)
+	}
+	for dir := filepath.Dir(clean); dir != "/"; dir = filepath.Dir(dir) {
+		if systemDirs[dir] {
+			return fmt.Errorf("Root %q is inside the system directory %s, use a directory like /opt/dojo", root, dir)
+		}
+	}
+
+	return nil
+}
+
+// loadDistroCmds loads the commands in DistroCmdsDir so CmdsForTarget uses
+// them in place of or alongside the built-in ones for the rest of the run.
+// It's called once at startup after validateConfig.
+func loadDistroCmds(d *DDConfig) error {
+	if len(d.conf.Install.DistroCmdsDir) > 0 {
+		d.verboseMsg(fmt.Sprintf("Loading the distro commands in %+v", d.conf.Install.DistroCmdsDir))
+	}
+	err := distros.LoadCmdDir(d.conf.Install.DistroCmdsDir)
+	if err != nil {
+		return fmt.Errorf("Unable to load the DistroCmdsDir commands: %w", err)
+	}
+
+	return nil
+}
+
+// checkSource returns an error if the Source directory src isn't a directory
+// below Root, which an uninstall or a re-extract removes whole
+func checkSource(src string) error {
+	clean := filepath.Clean(strings.TrimSpace(src))
+	if len(strings.TrimSpace(src)) == 0 || clean == "." || clean == "/" {
+		return fmt.Errorf("Source can't be empty, it's the directory under Root the DefectDojo source is put in")
+	}
+	for _, p := range strings.Split(filepath.ToSlash(src), "/") {
+		if p == ".." {
+			return fmt.Errorf("Source %q can't contain .., it must be a directory under Root like django-DefectDojo", src)
+		}
+	}
+
+	return nil
+}
+
+// checkVenvPath returns an error if the VenvPath p isn't an absolute path
+// godojo can create DefectDojo's virtualenv in, checking the closest
+// directory that exists is writable when p doesn't exist yet
+func checkVenvPath(p string) error {
+	if !filepath.IsAbs(p) {
+		return fmt.Errorf("VenvPath %q must be an absolute path like /opt/dojo-venv", p)
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return fmt.Errorf("VenvPath %q can't contain .., use the path it resolves to instead", p)
+		}
+	}
+	clean := filepath.Clean(p)
+	if _, ok := systemDirs[clean]; ok || clean == "/" {
+		return fmt.Errorf("VenvPath %q is a system directory, use a directory for the virtualenv like %s/dojo-venv", p, strings.TrimSuffix(clean, "/"))
+	}
+	for dir := filepath.Dir(clean); dir != "/"; dir = filepath.Dir(dir) {
+		if systemDirs[dir] {
+			return fmt.Errorf("VenvPath %q is inside the system directory %s, use a directory like /opt/dojo-venv", p, dir)
+		}
+	}
+
+	dir := clean
+	for {
+		fi, err := os.Stat(dir)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("VenvPath %q can't be created, %s isn't a directory", p, dir)
+			}
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	if err := syscall.Access(dir, 0x2); err != nil { // W_OK
+		return fmt.Errorf("VenvPath %q can't be created, %s isn't writable: %v", p, dir, err)
+	}
+
+	return nil
+}
+
+// dbEngines maps the accepted DB.Engine values, in lower case, to the engine
+// name used by the rest of godojo
+var dbEngines = map[string]string{
+	"postgresql": "PostgreSQL",
+	"postgres":   "PostgreSQL",
+	"mysql":      "MySQL",
+	"mariadb":    "MariaDB",
+	"sqlite":     "SQLite",
 }
 
-// DojoConfig - "mother" struct to hold all the config options
-type dojoConfig struct {
-	Install  installConfig
-	Settings settingsConfig
-	Options  optionalConfig
+// setDBEngine replaces the configured DB.Engine with its canonical name so
+// values like postgres or mysql select the right database commands
+func setDBEngine(d *DDConfig) {
+	if e, ok := dbEngines[strings.ToLower(strings.TrimSpace(d.conf.Install.DB.Engine))]; ok {
+		d.conf.Install.DB.Engine = e
+	}
+	d.verboseMsg(fmt.Sprintf("Database backend selected is %+v", d.conf.Install.DB.Engine))
+}
+
+// checkURL returns an error if u isn't an absolute URL using one of the schemes
+func checkURL(u string, schemes ...string) error {
+	p, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("%q isn't a valid URL: %v", u, err)
+	}
+	for i := range schemes {
+		if p.Scheme != schemes[i] {
+			continue
+		}
+		if p.Scheme != "file" && len(p.Host) == 0 {
+			return fmt.Errorf("%q is missing a host", u)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%q must be a %s URL", u, strings.Join(schemes, ", "))
+}
+
+// setSourceURLs replaces the default release and clone URLs with the ones
+// from the config so installs can use an internal mirror of DefectDojo
+func setSourceURLs(d *DDConfig) {
+	d.releaseURL = d.conf.Install.ReleaseURL
+	if !strings.HasSuffix(d.releaseURL, "/") {
+		d.releaseURL += "/"
+	}
+	if !strings.HasSuffix(d.conf.Install.ReleaseAPIURL, "/") {
+		d.conf.Install.ReleaseAPIURL += "/"
+	}
+	d.cloneURL = d.conf.Install.CloneURL
+	d.verboseMsg(fmt.Sprintf("Release URL in effect is %+v", d.releaseURL))
+	d.verboseMsg(fmt.Sprintf("Clone URL in effect is %+v", d.cloneURL))
+}
+
+// Config - "mother" struct to hold all the config options read from
+// dojoConfig.yml, exported so it can be passed to NewInstaller
+type Config struct {
+	ConfigVersion int // Version of the config file layout, see configVersion
+	Install       installConfig
+	Settings      settingsConfig
+	Options       optionalConfig
 }
 
 // InstallConfig - struct to hold the install time options
 type installConfig struct {
 	// Installer settings
-	Version       string         // Holds the version of Dojo to check out from the repo
-	SourceInstall bool           // If true, do a source install instead of a versioned release
-	SourceBranch  string         // Branch to checkout for a source install, if SourceCommit isn't "", SourceBranch will be ignored
-	SourceCommit  string         // head or full commit hash to install a specific commit, SourceBranch will be ignored if this isn't ""
-	Quiet         bool           // If true, suppress all output except for very early errors - logs will still be written in the log directory
-	Trace         bool           // If true, log at the trace level
-	Redact        bool           // If true, redact sensitive information from being logged.  Defaults to true
-	Prompt        bool           // Prompt at run time for install config.  If true, user will be prompted
-	Mac           bool           // The install set or type: Single Server, Dev, Stand-alone
-	Root          string         // Install root defaults to /opt/dojo
-	Source        string         // Directory to put the Dojo souce, child directory of Root
-	Files         string         // Directory for locally generated files like uploads, static, media, etc
-	App           string         // Directory where the Dojo Django app lives inside of Source above
-	Sampledata    bool           // Install the sample data if true, defaults to false
-	DB            dBTarget       // struct for DB configuration values
-	OS            oSTarget       // struct for DB configuration values
-	Settings      settingsTarget // struct for DB configuration values
-	Admin         adminTarget    // struct for DB configuration values
-	PullSource    bool           // If false, installer won't download source code - primarily for debugging
+	Version                string         // Holds the version of Dojo to check out from the repo
+	SourceInstall          bool           // If true, do a source install instead of a versioned release
+	SourceBranch           string         // Branch to checkout for a source install, only one of SourceCommit, SourceTag or SourceBranch can be set
+	SourceCommit           string         // head or full commit hash to install a specific commit, only one of SourceCommit, SourceTag or SourceBranch can be set
+	ShallowClone           bool           // If true, clone only the history needed for a source install instead of the full repo
+	CloneDepth             int            // History depth for shallow commit installs, defaults to 50
+	SourceTag              string         // Git tag to checkout for a source install, only one of SourceCommit, SourceTag or SourceBranch can be set
+	Quiet                  bool           // If true with -trace-to-syslog, suppress all console output - logs will still be written to syslog and the log directory
+	Trace                  bool           // If true, log at the trace level
+	Redact                 bool           // If true, redact sensitive information from being logged.  Defaults to true
+	Prompt                 bool           // Prompt at run time for install config.  If true, user will be prompted
+	Mac                    bool           // The install set or type: Single Server, Dev, Stand-alone
+	Root                   string         // Install root defaults to /opt/dojo
+	Source                 string         // Directory to put the Dojo souce, child directory of Root
+	Files                  string         // Directory for locally generated files like uploads, static, media, etc
+	App                    string         // Directory where the Dojo Django app lives inside of Source above
+	Sampledata             bool           // Install the sample data if true, defaults to false
+	DB                     dBTarget       // struct for DB configuration values
+	OS                     oSTarget       // struct for DB configuration values
+	Settings               settingsTarget // struct for DB configuration values
+	Admin                  adminTarget    // struct for DB configuration values
+	SELinux                seLinuxTarget  // struct for SELinux configuration values
+	Systemd                systemdTarget  // struct for systemd configuration values
+	Celery                 celeryTarget   // struct for the celery worker and beat configuration values
+	Broker                 brokerTarget   // struct for the celery broker installed or checked by godojo
+	HealthCheck            healthTarget   // struct for the post-install health check values
+	Hooks                  hooksTarget    // struct for the commands run at points in the install
+	Node                   nodeTarget     // struct for the Node.js used to build the frontend
+	PullSource             bool           // If false, installer won't download source code - primarily for debugging
+	PythonMin              string         // Oldest supported Python 3 version as major.minor, defaults to 3.11
+	PythonMax              string         // Newest supported Python 3 version as major.minor, if "" there is no upper limit
+	PythonCandidates       []string       // Python interpreters to try in order, then the common install locations, using the first supported one.  Empty uses PyPath
+	PipVersion             string         // Exact pip version installed in the virtualenv before the requirements, if "" the latest pip is used
+	VirtualenvVersion      string         // Exact virtualenv version used to create the virtualenv, if "" the distro's virtualenv is used
+	VenvPath               string         // Absolute path DefectDojo's virtualenv is created in, if "" the virtualenv is Root itself
+	Requirements           string         // Path to a pip requirements file installed instead of the source's requirements.txt, if "" the source's is used
+	DownloadAttempts       int            // Number of times to try downloading a release or cloning/fetching the source, defaults to 3
+	DownloadTimeoutSeconds int            // Seconds before a release download times out, defaults to 120 and 0 means no timeout
+	DownloadDelay          int            // Seconds to wait before the first download or clone retry, doubled for each retry after, defaults to 2
+	MaxDownloadRate        int64          // Most bytes per second used downloading a release, defaults to 0 which means unlimited
+	ResumeDownload         bool           // If true, keep a partial release download the server can resume for the next run, defaults to true
+	KeepTarball            bool           // If true, keep the downloaded release tarball after it's verified and extracted, defaults to true
+	CmdTimeoutMinutes      int            // Minutes before an OS command is killed unless its distro definition sets a Timeout, defaults to 30 and 0 means no timeout
+	ParallelCmds           int            // Most OS commands marked as independent to run at once, defaults to 4 and 1 runs every command in order
+	LocalTarball           string         // Path to a pre-staged release tarball to install instead of downloading one
+	Checksum               string         // SHA256 checksum of the release tarball, if "" the published .sha256 file is used
+	AllowUnverified        bool           // If true, install a release that has no Checksum or published .sha256 without verifying it, defaults to false, the default Github archive only warns
+	ExtractMultiplier      float64        // Estimated extracted size of a release as a multiple of the tarball size for the disk space check, defaults to 4
+	VerifySignature        bool           // If true, verify the release against its .asc GPG signature using SigningKey, defaults to false
+	SigningKey             string         // Path to the armored PGP public key used to verify release signatures
+	ReleaseURL             string         // Base URL releases are downloaded from as <ReleaseURL><Version>.tar.gz, defaults to DefectDojo's Github archive
+	ReleaseUser            string         // User for release mirrors protected by HTTP basic auth, if "" no credentials are sent
+	ReleasePass            string         // Password for ReleaseUser, prefer ReleasePassEnv to keep it out of the config file
+	ReleasePassEnv         string         // Name of the env variable holding the password for ReleaseUser
+	ReleaseAPI             bool           // If true, look up the release download with the Github releases API at ReleaseAPIURL instead of using ReleaseURL, defaults to false
+	ReleaseAPIURL          string         // Github releases API endpoint a release is looked up at as <ReleaseAPIURL><tag>
+	ReleaseAsset           string         // Name or glob pattern of the release asset downloaded with ReleaseAPI, if "" the source tarball is used
+	LatestURL              string         // Github releases API endpoint queried for the newest stable release when Version is latest
+	GithubToken            string         // Token sent to github.com to raise the Github rate limit, prefer GithubTokenEnv to keep it out of the config file
+	GithubTokenEnv         string         // Name of the env variable holding the token sent to github.com to raise the Github rate limit
+	CloneURL               string         // URL of the git repo cloned for source installs, defaults to DefectDojo's Github repo
+	GitUser                string         // User for authenticated clones, defaults to git
+	GitToken               string         // HTTPS token for cloning a private repo, prefer GitTokenEnv to keep it out of the config file
+	GitTokenEnv            string         // Name of the env variable holding the HTTPS token for cloning a private repo
+	GitSSHKey              string         // Path to the SSH private key for cloning a private repo over SSH
+	GitSSHKeyPass          string         // Passphrase for GitSSHKey, if any
+	CABundle               string         // Path to a PEM CA bundle, or a directory of them, trusted for downloads and clones in addition to the system CAs
+	DistroCmdsDir          string         // Directory of YAML files with distro commands that replace or add to the built-in ones, if "" only the built-in commands are used
 }
 
 // DBTarget - struct to hold Install.DB options
 type dBTarget struct {
-	Engine string
-	Local  bool
-	Exists bool
-	Ruser  string
-	Rpass  string
-	Name   string
-	User   string
-	Pass   string
-	Host   string
-	Port   int
-	Drop   bool
+	Engine         string
+	Local          bool
+	Exists         bool
+	Ruser          string
+	Rpass          string
+	Name           string
+	User           string
+	Pass           string
+	Host           string
+	Port           int
+	Drop           bool
+	ConnectTimeout int // Seconds to wait when verifying the database connection after install, defaults to 10
 }
 
 // OSTarget - struct to hold Install.OS options
@@ -154,6 +710,76 @@ type oSTarget struct {
 	User  string
 	Pass  string
 	Group string
+	UID   int    // UID for the DefectDojo OS user, 0 lets the OS pick one
+	GID   int    // GID for the DefectDojo OS group, 0 lets the OS pick one
+	Home  string // Home directory for the DefectDojo OS user, defaults to /home/<User>
+}
+
+// SELinuxTarget - struct to hold Install.SELinux options
+type seLinuxTarget struct {
+	Manage      bool   // If true, set SELinux contexts on RHEL-family distros when SELinux is enabled, defaults to true
+	FileContext string // SELinux type for the install root, defaults to httpd_sys_content_t
+	RWContext   string // SELinux type for the writable Files directory, defaults to httpd_sys_rw_content_t
+	PortType    string // SELinux type for the ports DefectDojo binds, defaults to http_port_t
+	Ports       []int  // TCP ports to label with PortType in addition to Settings.UwsgiPort
+}
+
+// SystemdTarget - struct to hold Install.Systemd options
+type systemdTarget struct {
+	Manage         bool   // If true, create and enable systemd units for DefectDojo when systemd is running, defaults to true
+	UnitDir        string // Directory the unit files are written to, defaults to /etc/systemd/system
+	AppTemplate    string // Path to a template used instead of the default for the uwsgi app unit
+	WorkerTemplate string // Path to a template used instead of the default for the celery worker unit
+	BeatTemplate   string // Path to a template used instead of the default for the celery beat unit
+}
+
+// CeleryTarget - struct to hold Install.Celery options
+type celeryTarget struct {
+	Manage        bool              // If true, set up the celery worker and beat, defaults to true
+	Manager       string            // What runs the celery worker and beat, systemd or supervisor, defaults to systemd
+	Concurrency   int               // Number of celery worker processes, 0 is one per CPU, defaults to 1
+	SupervisorDir string            // Directory the supervisor config is written to, defaults to /etc/supervisor/conf.d
+	Env           map[string]string // Extra environment variables for the celery worker and beat
+}
+
+// BrokerTarget - struct to hold Install.Broker options
+type brokerTarget struct {
+	Engine   string // Celery broker to install, Redis or RabbitMQ, if "" no broker is installed
+	External bool   // If true, use a broker godojo doesn't install at Host and only check it can be reached, defaults to false
+	Host     string // Address the broker listens on, or the host of an External broker, defaults to 127.0.0.1
+	Port     int    // Port of the broker, 0 is the Engine's default of 6379 for Redis and 5672 for RabbitMQ
+	User     string // RabbitMQ user celery connects as, defaults to defectdojo
+	Pass     string // Password for the broker, required unless External
+}
+
+// HealthTarget - struct to hold Install.HealthCheck options
+type healthTarget struct {
+	Enabled         bool   // If true, start DefectDojo after the install and wait for it to answer with a 200, defaults to false
+	URL             string // Full URL to poll instead of http://127.0.0.1:<Port><Path>
+	Port            int    // Port for the default URL, defaults to Settings.UwsgiPort or 8000
+	Path            string // Path for the default URL, defaults to /login
+	TimeoutSeconds  int    // Seconds to wait for a 200 before the check fails, defaults to 180
+	IntervalSeconds int    // Seconds between requests, defaults to 3
+}
+
+// NodeTarget - struct to hold Install.Node options
+type nodeTarget struct {
+	Version string // Node.js version for the frontend build like 20 or 20.11.1, if "" the Node.js from the OS packages is used
+	Method  string // How Version is installed: distro, nodesource or nvm, defaults to nodesource
+	NVMURL  string // URL of the nvm install script for the nvm Method
+}
+
+// HooksTarget - struct to hold Install.Hooks options
+type hooksTarget struct {
+	PostDownload []hookCmd // Run after the release is downloaded and verified but before it's extracted
+	PostExtract  []hookCmd // Run after the release is extracted, before DefectDojo is configured
+	PostInstall  []hookCmd // Run after every install phase has completed
+}
+
+// HookCmd - a command in Install.Hooks
+type hookCmd struct {
+	Cmd  string // Command run with bash from Install.Root
+	Hard bool   // If true, the install stops when the command fails
 }
 
 // SettingsTarget - struct to hold Install.Settings options
@@ -272,15 +898,16 @@ type settingsConfig struct {
 // OptionalConfig values added to make developing and testing godojo easier
 // AKA you should never really need to change these.
 type optionalConfig struct {
-	HelpURL    string `yaml:"HelpURL"`
-	ReleaseURL string `yaml:"ReleaseURL"`
-	CloneURL   string `yaml:"CloneURL"`
-	YarnGPG    string `yaml:"YarnGPG"`
-	YarnRepo   string `yaml:"YarnRepo"`
-	NodeURL    string `yaml:"NodeURL"`
-	Embd       bool   `yaml:"Embd"`
-	Key        string `yaml:"Key"`
-	Tmpdir     string `yaml:"Tmpdir"`
-	UsrInst    bool   `yaml:"UsrInst"`
-	PyPath     string `yaml:"PyPath"`
+	HelpURL   string `yaml:"HelpURL"`
+	YarnGPG   string `yaml:"YarnGPG"`
+	YarnRepo  string `yaml:"YarnRepo"`
+	NodeURL   string `yaml:"NodeURL"`
+	Embd      bool   `yaml:"Embd"`
+	Key       string `yaml:"Key"`
+	Tmpdir    string `yaml:"Tmpdir"`
+	UsrInst   bool   `yaml:"UsrInst"`
+	PyPath    string `yaml:"PyPath"`
+	Proxy     string `yaml:"Proxy"`
+	ProxyUser string `yaml:"ProxyUser"`
+	ProxyPass string `yaml:"ProxyPass"`
 }
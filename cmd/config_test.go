@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckRootRejectsUnsafePaths(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		want string
+	}{
+		{name: "empty", root: "", want: "can't be empty"},
+		{name: "blank", root: "   ", want: "can't be empty"},
+		{name: "relative", root: "opt/dojo", want: "absolute path"},
+		{name: "dot relative", root: "./dojo", want: "absolute path"},
+		{name: "root", root: "/", want: "can't be /"},
+		{name: "root with slashes", root: "//", want: "can't be /"},
+		{name: "traversal", root: "/opt/dojo/../../etc", want: "can't contain .."},
+		{name: "traversal to root", root: "/opt/..", want: "can't contain .."},
+		{name: "system directory", root: "/usr", want: "system directory"},
+		{name: "system directory trailing slash", root: "/etc/", want: "system directory"},
+		{name: "inside system directory", root: "/etc/dojo", want: "inside the system directory /etc"},
+		{name: "deep inside system directory", root: "/usr/lib/python3/dojo", want: "inside the system directory /usr/lib"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRoot(tc.root)
+			if err == nil {
+				t.Fatalf("Expected an error for Root %q, got nil", tc.root)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected the error for Root %q to contain %q, got %v", tc.root, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestCheckRootAcceptsSafePaths(t *testing.T) {
+	for _, root := range []string{"/opt/dojo", "/opt/dojo/", "/srv/defectdojo", "/var/lib/dojo", "/usr/local/dojo", "/home/dojo/app", "/opt/dojo..v2"} {
+		t.Run(root, func(t *testing.T) {
+			if err := checkRoot(root); err != nil {
+				t.Errorf("Expected no error for Root %q, got %v", root, err)
+			}
+		})
+	}
+}
+
+func TestUninstallPathsRejectsUnsafeConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		root   string
+		source string
+		want   string
+	}{
+		{name: "root is /", root: "/", source: "django-DefectDojo", want: "can't be /"},
+		{name: "root is / and empty source", root: "/", source: "", want: "can't be /"},
+		{name: "empty source", root: "/opt/dojo", source: "", want: "Source can't be empty"},
+		{name: "dot source", root: "/opt/dojo", source: ".", want: "Source can't be empty"},
+		{name: "slash source", root: "/opt/dojo", source: "/", want: "Source can't be empty"},
+		{name: "traversal source", root: "/opt/dojo", source: "../..", want: "can't contain .."},
+		{name: "nested traversal source", root: "/opt/dojo", source: "django-DefectDojo/../../etc", want: "can't contain .."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newTestConfig()
+			d.conf.Install.Root = tc.root
+			d.conf.Install.Source = tc.source
+			_, _, err := uninstallPaths(d)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Expected an error containing %q for Root %q and Source %q, got %v", tc.want, tc.root, tc.source, err)
+			}
+		})
+	}
+
+	d := newTestConfig()
+	d.conf.Install.Root = "/opt/dojo"
+	d.conf.Install.Source = "django-DefectDojo"
+	d.conf.Install.Version = "2.30.0"
+	src, tarball, err := uninstallPaths(d)
+	if err != nil || src != "/opt/dojo/django-DefectDojo" || tarball != "/opt/dojo/dojo-v2.30.0.tar.gz" {
+		t.Errorf("Expected the source and tarball under /opt/dojo, got %s, %s, %v", src, tarball, err)
+	}
+
+	// Version: latest removes the tarball of the release that was installed
+	root := t.TempDir()
+	makeTree(t, filepath.Join(root, "django-DefectDojo"), map[string]string{"dojo/__init__.py": "__version__ = '2.32.2'\n"})
+	d.conf.Install.Root = root
+	d.conf.Install.App = "dojo"
+	d.conf.Install.Version = latestVersion
+	_, tarball, err = uninstallPaths(d)
+	if want := filepath.Join(root, "dojo-v2.32.2.tar.gz"); err != nil || tarball != want {
+		t.Errorf("Expected the tarball %s for Version: latest, got %s, %v", want, tarball, err)
+	}
+}
+
+func TestMaintenanceOnlyRecordedForUpgrade(t *testing.T) {
+	d := newTestConfig()
+	d.conf.Install.Root = t.TempDir()
+	d.phaseState = ".godojo-phases"
+
+	if err := runMaintenance(d); err != nil {
+		t.Fatal(err)
+	}
+	if phaseDone(d, phaseMaintenance) {
+		t.Fatal("Expected the maintenance phase not to be recorded without -upgrade")
+	}
+	if _, err := writePhases(d, []string{phaseBootstrap}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runMaintenance(d); err != nil {
+		t.Fatal(err)
+	}
+	if phases := readPhases(d); len(phases) != 1 || phases[0] != phaseBootstrap {
+		t.Errorf("Expected only the bootstrap phase in %s, got %v", filepath.Join(d.conf.Install.Root, d.phaseState), phases)
+	}
+}
+
+func TestFailedPhaseNotRecorded(t *testing.T) {
+	d := newTestConfig()
+	d.conf.Install.Root = t.TempDir()
+	d.phaseState = ".godojo-phases"
+
+	cause := errors.New("command failed")
+	err := runPhase(d, phaseDownload, func() error { return cause })
+	if !errors.Is(err, cause) {
+		t.Fatalf("Expected the phase's error to be returned, got %v", err)
+	}
+	if phaseDone(d, phaseDownload) {
+		t.Error("Expected the failed download phase not to be recorded")
+	}
+	if d.phase != phaseDownload {
+		t.Errorf("Expected the failed phase %s to be left in d.phase, got %q", phaseDownload, d.phase)
+	}
+}
+
+func TestAccountState(t *testing.T) {
+	d := newTestConfig()
+	d.conf.Install.Root = t.TempDir()
+	d.accountState = ".godojo-accounts"
+
+	recordAccount(d, acctUser, "dojo", acctCreated)
+	recordAccount(d, acctGroup, "www-data", acctExisting)
+	if !accountCreated(d, acctUser, "dojo") {
+		t.Error("Expected the created user dojo to be recorded as created")
+	}
+	if accountCreated(d, acctGroup, "www-data") {
+		t.Error("Expected the existing group www-data not to be recorded as created")
+	}
+
+	// A re-run finds the user an earlier run created
+	recordAccount(d, acctUser, "dojo", acctExisting)
+	if !accountCreated(d, acctUser, "dojo") {
+		t.Error("Expected the user dojo to stay recorded as created when a re-run finds it")
+	}
+
+	forgetAccount(d, acctUser, "dojo")
+	forgetAccount(d, acctGroup, "www-data")
+	if _, err := os.Stat(filepath.Join(d.conf.Install.Root, d.accountState)); !os.IsNotExist(err) {
+		t.Errorf("Expected the account state file to be removed once empty, got %v", err)
+	}
+}
+
+func TestRemoveAccountOnlyCreated(t *testing.T) {
+	d := newTestConfig()
+	d.conf.Install.Root = t.TempDir()
+	d.accountState = ".godojo-accounts"
+
+	// root is never removed, even when recorded as created
+	recordAccount(d, acctUser, "root", acctCreated)
+	removeAccount(d, acctUser, "root")
+	if !accountCreated(d, acctUser, "root") {
+		t.Error("Expected removing the root user to be refused and left recorded")
+	}
+
+	// An account that's already gone is dropped from the record
+	recordAccount(d, acctGroup, "godojo-no-such-group", acctCreated)
+	removeAccount(d, acctGroup, "godojo-no-such-group")
+	if accountCreated(d, acctGroup, "godojo-no-such-group") {
+		t.Error("Expected a removed group to no longer be recorded")
+	}
+}
+
+func TestUninstallVenv(t *testing.T) {
+	root := t.TempDir()
+	ext := filepath.Join(t.TempDir(), "dojo-venv")
+	tests := []struct {
+		name string
+		venv string
+		want string
+		err  bool
+	}{
+		{name: "default virtualenv in Root", venv: "", want: ""},
+		{name: "virtualenv inside Root", venv: filepath.Join(root, "venv"), want: ""},
+		{name: "virtualenv outside Root", venv: ext, want: ext},
+		{name: "virtualenv is /", venv: "/", err: true},
+		{name: "virtualenv is a system directory", venv: "/usr", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newTestConfig()
+			d.conf.Install.Root = root
+			d.conf.Install.VenvPath = tc.venv
+			got, err := uninstallVenv(d)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("Expected an error for VenvPath %q, got %q", tc.venv, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("Expected %q for VenvPath %q, got %q, %v", tc.want, tc.venv, got, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigDoesNotKeepEarlierFile(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	if err := os.WriteFile(filepath.Join(dir, "a.yml"), []byte("Install:\n  Version: 2.30.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dojoConfig.yml"), []byte("Install:\n  Version: 2.31.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadConfig(filepath.Join(dir, "a.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Install.Version != "2.30.0" {
+		t.Fatalf("Expected Version 2.30.0 from a.yml, got %s", c.Install.Version)
+	}
+	c, err = LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Install.Version != "2.31.0" {
+		t.Errorf("Expected Version 2.31.0 from ./dojoConfig.yml, got %s", c.Install.Version)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// confirm prints the question q and returns true if the user answers yes
+func confirm(q string) bool {
+	fmt.Printf("%s [y/N] ", q)
+	in, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	a := strings.ToLower(strings.TrimSpace(in))
+	return a == "y" || a == "yes"
+}
+
+// confirmDestructive takes a pointer to a DDConfig struct and a description of
+// a step that could lose data like "drop the existing database" and asks the
+// user to confirm it, returning an error if they don't.  Nothing is asked with
+// -yes or for dry runs and an error is returned when stdin isn't a terminal to
+// answer the prompt.
+func confirmDestructive(d *DDConfig, what string) error {
+	if d.yes {
+		d.traceMsg(fmt.Sprintf("-yes set so not confirming before godojo will %+v", what))
+		return nil
+	}
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would ask for confirmation before godojo will " + what)
+		return nil
+	}
+	if d.logger != nil || (!isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd())) {
+		return fmt.Errorf("godojo needs to %s but can't prompt to confirm it, re-run godojo with -yes to allow this without a prompt", what)
+	}
+
+	// Pause any running spinner so it doesn't write over the prompt
+	if d.spin != nil && d.spin.Active() {
+		d.spin.Spinner.Stop()
+		defer d.spin.Spinner.Start()
+	}
+	if !confirm(fmt.Sprintf("godojo needs to %s, continue?", what)) {
+		return errors.New("install cancelled, re-run godojo with -yes to skip this prompt")
+	}
+	d.traceMsg(fmt.Sprintf("User confirmed godojo will %+v", what))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// configVersion is the newest ConfigVersion of dojoConfig.yml this godojo
+// understands.  Bump it and add a configMigration when a config option is
+// renamed, moved or changes meaning so older config files still work.
+const configVersion = 1
+
+// configMigration updates a config written for ConfigVersion from to the next
+// version.  fn gets the config file's own values, without defaults or env
+// variables, to see what the file sets and returns a note for each change it
+// made to the config c.
+type configMigration struct {
+	from int
+	desc string
+	fn   func(f *viper.Viper, c *Config) []string
+}
+
+// configMigrations are applied in order to configs older than configVersion
+var configMigrations = []configMigration{
+	{from: 0, desc: "Options.ReleaseURL and Options.CloneURL moved to Install", fn: migrateSourceURLs},
+}
+
+// migrateConfig takes a pointer to a DDConfig struct and applies the
+// migrations needed to bring the config read into d.conf from an older
+// ConfigVersion up to configVersion, logging each one applied.  Config files
+// without ConfigVersion are version 0.  An error is returned for a config
+// newer than this godojo understands rather than guess at what it means.
+func migrateConfig(d *DDConfig) error {
+	ver := d.conf.ConfigVersion
+	switch {
+	case ver > configVersion:
+		return fmt.Errorf("The godojo config file (%s) is ConfigVersion %d but this godojo only understands up to ConfigVersion %d\n"+
+			"  Upgrade godojo or use a config file written for godojo %s", configName(d), ver, configVersion, d.ver)
+	case ver < 0:
+		return fmt.Errorf("The godojo config file (%s) has an invalid ConfigVersion of %d", configName(d), ver)
+	case ver == configVersion:
+		d.traceMsg(fmt.Sprintf("Config file is ConfigVersion %d, no migrations needed", ver))
+		return nil
+	}
+
+	// Migrations need to know what the file itself sets, not the defaults
+	f := viper.New()
+	f.SetConfigType("yml")
+	f.SetConfigFile(d.viper.ConfigFileUsed())
+	err := f.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("Unable to re-read the godojo config file (%s) to migrate it\nError was: %v", configName(d), err)
+	}
+
+	for _, m := range configMigrations {
+		if m.from < ver {
+			continue
+		}
+		d.traceMsg(fmt.Sprintf("Applying config migration from ConfigVersion %d: %s", m.from, m.desc))
+		for _, n := range m.fn(f, &d.conf) {
+			d.statusMsg(fmt.Sprintf("Config migration from ConfigVersion %d: %s", m.from, n))
+		}
+	}
+	d.statusMsg(fmt.Sprintf("Migrated the config file (%s) from ConfigVersion %d to %d, update the file and set "+
+		"ConfigVersion: %d to stop these messages", configName(d), ver, configVersion, configVersion))
+	d.conf.ConfigVersion = configVersion
+
+	return nil
+}
+
+// migrateSourceURLs moves the release and clone URLs from Options, where
+// older configs had them but godojo no longer reads them, to Install unless
+// the file already sets the Install version
+func migrateSourceURLs(f *viper.Viper, c *Config) []string {
+	var notes []string
+	moves := []struct {
+		old string
+		new string
+		to  *string
+	}{
+		{"Options.ReleaseURL", "Install.ReleaseURL", &c.Install.ReleaseURL},
+		{"Options.CloneURL", "Install.CloneURL", &c.Install.CloneURL},
+	}
+	for _, m := range moves {
+		if !f.IsSet(m.old) {
+			continue
+		}
+		v := f.GetString(m.old)
+		switch {
+		case !f.IsSet(m.new):
+			*m.to = v
+			notes = append(notes, fmt.Sprintf("using %s for %s", m.old, m.new))
+		case f.GetString(m.new) != v:
+			notes = append(notes, fmt.Sprintf("%s is set so %s is ignored, remove %s", m.new, m.old, m.old))
+		default:
+			notes = append(notes, fmt.Sprintf("%s is the same as %s, remove %s", m.old, m.new, m.old))
+		}
+	}
+
+	return notes
+}
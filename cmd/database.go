@@ -7,9 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/defectdojo/godojo/distros"
 	c "github.com/mtesauro/commandeer"
 )
@@ -24,209 +22,218 @@ type sqlStr struct {
 }
 
 // saneDBConfig checks if the options configured in dojoConfig.yml are
-// possible aka sane and will exist the installer with a message if they are not
-func saneDBConfig(d *DDConfig) {
+// possible aka sane and returns an error saying why if they are not
+func saneDBConfig(d *DDConfig) error {
 	// Remote database that doesn't exist - godojo can't help you here
 	if !d.conf.Install.DB.Local && !d.conf.Install.DB.Exists {
-		d.errorMsg("Remote database which doens't exist was confgiured in dojoConfig.yml.")
-		d.errorMsg("This is an unsupported configuration.")
-		d.statusMsg("Correct configuration and/or install a remote DB before running installer again.")
-		fmt.Printf("Exiting...\n\n")
-		os.Exit(1)
+		return errors.New("Remote database which doens't exist was confgiured in dojoConfig.yml.\n" +
+			"  This is an unsupported configuration.\n" +
+			"  Correct configuration and/or install a remote DB before running installer again.")
 	}
+
+	return nil
 }
 
-// prepDBForDojo
-func installDBForDojo(d *DDConfig, t *targetOS) {
+// installDB installs a local database that doesn't exist yet, the client for
+// a remote database, and starts a newly installed local database
+func installDB(d *DDConfig, t *targetOS) error {
+	// Make sure the DefectDojo being installed supports the configured database
+	err := checkDBSupport(d)
+	if err != nil {
+		return &InstallError{Kind: ErrDatabase, Err: err}
+	}
+	d.traceMsg(fmt.Sprintf("Database backend is %s, using the %s install and setup commands", d.conf.Install.DB.Engine, dbFamily(d)))
+
 	// Handle the case that the DB is local and doesn't exist
 	if !d.conf.Install.DB.Exists {
 		// Note that godojo won't try to install remote databases
-		dbNotExist(d, t)
+		err = dbNotExist(d, t)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Install DB clients for remote DBs
 	if !d.conf.Install.DB.Local {
-		dbClient(d, t)
+		err = dbClient(d, t)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Start the database if local and didn't already exist
 	if d.conf.Install.DB.Local && !d.conf.Install.DB.Exists {
-		localDBStart(d, t)
+		return startLocalDB(d, t)
 	}
 
+	return nil
+}
+
+// dbLookups are the functions returning the database commands for each distro
+var dbLookups = map[string]func(*c.CmdPkg, string, string) error{
+	"ubuntu": distros.GetUbuntuDB,
+	"debian": distros.GetDebianDB,
+	"rhel":   distros.GetRHELDB,
+	"amazon": distros.GetAmazonDB,
+	"fedora": distros.GetFedoraDB,
+	"arch":   distros.GetArchDB,
+	"gentoo": distros.GetGentooDB,
+	"suse":   distros.GetSUSEDB,
+}
+
+// dbTargetCmds adds the commands for the command package's label and the
+// configured database on the target OS t to p and returns them.  what is what
+// the commands do for the error messages, e.g. install the DB client.
+func dbTargetCmds(d *DDConfig, t *targetOS, p *c.CmdPkg, what string) ([]distros.Cmd, error) {
+	get, ok := dbLookups[t.distro]
+	if !ok {
+		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
+	}
+	d.traceMsg(fmt.Sprintf("Searching for commands to %s of %s on %s", what, dbFamily(d), t.id))
+	err := get(p, t.id, dbFamily(d))
+	if err != nil {
+		return nil, fmt.Errorf("error searching for commands to %s on target OS %s: %w", what, t.id, err)
+	}
+	tCmds, err := distros.CmdsForTarget(p, t.id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting commands to %s on target OS %s: %w", what, t.id, err)
+	}
+	if t.distro == "gentoo" {
+		d.traceMsg("Portage builds the database packages from source so this may take a while")
+	}
+
+	return tCmds, nil
 }
 
 // dbNotExist takes a pointer to a DDConfig struct and a pointer to targetOS
 // struct and runs the commands necesary to install a local database of the
 // supported type (PostgreSQL, MySQL, etc)
-func dbNotExist(d *DDConfig, t *targetOS) {
+func dbNotExist(d *DDConfig, t *targetOS) error {
 	// Handle the case that the DB is local and doesn't exist
 	d.sectionMsg("Installing database needed for DefectDojo")
 
-	// Create a new install DB command package
-	cInstallDB := c.NewPkg("installdb")
-
 	// Get commands for the right distro & DB
-	switch {
-	case t.distro == "ubuntu":
-		d.traceMsg("DB needs to be installed on Ubuntu")
-		err := distros.GetUbuntuDB(cInstallDB, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to install DB on target OS %s was\n", t.id)
-			fmt.Printf("\t%+v\n", err)
-			os.Exit(1)
-		}
-		if strings.ToLower(d.conf.Install.DB.Engine) == "mysql" {
-			d.warnMsg("WARNING: While supported, there is significantly more testing with PostreSQL than MySQL. YMMV.")
-		}
-	case t.distro == "rhel":
-		d.traceMsg("DB needs to be installed on RHEL")
-		err := distros.GetRHELDB(cInstallDB, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to install DB on target OS %s was\n", t.id)
-			fmt.Printf("\t%+v\n", err)
-			os.Exit(1)
-		}
-		if strings.ToLower(d.conf.Install.DB.Engine) == "mysql" {
-			d.warnMsg("WARNING: While supported, there is significantly more testing with PostreSQL than MySQL. YMMV.")
-		}
-	default:
-		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+	tCmds, err := dbTargetCmds(d, t, c.NewPkg("installdb"), "install the database")
+	if err != nil {
+		return err
+	}
+	if dbFamily(d) == "MySQL" {
+		d.warnMsg("WARNING: While supported, there is significantly more testing with PostreSQL than MySQL. YMMV.")
 	}
 
 	// Run the commands to install the chosen DB
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Installing " + d.conf.Install.DB.Engine + " database for DefectDojo..."
+	d.spin = d.newSpinner("Installing " + d.conf.Install.DB.Engine + " database for DefectDojo...")
 	d.spin.Start()
-	// Run the install DB for the target OS
-	tCmds, err := distros.CmdsForTarget(cInstallDB, t.id)
+	err = runCmds(d, tCmds)
 	if err != nil {
-		fmt.Printf("Error getting commands to install DB target OS %s\n", t.id)
-		os.Exit(1)
-	}
-
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+		d.spin.Fail()
+		return &InstallError{Kind: ErrDatabase, Op: "installing the " + d.conf.Install.DB.Engine + " database", Err: err}
 	}
 	d.spin.Stop()
 	d.statusMsg("Installing Database complete")
+
+	return nil
 }
 
-// dbClientInstall
-func dbClient(d *DDConfig, t *targetOS) {
-	// Handle the case that the DB is local and doesn't exist
+// dbClient runs the commands to install the client for a remote database
+func dbClient(d *DDConfig, t *targetOS) error {
 	d.sectionMsg("Installing database client needed for DefectDojo")
 
-	// Create a new install DB client command package
-	cInstallDBClient := c.NewPkg("installdbclient")
-
 	// Get the commands for the right distro & DB
-	switch {
-	case t.distro == "ubuntu":
-		d.traceMsg("DB client needs to be installed on Ubuntu")
-		err := distros.GetUbuntuDB(cInstallDBClient, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to install DB client on target OS %s was\n", t.id)
-			fmt.Printf("\t%+v\n", err)
-			os.Exit(1)
-		}
-	case t.distro == "rhel":
-		d.traceMsg("DB client needs to be installed on RHEL")
-		err := distros.GetRHELDB(cInstallDBClient, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to install DB client on target OS %s was\n", t.id)
-			fmt.Printf("\t%+v\n", err)
-			os.Exit(1)
-		}
-	default:
-		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+	tCmds, err := dbTargetCmds(d, t, c.NewPkg("installdbclient"), "install the database client")
+	if err != nil {
+		return err
 	}
 
-	// Run the commands to install the chosen DB
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Installing " + d.conf.Install.DB.Engine + " database client for DefectDojo..."
+	// Run the commands to install the chosen DB client
+	d.spin = d.newSpinner("Installing " + d.conf.Install.DB.Engine + " database client for DefectDojo...")
 	d.spin.Start()
-	// Run the install DB client for the target OS
-	tCmds, err := distros.CmdsForTarget(cInstallDBClient, t.id)
+	err = runCmds(d, tCmds)
 	if err != nil {
-		fmt.Printf("Error getting commands to install DB target OS %s\n", t.id)
-		os.Exit(1)
-	}
-
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+		d.spin.Fail()
+		return &InstallError{Kind: ErrDatabase, Op: "installing the " + d.conf.Install.DB.Engine + " database client", Err: err}
 	}
 	d.spin.Stop()
 	d.statusMsg("Installing Database client complete")
 
+	return nil
 }
 
-// localDBStart
-func localDBStart(d *DDConfig, t *targetOS) {
-	// Handle the case that the DB is local and doesn't exist
+// startLocalDB runs the commands to start the local database for the target OS
+func startLocalDB(d *DDConfig, t *targetOS) error {
 	d.sectionMsg("Starting the database needed for DefectDojo")
 
-	// Create new boostrap command package
-	cStartDB := c.NewPkg("startdb")
-
 	// Get commands for the right distro
-	switch {
-	case t.distro == "ubuntu":
-		d.traceMsg("Searching for commands to start MySQL under Ubuntu")
-		err := distros.GetUbuntuDB(cStartDB, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to start database under target OS %s\n", t.id)
-			os.Exit(1)
-		}
-	case t.distro == "rhel":
-		d.traceMsg("Searching for commands to start MySQL under RHEL")
-		err := distros.GetRHELDB(cStartDB, t.id, d.conf.Install.DB.Engine)
-		if err != nil {
-			fmt.Printf("Error searching for commands to start database under target OS %s\n", t.id)
-			os.Exit(1)
-		}
-	default:
-		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+	tCmds, err := dbTargetCmds(d, t, c.NewPkg("startdb"), "start the database")
+	if err != nil {
+		return err
 	}
 
-	// Run the commands to install the chosen DB
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Starting " + d.conf.Install.DB.Engine + " database for DefectDojo..."
-	d.spin.Start()
 	// Run the start DB command(s) for the target OS
-	tCmds, err := distros.CmdsForTarget(cStartDB, t.id)
+	d.spin = d.newSpinner("Starting " + d.conf.Install.DB.Engine + " database for DefectDojo...")
+	d.spin.Start()
+	err = runCmds(d, tCmds)
 	if err != nil {
-		fmt.Printf("Error getting commands to start DB on target OS %s\n", t.id)
-		os.Exit(1)
-	}
-
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+		d.spin.Fail()
+		return err
 	}
 	d.spin.Stop()
 	d.statusMsg("Starting Database complete")
+
+	return nil
 }
 
-// prepDBForDojo
-func prepDBForDojo(d *DDConfig, t *targetOS) {
+// dbFamily returns the database engine whose commands are used for the
+// configured DB.Engine, MariaDB uses the MySQL commands
+func dbFamily(d *DDConfig) string {
+	if d.conf.Install.DB.Engine == "MariaDB" {
+		return "MySQL"
+	}
+
+	return d.conf.Install.DB.Engine
+}
+
+// dbDrivers maps the database engines to the prefix of the Python package
+// DefectDojo's requirements.txt needs to use that database
+var dbDrivers = map[string]string{
+	"PostgreSQL": "psycopg",
+	"MySQL":      "mysqlclient",
+}
+
+// checkDBSupport returns an error if the requirements.txt of the DefectDojo
+// source being installed doesn't include the Python driver for the configured
+// database engine, e.g. a release that has dropped MySQL support
+func checkDBSupport(d *DDConfig) error {
+	drv, ok := dbDrivers[dbFamily(d)]
+	if !ok {
+		d.traceMsg(fmt.Sprintf("No Python driver check for the %s database backend", d.conf.Install.DB.Engine))
+		return nil
+	}
+	req := requirementsFile(d)
+	f, err := os.Open(req)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to read %+v to check database support, error was: %+v", req, err))
+		return nil
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(sc.Text())), drv) {
+			d.traceMsg(fmt.Sprintf("Found %+v in %+v, %s is supported", strings.TrimSpace(sc.Text()), req, d.conf.Install.DB.Engine))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("The %s database engine isn't supported by the DefectDojo being installed, its requirements.txt "+
+		"doesn't include the %s Python package.\n  Use a DefectDojo version that supports %s or change DB.Engine",
+		d.conf.Install.DB.Engine, drv, d.conf.Install.DB.Engine)
+}
+
+// setupDB prepares the database for DefectDojo and starts it when it's local,
+// the returned error matches ErrDatabase
+func setupDB(d *DDConfig, t *targetOS) error {
 	// Preapare the database for DefectDojo by:
 	// (1) Checking connectivity to the DB,
 	// (2) checking that the configured Dojo database name doesn't exit already
@@ -236,24 +243,28 @@ func prepDBForDojo(d *DDConfig, t *targetOS) {
 	d.sectionMsg("Preparing the database needed for DefectDojo")
 	err := dbPrep(d, t)
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("%+v", err))
-		os.Exit(1)
+		return &InstallError{Kind: ErrDatabase, Op: "preparing the " + d.conf.Install.DB.Engine + " database", Err: err}
 	}
 
 	// Start the installed DB
 	if d.conf.Install.DB.Local {
 		d.traceMsg("Starting the local DB")
-		localDBStart(d, t)
+		err = startLocalDB(d, t)
+		if err != nil {
+			return &InstallError{Kind: ErrDatabase, Op: "starting the local " + d.conf.Install.DB.Engine + " database", Err: err}
+		}
 	}
 
+	return nil
 }
 
 // dbPrep
 func dbPrep(d *DDConfig, t *targetOS) error {
 	// Call the necessary function for the supported DB engines
-	switch d.conf.Install.DB.Engine {
+	d.traceMsg(fmt.Sprintf("Preparing the %s database backend", d.conf.Install.DB.Engine))
+	switch dbFamily(d) {
 	case "MySQL":
-		// Generate commands to install MySQL
+		// Generate commands to install MySQL, also used for MariaDB
 		return prepMySQL(d, t.id)
 	case "PostgreSQL":
 		// Generate commands to install PostgreSQL
@@ -276,7 +287,11 @@ func prepMySQL(d *DDConfig, osTar string) error {
 	if d.conf.Install.DB.Local && !d.conf.Install.DB.Exists {
 		// Determine default access for fresh install of that OS
 		// AKA databse is local and didn't exist before the install
-		creds = defaultDBCreds(d, osTar)
+		var err error
+		creds, err = defaultDBCreds(d, osTar)
+		if err != nil {
+			return err
+		}
 		d.addRedact(creds["pass"])
 	}
 	d.traceMsg(fmt.Sprintf("DB Creds are now %s / %s", creds["user"], creds["pass"]))
@@ -312,7 +327,7 @@ func prepMySQL(d *DDConfig, osTar string) error {
 		out, err := runMySQLCmd(d, dbCk)
 		if err != nil {
 			d.traceMsg("Check for existing DefectDojo MySQL database failed")
-			fmt.Println("Drop database set to true but no database found, continuing")
+			d.statusMsg("Drop database set to true but no database found, continuing")
 			//return err
 		}
 
@@ -331,6 +346,10 @@ func prepMySQL(d *DDConfig, osTar string) error {
 			return err
 		}
 		if ck == 1 {
+			err = confirmDestructive(d, "drop the existing MySQL database "+d.conf.Install.DB.Name)
+			if err != nil {
+				return err
+			}
 			d.traceMsg("DB EXISTS so droping that sucker")
 			dropDB := sqlStr{
 				os:     osTar,
@@ -344,12 +363,15 @@ func prepMySQL(d *DDConfig, osTar string) error {
 				d.traceMsg("Failed to drop existing database per configured option to drop existing")
 				return err
 			}
-			fmt.Printf("Existing database %+v dropped since Database Drop was set to %+v\n",
-				d.conf.Install.DB.Name, d.conf.Install.DB.Drop)
+			d.statusMsg(fmt.Sprintf("Existing database %+v dropped since Database Drop was set to %+v",
+				d.conf.Install.DB.Name, d.conf.Install.DB.Drop))
 		}
 
 	}
 
+	// Only a database created by this run is dropped by a rollback
+	fresh := !mysqlDBExists(d, osTar, creds)
+
 	// Create the DefectDojo database if it doesn't already exist
 	d.traceMsg("Creating database for DefectDojo on MySQL")
 	createDB := sqlStr{
@@ -364,6 +386,13 @@ func prepMySQL(d *DDConfig, osTar string) error {
 		d.traceMsg("Failed to create new database for DefectDojo to use")
 		return err
 	}
+	if fresh {
+		d.addRollback("drop the MySQL database "+d.conf.Install.DB.Name+" created by this run", func() error {
+			_, err := runMySQLCmd(d, sqlStr{os: osTar, sql: "DROP DATABASE IF EXISTS " + d.conf.Install.DB.Name + ";",
+				errMsg: "Unable to drop the MySQL database created by this run", creds: creds, kind: "try"})
+			return err
+		})
+	}
 
 	// Drop user DefectDojo uses to connect to the database
 	d.traceMsg("Dropping existing DefectDojo MySQL DB user, if any")
@@ -405,6 +434,11 @@ func prepMySQL(d *DDConfig, osTar string) error {
 		d.traceMsg("Failed to create database user for DefectDojo")
 		return err
 	}
+	d.addRollback("drop the MySQL database user "+d.conf.Install.DB.User+" created by this run", func() error {
+		_, err := runMySQLCmd(d, sqlStr{os: osTar, sql: "DROP USER IF EXISTS '" + d.conf.Install.DB.User + "'@'" + usrHost + "';",
+			errMsg: "Unable to drop the MySQL database user created by this run", creds: creds, kind: "try"})
+		return err
+	})
 
 	// Grant the DefectDojo db user the necessary privileges
 	d.traceMsg("Granting privileges to DefectDojo MySQL DB user")
@@ -448,6 +482,32 @@ func prepMySQL(d *DDConfig, osTar string) error {
 	return nil
 }
 
+// mysqlDBExists returns true if the configured DefectDojo database already
+// exists on the MySQL server, or if that can't be determined
+func mysqlDBExists(d *DDConfig, osTar string, creds map[string]string) bool {
+	if d.dryRun {
+		return true
+	}
+	out, err := runMySQLCmd(d, sqlStr{
+		os: osTar,
+		sql: "SELECT count(SCHEMA_NAME) FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = '" +
+			d.conf.Install.DB.Name + "';",
+		errMsg: "Unable to check for existing DefectDojo MySQL database",
+		creds:  creds,
+		kind:   "inspect",
+	})
+	if err != nil {
+		return true
+	}
+	ck, err := strconv.Atoi(strings.TrimSpace(strings.ReplaceAll(squishSlice(out), "count(SCHEMA_NAME)", "")))
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to parse the existing MySQL database check output %+v", squishSlice(out)))
+		return true
+	}
+
+	return ck > 0
+}
+
 func runMySQLCmd(d *DDConfig, c sqlStr) ([]string, error) {
 	out := make([]string, 1)
 	d.traceMsg(fmt.Sprintf("MySQL query: %s", c.sql))
@@ -478,8 +538,7 @@ func runMySQLCmd(d *DDConfig, c sqlStr) ([]string, error) {
 		}
 	default:
 		d.traceMsg("Invalid 'kind' sent to runMySQLCmd, bug in godojo")
-		fmt.Println("Bug discovered in godojo, see trace message or re-run with trace logging. Quitting.")
-		os.Exit(1)
+		return out, fmt.Errorf("bug in godojo, invalid kind %q sent to runMySQLCmd", c.kind)
 	}
 
 	return out, nil
@@ -506,15 +565,20 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 	if d.conf.Install.DB.Local && !d.conf.Install.DB.Exists {
 		// Determine default access for fresh install of that OS
 		// AKA databse is local and didn't exist before the install
-		creds = defaultDBCreds(d, t.id)
+		var err error
+		creds, err = defaultDBCreds(d, t.id)
+		if err != nil {
+			return err
+		}
 		d.addRedact(creds["pass"])
 	}
 	d.traceMsg(fmt.Sprintf("DB Creds are now %s / %s", creds["user"], creds["pass"]))
 
 	// Update pg_hba.conf for RHEL only (shakes fist at RHEL)
-	if !updatePgHba(d, t) {
+	err := updatePgHba(d, t)
+	if err != nil {
 		d.traceMsg("Failed to update pg_hba.conf, cannot connect to the DB. Quiting install")
-		return errors.New("Unable to update pg_hba.conf so SQL to the DB will fail.  Exiting")
+		return fmt.Errorf("Unable to update pg_hba.conf so SQL to the DB will fail: %w", err)
 	}
 
 	// Use pg_isready to check connectivity to PostgreSQL DB
@@ -557,7 +621,7 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 		out, err := runPgSQLCmd(d, dbCk)
 		if err != nil {
 			d.traceMsg("Check for existing DefectDojo PostgreSQL database failed")
-			fmt.Println("Drop database set to true but no database found, continuing")
+			d.statusMsg("Drop database set to true but no database found, continuing")
 		}
 
 		// Clean up stdout from inspectCmd output
@@ -568,6 +632,10 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 		// if ck = 0 then DB doesn't exist
 		// if ck = 1 then the DB exists already and needs to be dropped first
 		if ck == 1 {
+			err = confirmDestructive(d, "drop the existing PostgreSQL database "+d.conf.Install.DB.Name)
+			if err != nil {
+				return err
+			}
 			d.traceMsg("DB EXISTS so droping that sucker")
 			dropDB := sqlStr{
 				os:     t.id,
@@ -581,8 +649,8 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 				d.traceMsg("Failed to drop existing database per configured option to drop existing")
 				return err
 			}
-			fmt.Printf("Existing database %+v dropped since Database Drop was set to %+v\n",
-				d.conf.Install.DB.Name, d.conf.Install.DB.Drop)
+			d.statusMsg(fmt.Sprintf("Existing database %+v dropped since Database Drop was set to %+v",
+				d.conf.Install.DB.Name, d.conf.Install.DB.Drop))
 		}
 
 	}
@@ -601,6 +669,13 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 		d.traceMsg("Failed to create new database for DefectDojo to use")
 		// TODO: DEGUGGING
 		//return err
+	} else {
+		// CREATE DATABASE fails for an existing database so this one is new
+		d.addRollback("drop the PostgreSQL database "+d.conf.Install.DB.Name+" created by this run", func() error {
+			_, err := runPgSQLCmd(d, sqlStr{os: t.id, sql: "DROP DATABASE IF EXISTS " + d.conf.Install.DB.Name + ";",
+				errMsg: "Unable to drop the PostgreSQL database created by this run", creds: creds, kind: "try"})
+			return err
+		})
 	}
 
 	// Drop user DefectDojo uses to connect to the database
@@ -636,6 +711,13 @@ func prepPostgreSQL(d *DDConfig, t *targetOS) error {
 		d.traceMsg("Failed to create database user for DefectDojo")
 		return err
 	}
+	d.addRollback("drop the PostgreSQL database user "+d.conf.Install.DB.User+" created by this run", func() error {
+		// The user can't be dropped while it owns the DefectDojo database
+		_, err := runPgSQLCmd(d, sqlStr{os: t.id, sql: "ALTER DATABASE " + d.conf.Install.DB.Name + " OWNER TO CURRENT_USER; " +
+			"DROP OWNED BY " + d.conf.Install.DB.User + "; DROP USER IF EXISTS " + d.conf.Install.DB.User + ";",
+			errMsg: "Unable to drop the PostgreSQL database user created by this run", creds: creds, kind: "try"})
+		return err
+	})
 
 	// Remote DBs cannot have their pg_hba.conf modified (duh)
 	if !d.conf.Install.DB.Local {
@@ -715,32 +797,31 @@ func runPgSQLCmd(d *DDConfig, c sqlStr) ([]string, error) {
 		}
 	default:
 		d.traceMsg("Invalid 'kind' sent to runPgSQLCmd, bug in godojo")
-		fmt.Println("Bug discovered in godojo, see trace message. Quitting.")
-		os.Exit(1)
+		return out, fmt.Errorf("bug in godojo, invalid kind %q sent to runPgSQLCmd", c.kind)
 	}
 
 	return out, nil
 }
 
-func updatePgHba(d *DDConfig, t *targetOS) bool {
-	// Only RHEL and binary compatible distros (e.g. Rocky Linux) need to have pg_hba.conf modified)
-	if !strings.Contains(t.distro, "rhel") {
+// updatePgHba switches the localhost entries in pg_hba.conf to md5 on the
+// distros whose PostgreSQL packages default to ident and reloads it
+func updatePgHba(d *DDConfig, t *targetOS) error {
+	// Only RHEL, binary compatible distros (e.g. Rocky Linux), Amazon Linux, Fedora and SUSE need to have pg_hba.conf modified)
+	if !strings.Contains(t.distro, "rhel") && t.distro != "amazon" && t.distro != "fedora" && t.distro != "suse" {
 		// return early
-		return true
+		return nil
 	}
 
 	// For remote DBs, it's not possible to edit pg_hba.conf
 	if !d.conf.Install.DB.Local {
 		// return early
-		return true
+		return nil
 	}
 
-	d.traceMsg("RHEL or variant - pg_hba.conf needs to be updated.")
+	d.traceMsg("RHEL, a variant, Amazon Linux, Fedora or SUSE - pg_hba.conf needs to be updated.")
 	f, err := os.OpenFile("/var/lib/pgsql/data/pg_hba.conf", os.O_RDWR, 0600)
 	if err != nil {
-		// Exit with error code if we can't read the default creds file
-		d.errorMsg("Unable to read pg_hba.conf file, cannot continue")
-		os.Exit(1)
+		return fmt.Errorf("unable to read the pg_hba.conf file: %w", err)
 	}
 	defer f.Close()
 
@@ -765,9 +846,7 @@ func updatePgHba(d *DDConfig, t *targetOS) bool {
 	}
 
 	if err = scanner.Err(); err != nil {
-		// Exit with error code if we can't scan the default creds file
-		d.errorMsg("Unable to scan the pg_hba.conf file, exiting")
-		os.Exit(1)
+		return fmt.Errorf("unable to scan the pg_hba.conf file: %w", err)
 	}
 
 	// Truncate the file to make sure its empty before writing
@@ -776,9 +855,7 @@ func updatePgHba(d *DDConfig, t *targetOS) bool {
 	// Write new config file by starting at the begining of the file
 	_, err = f.WriteAt([]byte(content), 0)
 	if err != nil {
-		// Exit with error code if we can't scan the default creds file
-		d.errorMsg("Unable to write the pg_hba.conf file, exiting")
-		os.Exit(1)
+		return fmt.Errorf("unable to write the pg_hba.conf file: %w", err)
 	}
 	d.traceMsg("Wrote the updated config file")
 
@@ -797,12 +874,11 @@ func updatePgHba(d *DDConfig, t *targetOS) bool {
 	err = tryCmds(d, DBCmds)
 	if err != nil {
 		d.traceMsg("Unable to reload the pg_hba.conf file")
-		d.errorMsg("Unable to reload the pg_hba.conf file, exiting")
-		os.Exit(1)
+		return err
 	}
 	d.traceMsg("Restarted PostgreSQL")
 
-	return true
+	return nil
 }
 
 // TODO: REPLACE THIS WITH CLIENT CALLS
@@ -885,32 +961,34 @@ func trimRequirementsTxt(d *DDConfig, dbUsed string) error {
 
 }
 
-func defaultDBCreds(d *DDConfig, os string) map[string]string {
+func defaultDBCreds(d *DDConfig, os string) (map[string]string, error) {
 	// Setup a map to return
 	creds := map[string]string{"user": "foo", "pass": "bar"}
 
-	getDefaultDBCreds(d, creds)
+	err := getDefaultDBCreds(d, creds)
 
-	return creds
+	return creds, err
 }
 
 // Determine the default creds for a database freshly installed in Ubuntu
-func getDefaultDBCreds(d *DDConfig, creds map[string]string) {
+func getDefaultDBCreds(d *DDConfig, creds map[string]string) error {
 	// Installer currently assumes the default DB passwrod handling won't change by release
 	// Switch on the DB type
-	switch d.conf.Install.DB.Engine {
+	switch dbFamily(d) {
 	case "MySQL":
-		ubuntuDefaultMySQL(d, creds)
 		d.warnMsg("MySQL default credentials are not implemented for RHEL Linux")
+		return ubuntuDefaultMySQL(d, creds)
 	case "PostgreSQL":
 		// Set creds as the Ruser & Rpass for Postgres
 		creds["user"] = d.conf.Install.DB.Ruser
 		creds["pass"] = d.conf.Install.DB.Rpass
-		setDefaultPgSQL(d, creds)
+		return setDefaultPgSQL(d, creds)
 	}
+
+	return nil
 }
 
-func ubuntuDefaultMySQL(d *DDConfig, c map[string]string) {
+func ubuntuDefaultMySQL(d *DDConfig, c map[string]string) error {
 	// Sent some initial values that ensure the connection will fail if the file read fails
 	c["user"] = "debian-sys-maint"
 	c["pass"] = "FAIL"
@@ -918,10 +996,9 @@ func ubuntuDefaultMySQL(d *DDConfig, c map[string]string) {
 	// Pull the debian-sys-maint creds from /etc/mysql/debian.cnf
 	f, err := os.Open("/etc/mysql/debian.cnf")
 	if err != nil {
-		// Exit with error code if we can't read the default creds file
-		d.errorMsg("Unable to read file with defautl credentials, cannot continue")
-		os.Exit(1)
+		return fmt.Errorf("unable to read the file with the default MySQL credentials: %w", err)
 	}
+	defer f.Close()
 
 	// Create a new buffered reader
 	fr := bufio.NewReader(f)
@@ -940,14 +1017,13 @@ func ubuntuDefaultMySQL(d *DDConfig, c map[string]string) {
 		}
 	}
 	if err = scanner.Err(); err != nil {
-		// Exit with error code if we can't scan the default creds file
-		d.errorMsg("Unable to scan file with defautl credentials, cannot continue")
-		os.Exit(1)
+		return fmt.Errorf("unable to scan the file with the default MySQL credentials: %w", err)
 	}
 
+	return nil
 }
 
-func setDefaultPgSQL(d *DDConfig, creds map[string]string) {
+func setDefaultPgSQL(d *DDConfig, creds map[string]string) error {
 	d.traceMsg("Called setDefaultPgSQL")
 
 	// Set user to postgres as that's the default DB user for any new install
@@ -965,9 +1041,9 @@ func setDefaultPgSQL(d *DDConfig, creds map[string]string) {
 	err := tryCmds(d, pgAlter)
 	if err != nil {
 		d.traceMsg(fmt.Sprintf("Error updating PostgreSQL DB user with %+v", squishSlice(pgAlter.cmds)))
-		d.errorMsg("Unable to update default PostgreSQL DB user, quitting")
-		os.Exit(1)
+		return fmt.Errorf("unable to update the default PostgreSQL DB user: %w", err)
 	}
 
 	d.traceMsg("No error return from setDefaultPgSQL")
+	return nil
 }
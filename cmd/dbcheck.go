@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// verifyDBForDojo takes a pointer to a DDConfig struct and connects to the
+// configured database as the DefectDojo database user, returning a clear
+// error if DefectDojo won't be able to reach its database
+func verifyDBForDojo(d *DDConfig) error {
+	d.sectionMsg("Verifying DefectDojo can connect to its database")
+	engine := strings.ToLower(d.conf.Install.DB.Engine)
+	if engine == "sqlite" {
+		d.traceMsg("SQLite doesn't need a connection check")
+		return nil
+	}
+	addr := net.JoinHostPort(d.conf.Install.DB.Host, strconv.Itoa(d.conf.Install.DB.Port))
+	if d.dryRun {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would connect to the %s database %s at %s as %s",
+			d.conf.Install.DB.Engine, d.conf.Install.DB.Name, addr, d.conf.Install.DB.User))
+		return nil
+	}
+
+	err := checkDBConnection(d)
+	if err != nil {
+		return &InstallError{Kind: ErrDatabase, Err: fmt.Errorf("Unable to connect to the %s database %s at %s as %s, error was:\n    %w\n"+
+			"  Check the DB settings in the config and that the database is running and reachable",
+			d.conf.Install.DB.Engine, d.conf.Install.DB.Name, addr, d.conf.Install.DB.User, err)}
+	}
+	d.statusMsg(fmt.Sprintf("Successfully connected to the %s database %s at %s",
+		d.conf.Install.DB.Engine, d.conf.Install.DB.Name, addr))
+
+	return nil
+}
+
+// checkDBConnection opens a connection to the configured database and runs a
+// trivial query, giving up after the configured DB.ConnectTimeout
+func checkDBConnection(d *DDConfig) error {
+	timeout := time.Duration(d.conf.Install.DB.ConnectTimeout) * time.Second
+	d.traceMsg(fmt.Sprintf("Database connect timeout is %v", timeout))
+
+	switch strings.ToLower(d.conf.Install.DB.Engine) {
+	case "postgresql":
+		// Local installs don't set up TLS, for remote DBs try TLS first then fall
+		// back to a plain connection like Django's default sslmode of prefer
+		if d.conf.Install.DB.Local {
+			return queryDB(d, "postgres", pgDSN(d, "disable", timeout), timeout)
+		}
+		err := queryDB(d, "postgres", pgDSN(d, "require", timeout), timeout)
+		if errors.Is(err, pq.ErrSSLNotSupported) {
+			d.traceMsg("Database server doesn't support SSL, retrying without it")
+			return queryDB(d, "postgres", pgDSN(d, "disable", timeout), timeout)
+		}
+		return err
+	case "mysql", "mariadb":
+		cfg := mysql.NewConfig()
+		cfg.User = d.conf.Install.DB.User
+		cfg.Passwd = d.conf.Install.DB.Pass
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(d.conf.Install.DB.Host, strconv.Itoa(d.conf.Install.DB.Port))
+		cfg.DBName = d.conf.Install.DB.Name
+		cfg.Timeout = timeout
+		return queryDB(d, "mysql", cfg.FormatDSN(), timeout)
+	}
+
+	return fmt.Errorf("verifying a %s database isn't supported", d.conf.Install.DB.Engine)
+}
+
+// pgDSN returns the connection URL for the configured PostgreSQL database
+func pgDSN(d *DDConfig, sslmode string, timeout time.Duration) string {
+	q := url.Values{}
+	q.Set("sslmode", sslmode)
+	if timeout > 0 {
+		q.Set("connect_timeout", strconv.Itoa(int(timeout.Seconds())))
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.conf.Install.DB.User, d.conf.Install.DB.Pass),
+		Host:     net.JoinHostPort(d.conf.Install.DB.Host, strconv.Itoa(d.conf.Install.DB.Port)),
+		Path:     "/" + d.conf.Install.DB.Name,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
+
+// queryDB connects with the driver and data source name then runs SELECT 1
+func queryDB(d *DDConfig, driver string, dsn string, timeout time.Duration) error {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := d.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(d.ctx, timeout)
+		defer cancel()
+	}
+
+	var one int
+	err = db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	if err != nil {
+		return err
+	}
+	d.traceMsg(fmt.Sprintf("Trivial query against the %+v database returned %d", driver, one))
+
+	return nil
+}
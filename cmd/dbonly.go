@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dbOnly takes a pointer to a DDConfig struct and the arguments after the
+// db-only subcommand and re-runs just the database setup against an already
+// installed DefectDojo, e.g. after rotating database credentials or moving to
+// a new database.  The DB settings in the config are applied by preparing the
+// database and user, updating DD_DATABASE_URL in .env.prod, running the
+// Django migrations and verifying DefectDojo can connect.
+func dbOnly(d *DDConfig, args []string) {
+	fs := flag.NewFlagSet("db-only", flag.ExitOnError)
+	fs.StringVar(&d.cfPath, "config", "", "Path to the config file used for the install instead of ./dojoConfig.yml")
+	fs.BoolVar(&d.allowUnpriv, "allow-unprivileged", false, "Don't exit when godojo isn't run as root")
+	fs.BoolVar(&d.dryRun, "dry-run", false, "Print the commands that would be run instead of running them")
+	fs.BoolVar(&d.yes, "yes", false, "Don't prompt for confirmation before dropping an existing database")
+	fs.StringVar(&d.logFile, "log-file", "", "Also write all log messages and command output to this file")
+	fs.Usage = printDBOnlyHelp
+	_ = fs.Parse(args)
+	err := openLogFile(d)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(d.cfPath) > 0 {
+		err = checkConfigPath(d.cfPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	// Read and check the same config used for the install
+	readConfigFile(d)
+	if err := readEnvVars(&d.conf); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	d.initRedact()
+	checkUserPrivs(d, "to configure the DefectDojo database")
+	err = saneDBConfig(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+	err = validateConfig(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("The configuration has the following problems:\n%v\n"+
+			"  Please correct the configuration and run godojo db-only again", err))
+		os.Exit(1)
+	}
+	err = loadDistroCmds(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+	setDBEngine(d)
+	d.cmdLogger, err = setCmdLogging(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+
+	// Only the DB is changed so DefectDojo must already be installed
+	srcPath := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+	if _, err := os.Stat(filepath.Join(srcPath, "manage.py")); err != nil {
+		d.errorMsg(fmt.Sprintf("No DefectDojo install found at %s, run godojo to install DefectDojo first", srcPath))
+		os.Exit(1)
+	}
+	osTarget, err := checkOS(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		d.exit(exitCode(err))
+	}
+
+	err = setupDB(d, &osTarget)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		d.exit(exitCode(err))
+	}
+	updateEnvDBURL(d)
+	err = migrateDB(d)
+	if err == nil {
+		err = verifyDBForDojo(d)
+	}
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		d.exit(exitCode(err))
+	}
+
+	d.statusMsg(fmt.Sprintf("Successfully configured the %s database %s for DefectDojo", d.conf.Install.DB.Engine, d.conf.Install.DB.Name))
+}
+
+// updateEnvDBURL replaces DD_DATABASE_URL in DefectDojo's .env.prod with the
+// configured database, leaving the rest of the file as is so keys like
+// DD_SECRET_KEY aren't regenerated
+func updateEnvDBURL(d *DDConfig) {
+	p := filepath.Join(d.conf.Install.Root, d.conf.Install.Source, "dojo", "settings", ".env.prod")
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would update DD_DATABASE_URL in " + p)
+		return
+	}
+
+	d.traceMsg(fmt.Sprintf("Updating DD_DATABASE_URL in %+v", p))
+	err := setEnvProd(p, "DD_DATABASE_URL", databaseURL(d))
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("Unable to write the database settings to %s, error was: %+v", p, err))
+		os.Exit(1)
+	}
+	d.statusMsg("Updated the database settings in " + p)
+}
+
+// migrateDB runs the Django migrations so a new or restored database has the
+// schema the installed DefectDojo needs
+func migrateDB(d *DDConfig) error {
+	d.sectionMsg("Running the DefectDojo database migrations")
+	return sendCmd(d, d.cmdLogger, "cd "+filepath.Join(d.conf.Install.Root, d.conf.Install.Source)+
+		" && source "+filepath.Join(venvPath(d), "bin", "activate")+" && python3 manage.py migrate --noinput",
+		"Unable to run the DefectDojo database migrations", true)
+}
+
+// printDBOnlyHelp prints the help for the db-only subcommand to stdout
+func printDBOnlyHelp() {
+	fmt.Println("")
+	fmt.Println("Usage of godojo db-only")
+	fmt.Println("")
+	fmt.Println("./godojo db-only [optional arguments]")
+	fmt.Println("")
+	fmt.Println("  Re-runs only the database setup for an existing DefectDojo install using the dojoConfig.yml")
+	fmt.Println("  in the current working directory, e.g. after rotating database credentials.  The database and")
+	fmt.Println("  database user are prepared, DD_DATABASE_URL in .env.prod is updated, the Django migrations are")
+	fmt.Println("  run and the connection is verified.  The OS and DefectDojo source aren't changed")
+	fmt.Println("  -allow-unprivileged")
+	fmt.Println("        OPTIONAL - Don't exit when godojo isn't run as root or with sudo")
+	fmt.Println("  -config=/path/to/dojoConfig.yml")
+	fmt.Println("        OPTIONAL - Use the config file at the path provided instead of ./dojoConfig.yml")
+	fmt.Println("  -dry-run")
+	fmt.Println("        OPTIONAL - Print the commands that would be run without running them")
+	fmt.Println("  -log-file=/path/to/godojo.log")
+	fmt.Println("        OPTIONAL - Also write every log message and all OS command output to the file provided")
+	fmt.Println("  -yes")
+	fmt.Println("        OPTIONAL - Don't prompt for confirmation before dropping an existing database with DB.Drop")
+	fmt.Println("")
+}
@@ -1,57 +1,120 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"log/syslog"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/briandowns/spinner"
-	c "github.com/mtesauro/commandeer"
+	"github.com/defectdojo/godojo/distros"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/viper"
 )
 
 // godojo default value struct
 type DDConfig struct {
-	ver         string           // Holds the version of godojo
-	cf          string           // Name of the config file
-	conf        dojoConfig       // Global config struct
-	sensStr     []string         // Holds sensitive strings to redact
-	logLocation string           // Where the logs are written, relative to the directory godojo is called in
-	Trace       *log.Logger      // Logger for trace logs
-	Info        *log.Logger      // Logger for info logs
-	Warning     *log.Logger      // Logger for warning logs
-	Error       *log.Logger      // Logger for error logs
-	cmdLogger   *log.Logger      // File pointer to the file in logLocation where command output is written
-	helpURL     string           // Location of the godojo help URL
-	releaseURL  string           // Location to download DefectDojo releases
-	cloneURL    string           // URL to git clone DefectDojo
-	yarnGPG     string           // URL to the yarn GPG key
-	yarnRepo    string           // URL for the yarn repo
-	nodeURL     string           // URL for the node repo
-	quiet       bool             // Runtime flag to suppress output
-	traceOn     bool             // Runtime flag to turn on trace logging
-	redact      bool             // Runtime flag to redact sensitive info (defaults to on)
-	spin        *spinner.Spinner // Progress spinner
-	defInstall  bool             // Holds command-line bool asking for a default install
-	emdir       string
-	otdir       string
-	bdir        string
-	modf        string
-	tgzf        string
+	ver            string          // Holds the version of godojo
+	cf             string          // Name of the config file
+	cfPath         string          // Path to an alternate config file set with -config, "" uses cf in the working directory
+	conf           Config          // Global config struct
+	viper          *viper.Viper    // Config file as read by loadConfig, nil until it's read
+	sensStr        []string        // Holds sensitive strings to redact
+	logLocation    string          // Where the logs are written, relative to the directory godojo is called in
+	Trace          *log.Logger     // Logger for trace logs
+	Info           *log.Logger     // Logger for info logs
+	Warning        *log.Logger     // Logger for warning logs
+	Error          *log.Logger     // Logger for error logs
+	logger         Logger          // Receives every message instead of stdout and the log files when godojo is used as a library
+	cmdLogger      *log.Logger     // File pointer to the file in logLocation where command output is written
+	logFormat      string          // Format of the log file entries, either text or json
+	logFile        string          // Path set with -log-file to also write all log messages and command output to
+	teeLog         *log.Logger     // Logger for the -log-file, nil if it isn't set
+	traceSyslog    bool            // Runtime flag to also send trace messages to syslog
+	syslogAll      bool            // Runtime flag to send every log level to syslog, not just trace
+	syslogFacility string          // Syslog facility used with -trace-to-syslog
+	syslogTag      string          // Syslog tag used with -trace-to-syslog
+	sysLog         *syslog.Writer  // Connection to syslog, nil if -trace-to-syslog isn't set or syslog isn't available
+	helpURL        string          // Location of the godojo help URL
+	releaseURL     string          // Location to download DefectDojo releases
+	assetURL       string          // Release download URL looked up with the Github releases API, "" until it is
+	cloneURL       string          // URL to git clone DefectDojo
+	yarnGPG        string          // URL to the yarn GPG key
+	yarnRepo       string          // URL for the yarn repo
+	nodeURL        string          // URL for the node repo
+	quiet          bool            // Runtime flag to suppress output
+	traceOn        bool            // Runtime flag to turn on trace logging
+	verbosity      int             // Runtime flag level set with -verbose, 1 shows verbose messages and 2 also trace messages
+	redact         bool            // Runtime flag to redact sensitive info (defaults to on)
+	traceRedact    bool            // Runtime flag to redact sensitive info even if Install.Redact is false
+	dryRun         bool            // Runtime flag to print commands and downloads instead of running them
+	plain          bool            // Runtime flag to replace the progress spinner with plain status lines
+	noColor        bool            // Runtime flag to turn off ANSI colors in the console output and spinner
+	forceExtract   bool            // Runtime flag to extract the release tarball even if it was already extracted
+	allowUnpriv    bool            // Runtime flag to skip the root check, e.g. in containers that are already root-equivalent
+	restart        bool            // Runtime flag to run every install phase, even those completed by an earlier run
+	skipBootstrap  bool            // Runtime flag to skip the bootstrap phase for hosts with the OS dependencies already installed
+	skipMirror     bool            // Runtime flag to skip timing the package mirror before the OS package commands
+	yes            bool            // Runtime flag to skip confirming destructive steps, e.g. for automation
+	upgrade        bool            // Runtime flag to replace an existing install of a different DefectDojo version
+	noRollback     bool            // Runtime flag to leave the changes made by a failed install in place
+	phase          string          // Install phase currently running, "" outside of a phase
+	phaseStart     time.Time       // When the running phase started
+	phaseResults   []phaseResult   // How each phase reached went, for the -report and -metrics
+	report         string          // Path set with -report to write a JSON summary of the run to, "" writes none
+	metrics        string          // Path set with -metrics to write the phase timings to as CSV or JSON, "" writes none
+	reportOnce     sync.Once       // Makes sure only the first exit writes the -report and -metrics
+	firstErr       string          // First error message of the run, for the -report
+	target         targetOS        // Target OS once checkOS has determined it
+	commit         string          // Commit a source install checked out, for the -report
+	rollbacks      []rollbackStep  // Undo the changes made by this run's phases if the install fails
+	stopped        []string        // systemd units stopped for an -upgrade, started again once it's in place
+	offline        bool            // Runtime flag to fail any HTTP or git network call godojo would make
+	insecure       bool            // Runtime flag to skip TLS certificate verification for downloads and clones
+	phases         []string        // Install phases selected with -phase in the order they run, nil runs every phase
+	spin           *progress       // Progress spinner
+	ctx            context.Context // Cancelled when the install is interrupted
+	partial        string          // File or directory being written, removed if the install is stopped
+	stopping       bool            // Set once the install is being stopped by an interrupt or -timeout-overall
+	mu             sync.Mutex      // Guards partial and stopping
+	cancel         func()          // Cancels ctx to stop the install
+	start          time.Time       // When godojo started, -timeout-overall counts from here
+	timeoutOverall time.Duration   // Runtime flag capping how long the whole install can run, 0 means no limit
+	defInstall     bool            // Holds command-line bool asking for a default install
+	emdir          string
+	otdir          string
+	bdir           string
+	modf           string
+	tgzf           string
+	extractState   string // Name of the file in Install.Root recording the checksum of the last extracted tarball
+	phaseState     string // Name of the file in Install.Root recording the install phases completed so far
+	accountState   string // Name of the file in Install.Root recording the OS user and group godojo created or found
+	sourceManifest string // Name of the file in Install.Root recording the commit checked out for a source install
+	depManifest    string // Name of the file in Install.Root recording the installed versions of the key dependencies
 }
 
 // Set the godojo defaults in the DDConfig struct
 func (d *DDConfig) setGodojoDefaults() {
 	d.ver = "1.2.4"
+	if len(buildVersion) > 0 {
+		d.ver = buildVersion
+	}
 	d.cf = "dojoConfig.yml"
 
-	// Setup default logging
+	// Setup default logging, a library Logger replaces the log files
 	d.logLocation = "logs"
-	logHandler := d.prepLogging()
+	d.logFormat = "text"
+	var logHandler io.Writer = io.Discard
+	if d.logger == nil {
+		logHandler = d.prepLogging()
+	}
 	d.Trace = log.New(logHandler, "TRACE:   ", log.Ldate|log.Ltime)
 	d.Info = log.New(logHandler, "INFO:    ", log.Ldate|log.Ltime)
 	d.Warning = log.New(logHandler, "WARNING: ", log.Ldate|log.Ltime)
@@ -59,20 +122,48 @@ func (d *DDConfig) setGodojoDefaults() {
 
 	// Set some installer defaults - .deb specific
 	d.helpURL = "https://github.com/DefectDojo/godojo"
-	d.releaseURL = "https://github.com/DefectDojo/django-DefectDojo/archive/"
+	d.releaseURL = defaultReleaseURL
 	d.cloneURL = "https://github.com/DefectDojo/django-DefectDojo.git"
 	d.yarnGPG = "https://dl.yarnpkg.com/debian/pubkey.gpg"
 	d.yarnRepo = "deb https://dl.yarnpkg.com/debian/ stable main"
 	d.nodeURL = "https://deb.nodesource.com/setup_18.x"
 	d.quiet = false
 	d.traceOn = true
+	d.verbosity = 0
 	d.redact = true
+	d.traceRedact = false
+	d.dryRun = false
+	d.plain = !isatty.IsTerminal(os.Stdout.Fd())
+	d.noColor = colorDisabled()
+	d.forceExtract = false
+	d.allowUnpriv = false
+	d.restart = false
+	d.skipBootstrap = false
+	d.skipMirror = false
+	d.yes = false
+	d.upgrade = false
+	d.noRollback = false
+	d.offline = false
+	d.report = ""
+	d.metrics = ""
+	d.insecure = false
+	d.syslogFacility = "user"
+	d.syslogTag = "godojo"
+	d.ctx = context.Background()
+	d.cancel = func() {}
+	d.start = time.Now()
+	d.timeoutOverall = 0
 	d.defInstall = false
 	d.emdir = "embd/"
 	d.otdir = "/tmp/.dojo-temp/"
 	d.bdir = "/opt/"
 	d.modf = ".dd.mod"
 	d.tgzf = "gdj.tar.gz"
+	d.extractState = ".godojo-extracted"
+	d.phaseState = ".godojo-phases"
+	d.accountState = ".godojo-accounts"
+	d.sourceManifest = "godojo-source-manifest.json"
+	d.depManifest = "godojo-dependency-manifest.json"
 
 	// Set the normal Python3 path
 	d.conf.Options.PyPath = "/usr/bin/python3"
@@ -127,59 +218,140 @@ func (gd *DDConfig) prepLogging() io.Writer {
 
 }
 
+// logEvent is a single log entry when the log format is json
+type logEvent struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+// emit writes the already redacted string s to the log file using logger l and
+// to syslog when -trace-to-syslog is set.
+// For the default text format the logger's prefix and timestamp are used, for
+// json each entry is written as a single JSON object on its own line
+func (gd *DDConfig) emit(l *log.Logger, level string, s string) {
+	gd.toSyslog(level, s)
+	if gd.logFormat != "json" {
+		switch level {
+		case "section":
+			s = "SECTION: " + s
+		case "verbose":
+			s = "VERBOSE: " + s
+		}
+		l.Println(s)
+		if gd.teeLog != nil {
+			gd.teeLog.Println(l.Prefix() + s)
+		}
+		return
+	}
+
+	b, err := json.Marshal(logEvent{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Level:     level,
+		Message:   s,
+	})
+	if err != nil {
+		l.Println(s)
+		return
+	}
+	fmt.Fprintf(gd.teeWriter(l.Writer()), "%s\n", b)
+}
+
 // Output a section message and log the same string
 func (gd *DDConfig) sectionMsg(s string) {
+	if gd.logger != nil {
+		gd.logger.Info(gd.redactatron(s, gd.redact))
+		return
+	}
 	// Pring status message if quiet isn't set
 	if !gd.quiet {
 		fmt.Println("")
-		fmt.Println("==============================================================================")
-		fmt.Printf("  %s\n", s)
-		fmt.Println("==============================================================================")
+		fmt.Println(gd.paint(colorSection, "=============================================================================="))
+		fmt.Printf("  %s\n", gd.paint(colorSection, gd.redactatron(s, gd.redact)))
+		fmt.Println(gd.paint(colorSection, "=============================================================================="))
 		fmt.Println("")
 	}
-	gd.Info.Println("SECTION: " + s)
+	gd.emit(gd.Info, "section", gd.redactatron(s, gd.redact))
 }
 
 // Output a status message and log the same string
 func (gd *DDConfig) statusMsg(s string) {
+	if gd.logger != nil {
+		gd.logger.Info(gd.redactatron(s, gd.redact))
+		return
+	}
 	// Pring status message if quiet isn't set & redact sensitive info in redact is true
 	if !gd.quiet {
-		fmt.Printf("%s\n", gd.redactatron(s, gd.redact))
+		fmt.Printf("%s\n", gd.paint(colorStatus, gd.redactatron(s, gd.redact)))
 	}
-	gd.Info.Println(gd.redactatron(s, gd.redact))
+	gd.emit(gd.Info, "status", gd.redactatron(s, gd.redact))
 }
 
 // Output a blatant error message and log the string to the error log
 func (gd *DDConfig) warnMsg(s string) {
+	if gd.logger != nil {
+		gd.logger.Warn(gd.redactatron(s, gd.redact))
+		return
+	}
 	// Pring status message if quiet isn't set & redact sensitive info in redact is true
 	if !gd.quiet {
 		fmt.Println("")
-		fmt.Println("##############################################################################")
-		fmt.Printf("  WARNING: %s\n", gd.redactatron(s, gd.redact))
-		fmt.Println("##############################################################################")
+		fmt.Println(gd.paint(colorWarn, "##############################################################################"))
+		fmt.Printf("  %s\n", gd.paint(colorWarn, "WARNING: "+gd.redactatron(s, gd.redact)))
+		fmt.Println(gd.paint(colorWarn, "##############################################################################"))
 		fmt.Println("")
 	}
-	gd.Warning.Println(gd.redactatron(s, gd.redact))
+	gd.emit(gd.Warning, "warning", gd.redactatron(s, gd.redact))
 }
 
 // Output a blatant error message and log the string to the error log
 func (gd *DDConfig) errorMsg(s string) {
+	if gd.logger != nil {
+		gd.logger.Error(gd.redactatron(s, gd.redact))
+		return
+	}
 	// Pring status message if quiet isn't set & redact sensitive info in redact is true
 	if !gd.quiet {
 		fmt.Println("")
-		fmt.Println("##############################################################################")
-		fmt.Printf("  ERROR: %s\n", gd.redactatron(s, gd.redact))
-		fmt.Println("##############################################################################")
+		fmt.Println(gd.paint(colorError, "##############################################################################"))
+		fmt.Printf("  %s\n", gd.paint(colorError, "ERROR: "+gd.redactatron(s, gd.redact)))
+		fmt.Println(gd.paint(colorError, "##############################################################################"))
 		fmt.Println("")
 	}
-	gd.Error.Println(gd.redactatron(s, gd.redact))
+	gd.emit(gd.Error, "error", gd.redactatron(s, gd.redact))
+	gd.mu.Lock()
+	if len(gd.firstErr) == 0 {
+		gd.firstErr = gd.redactatron(s, true)
+	}
+	gd.mu.Unlock()
+}
+
+// Output a verbose message if -verbose is set and always log the string
+func (gd *DDConfig) verboseMsg(s string) {
+	if gd.logger != nil {
+		gd.logger.Trace(gd.redactatron(s, gd.redact))
+		return
+	}
+	// Print verbose message if -verbose is set and quiet isn't
+	if !gd.quiet && gd.verbosity >= 1 {
+		fmt.Printf("%s\n", gd.redactatron(s, gd.redact))
+	}
+	gd.emit(gd.Info, "verbose", gd.redactatron(s, gd.redact))
 }
 
 // Log the string as an trace log
 func (gd *DDConfig) traceMsg(s string) {
 	// Pring status message if quiet isn't set & redact sensitive info in redact is true
+	if gd.traceOn && gd.logger != nil {
+		gd.logger.Trace(gd.redactatron(s, gd.redact))
+		return
+	}
+	// Trace messages are only printed at -verbose level 2, e.g. -vv
+	if !gd.quiet && gd.verbosity >= 2 {
+		fmt.Printf("TRACE: %s\n", gd.redactatron(s, gd.redact))
+	}
 	if gd.traceOn {
-		gd.Trace.Println(gd.redactatron(s, gd.redact))
+		gd.emit(gd.Trace, "trace", gd.redactatron(s, gd.redact))
 	}
 }
 
@@ -208,7 +380,11 @@ func (gd *DDConfig) getReplacements() map[string]string {
 	iv["{yarnRepo}"] = gd.conf.Options.YarnRepo                    // Yarn's package URL
 	iv["{nodeURL}"] = gd.conf.Options.NodeURL                      // Node's URL
 	iv["{PyPath}"] = gd.conf.Options.PyPath                        // Path to Python binary to use for virtualenv
+	iv["{PipSpec}"] = pinSpec("pip", gd.conf.Install.PipVersion)   // pip requirement, pinned if PipVersion is set
+	iv["{VirtualenvEnv}"] = virtualenvEnv(gd)                      // Env prefix pointing Python at a pinned virtualenv, if any
 	iv["{conf.Install.Root}"] = gd.conf.Install.Root               // Path where DefectDojo is installed defaults to /opt/dojo
+	iv["{VenvPath}"] = venvPath(gd)                                // Path of DefectDojo's virtualenv, defaults to Install.Root
+	iv["{Requirements}"] = requirementsFile(gd)                    // Python requirements file installed into the virtualenv
 	iv["{conf.Install.OS.Group}"] = gd.conf.Install.OS.Group       // OS group used by DefectDojo application
 	iv["{conf.Install.OS.User}"] = gd.conf.Install.OS.User         // OS user used by DefectDojo application
 	iv["{conf.Install.Admin.User}"] = gd.conf.Install.Admin.User   // Admin user used by DefectDojo web UI
@@ -218,7 +394,7 @@ func (gd *DDConfig) getReplacements() map[string]string {
 	return iv
 }
 
-func (gd *DDConfig) injectConfigVals(cmds []c.SingleCmd) {
+func (gd *DDConfig) injectConfigVals(cmds []distros.Cmd) {
 	// Get replacement values
 	confVal := gd.getReplacements()
 
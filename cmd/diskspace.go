@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// checkDiskSpace takes a pointer to a DDConfig struct, a directory, the bytes
+// still to be downloaded and the size of the release tarball and returns an
+// error if the filesystem holding dir doesn't have room for the download plus
+// the tarball extracted.  The extracted size is estimated as the tarball size
+// times ExtractMultiplier since compression ratios vary between releases.
+func checkDiskSpace(d *DDConfig, dir string, download int64, tarball int64) error {
+	if tarball <= 0 {
+		d.traceMsg("Size of the release tarball is unknown, skipping the disk space check")
+		return nil
+	}
+
+	avail, err := availableSpace(dir)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to check available disk space for %+v, error was: %+v", dir, err))
+		return nil
+	}
+
+	mult := d.conf.Install.ExtractMultiplier
+	need := download + int64(float64(tarball)*mult)
+	d.traceMsg(fmt.Sprintf("Disk space for %+v: %s available, %s required", dir, humanBytes(avail), humanBytes(need)))
+	if avail < need {
+		return fmt.Errorf("not enough disk space for %s, %s available but %s required "+
+			"(%s to download plus %.1fx the %s tarball to extract it), free up space or adjust ExtractMultiplier",
+			dir, humanBytes(avail), humanBytes(need), humanBytes(download), mult, humanBytes(tarball))
+	}
+
+	return nil
+}
+
+// availableSpace returns the bytes available to unprivileged users on the
+// filesystem holding dir
+func availableSpace(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	err := syscall.Statfs(dir, &st)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
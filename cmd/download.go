@@ -0,0 +1,594 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+const (
+	defaultDownloadAttempts = 3                // Attempts made to download a release when DownloadAttempts isn't set
+	defaultDownloadDelay    = 2                // Seconds to wait before the first retry when DownloadDelay isn't set
+	headTimeout             = 15 * time.Second // Timeout for the HEAD request made before downloading a release
+	maxRateLimitWait        = 5 * time.Minute  // Longest godojo waits for a Github rate limit to reset before giving up
+
+	defaultReleaseURL = "https://github.com/DefectDojo/django-DefectDojo/archive/" // DefectDojo's Github archive, it doesn't publish .sha256 files
+)
+
+// headRelease sends a quick HEAD request for the release at u so a wrong URL
+// or version fails fast instead of waiting on the full download.  It returns
+// the release size, -1 if the server doesn't report it, and an error if the
+// host can't be reached or the release doesn't exist.  Servers that don't
+// support HEAD or return a 5xx are left for the download and its retries.
+func headRelease(d *DDConfig, u string) (int64, error) {
+	cl, err := newHTTPClient(d, headTimeout)
+	if err != nil {
+		return -1, err
+	}
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return -1, err
+	}
+	err = requestAuth(d, req)
+	if err != nil {
+		return -1, err
+	}
+
+	d.verboseMsg(fmt.Sprintf("Checking the release exists with a HEAD request to %+v", u))
+	resp, err := cl.Do(req)
+	if err != nil {
+		return -1, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("unable to reach %s: %w", u, err)}
+	}
+	resp.Body.Close()
+	d.traceMsg(fmt.Sprintf("Status of the HEAD request was %+v with a Content-Length of %d", resp.Status, resp.ContentLength))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if resp.ContentLength <= 0 {
+			d.traceMsg("Server didn't report the size of the release")
+			return -1, nil
+		}
+		return resp.ContentLength, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return -1, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("release not found at %s (%s), check Version and ReleaseURL", u, resp.Status)}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return -1, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("release at %s needs credentials (%s), check ReleaseUser and ReleasePass or ReleasePassEnv", u, resp.Status)}
+	case rateLimited(resp):
+		d.traceMsg("HEAD request was rate limited, leaving the wait and retry to the download")
+		return -1, nil
+	case resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode >= 500:
+		d.traceMsg("Skipping the HEAD check, the download will find out if the release exists")
+		return -1, nil
+	}
+
+	return -1, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("unable to download %s, status was %s", u, resp.Status)}
+}
+
+// downloadRelease takes a pointer to a DDConfig struct, an http client, a URL
+// and an offset and GETs that URL, retrying with exponential backoff on network
+// errors and 5xx responses.  Rate limited responses are retried once the
+// Retry-After or rate limit reset time has passed.  Any other non-200 response, like a 404 for a
+// release that doesn't exist, is returned as an error without retrying.  An
+// offset greater than 0 requests the rest of the file from that byte with a
+// Range header, the caller must check for a 206 response before appending as
+// servers that ignore Range return 200 and the whole file.  On success the
+// caller is responsible for closing the response body.
+func downloadRelease(d *DDConfig, cl *http.Client, u string, offset int64) (*http.Response, error) {
+	attempts, delay := retrySettings(d)
+	d.verboseMsg(fmt.Sprintf("Release download will be attempted up to %d times with a base delay of %v", attempts, delay))
+
+	var lastErr error
+	for i := 1; i <= attempts; i++ {
+		if d.spin != nil {
+			d.spin.setPrefix(fmt.Sprintf("Downloading release (attempt %d of %d)...", i, attempts))
+		}
+
+		d.verboseMsg(fmt.Sprintf("Download attempt %d of %d for %+v", i, attempts, u))
+		resp, err := getRange(d, cl, u, offset)
+		wait := delay
+		switch {
+		case err != nil:
+			d.traceMsg(fmt.Sprintf("Error downloading was: %+v", err))
+			lastErr = err
+		case resp.StatusCode == http.StatusOK:
+			d.traceMsg(fmt.Sprintf("Status of http.Client response was %+v", resp.Status))
+			return resp, nil
+		case resp.StatusCode == http.StatusPartialContent && offset > 0:
+			d.verboseMsg(fmt.Sprintf("Server returned %+v, resuming at byte %d", resp.Status, offset))
+			return resp, nil
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+			// The partial file is no good for this release, download all of it
+			d.verboseMsg("Server couldn't satisfy the Range request, downloading the full release")
+			resp.Body.Close()
+			return downloadRelease(d, cl, u, 0)
+		case rateLimited(resp):
+			resp.Body.Close()
+			wait, err = rateLimitWait(d, resp, u, delay)
+			if err != nil {
+				return nil, &InstallError{Kind: ErrDownloadFailed, Err: err}
+			}
+			lastErr = fmt.Errorf("rate limited requesting %s, status was %s", u, resp.Status)
+		case resp.StatusCode >= 500:
+			d.verboseMsg(fmt.Sprintf("Server error downloading release, status was %+v", resp.Status))
+			resp.Body.Close()
+			lastErr = fmt.Errorf("server returned %s for %s", resp.Status, u)
+		default:
+			// Client errors like 404 won't be fixed by retrying
+			resp.Body.Close()
+			return nil, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("unable to download %s, status was %s", u, resp.Status)}
+		}
+
+		if d.ctx.Err() != nil {
+			d.waitIfStopping()
+			return nil, fmt.Errorf("download of %s cancelled: %w", u, d.ctx.Err())
+		}
+		if i < attempts {
+			d.verboseMsg(fmt.Sprintf("Waiting %v before retrying the download", wait))
+			select {
+			case <-time.After(wait):
+			case <-d.ctx.Done():
+				d.waitIfStopping()
+				return nil, fmt.Errorf("download of %s cancelled: %w", u, d.ctx.Err())
+			}
+			delay *= 2
+		}
+	}
+
+	return nil, &InstallError{Kind: ErrDownloadFailed, Err: fmt.Errorf("download of %s failed after %d attempts: %w", u, attempts, lastErr)}
+}
+
+// retrySettings returns the number of attempts and the delay before the first
+// retry from DownloadAttempts and DownloadDelay, which are shared by release
+// downloads and git clones and fetches
+func retrySettings(d *DDConfig) (int, time.Duration) {
+	attempts := d.conf.Install.DownloadAttempts
+	if attempts < 1 {
+		attempts = defaultDownloadAttempts
+	}
+	delay := time.Duration(d.conf.Install.DownloadDelay) * time.Second
+	if delay <= 0 {
+		delay = defaultDownloadDelay * time.Second
+	}
+
+	return attempts, delay
+}
+
+// getWithContext GETs the URL u with the provided http client, stopping the
+// request if the install is interrupted
+func getWithContext(d *DDConfig, cl *http.Client, u string) (*http.Response, error) {
+	return getRange(d, cl, u, 0)
+}
+
+// getRange GETs the URL u like getWithContext, asking for the content from
+// offset onward with a Range header when offset is greater than 0
+func getRange(d *DDConfig, cl *http.Client, u string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	err = requestAuth(d, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return cl.Do(req)
+}
+
+// requestAuth adds the credentials for the host of req, the ReleaseUser basic
+// auth for the ReleaseURL host or the Github token for github.com
+func requestAuth(d *DDConfig, req *http.Request) error {
+	ok, err := releaseAuth(d, req)
+	if err != nil || ok {
+		return err
+	}
+
+	return githubAuth(d, req)
+}
+
+// releaseAuth adds the ReleaseUser basic auth to req when it's set and req is
+// for the ReleaseURL host, so the release, its checksum and signature all use
+// it while the credentials are never sent anywhere else.  It returns true if
+// the credentials were added.
+func releaseAuth(d *DDConfig, req *http.Request) (bool, error) {
+	if len(d.conf.Install.ReleaseUser) == 0 {
+		return false, nil
+	}
+	ru, err := url.Parse(d.releaseURL)
+	if err != nil || !strings.EqualFold(ru.Host, req.URL.Host) {
+		return false, nil
+	}
+	pass := d.conf.Install.ReleasePass
+	if len(d.conf.Install.ReleasePassEnv) > 0 {
+		pass = os.Getenv(d.conf.Install.ReleasePassEnv)
+		if len(pass) == 0 {
+			return false, fmt.Errorf("ReleasePassEnv is set but the %s env variable is empty", d.conf.Install.ReleasePassEnv)
+		}
+	}
+	d.addRedact(pass)
+	req.SetBasicAuth(d.conf.Install.ReleaseUser, pass)
+
+	return true, nil
+}
+
+// githubToken returns the token set in GithubToken, or read from the env
+// variable named in GithubTokenEnv, used to raise the Github rate limit.  ""
+// is returned if neither is set.
+func githubToken(d *DDConfig) (string, error) {
+	token := d.conf.Install.GithubToken
+	if len(d.conf.Install.GithubTokenEnv) > 0 {
+		token = os.Getenv(d.conf.Install.GithubTokenEnv)
+		if len(token) == 0 {
+			return "", fmt.Errorf("GithubTokenEnv is set but the %s env variable is empty", d.conf.Install.GithubTokenEnv)
+		}
+	}
+	d.addRedact(token)
+
+	return token, nil
+}
+
+// githubAuth adds an Authorization header with the configured Github token,
+// if any, to req when it's for github.com or one of its subdomains so the
+// token is never sent to a mirror or proxy set in ReleaseURL
+func githubAuth(d *DDConfig, req *http.Request) error {
+	h := strings.ToLower(req.URL.Hostname())
+	if h != "github.com" && !strings.HasSuffix(h, ".github.com") {
+		return nil
+	}
+	token, err := githubToken(d)
+	if err != nil || len(token) == 0 {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// rateLimited returns true if resp is a 429, or a 403 with no requests left
+// in the rate limit which is how Github reports an exceeded API rate limit
+func rateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// rateLimitDelay returns how long to wait before retrying the rate limited
+// response resp from its Retry-After header, as seconds or an HTTP date, or
+// the X-RateLimit-Reset time in Unix seconds.  def is returned if neither is set.
+func rateLimitDelay(resp *http.Response, now time.Time, def time.Duration) time.Duration {
+	if ra := strings.TrimSpace(resp.Header.Get("Retry-After")); len(ra) > 0 {
+		if s, err := strconv.Atoi(ra); err == nil && s >= 0 {
+			return time.Duration(s) * time.Second
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			if t.Before(now) {
+				return 0
+			}
+			return t.Sub(now)
+		}
+	}
+	if rs := resp.Header.Get("X-RateLimit-Reset"); len(rs) > 0 {
+		if s, err := strconv.ParseInt(rs, 10, 64); err == nil {
+			t := time.Unix(s, 0)
+			if t.Before(now) {
+				return 0
+			}
+			return t.Sub(now)
+		}
+	}
+
+	return def
+}
+
+// rateLimitWait logs that the request for u was rate limited and returns how
+// long to wait before retrying it, def if the server didn't say.  An error is
+// returned if the wait is longer than maxRateLimitWait.
+func rateLimitWait(d *DDConfig, resp *http.Response, u string, def time.Duration) (time.Duration, error) {
+	wait := rateLimitDelay(resp, time.Now(), def)
+	hint := ""
+	if tk, _ := githubToken(d); len(tk) == 0 {
+		hint = ", set GithubToken or GithubTokenEnv to raise the Github rate limit"
+	}
+	if wait > maxRateLimitWait {
+		return 0, fmt.Errorf("rate limited requesting %s (%s) for the next %v, longer than godojo waits%s",
+			u, resp.Status, wait.Round(time.Second), hint)
+	}
+	d.warnMsg(fmt.Sprintf("Rate limited requesting %s (%s), retrying in %v%s", u, resp.Status, wait.Round(time.Second), hint))
+
+	return wait, nil
+}
+
+// requireOnline returns an error if -offline is set so the network call
+// described by what fails before any connection is attempted
+func requireOnline(d *DDConfig, what string) error {
+	if d.offline {
+		d.traceMsg(fmt.Sprintf("-offline set, refusing to %+v", what))
+		return fmt.Errorf("-offline is set so godojo won't %s", what)
+	}
+
+	return nil
+}
+
+// newHTTPClient returns an http client with the provided timeout that uses the
+// configured proxy, if any, for release downloads.  No client is returned with
+// -offline so every HTTP request godojo makes fails up front.
+func newHTTPClient(d *DDConfig, timeout time.Duration) (*http.Client, error) {
+	err := requireOnline(d, "make HTTP requests")
+	if err != nil {
+		return nil, err
+	}
+	pf, err := proxyFunc(d)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := tlsConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = pf
+	tr.TLSClientConfig = tc
+
+	return &http.Client{Timeout: timeout, Transport: tr}, nil
+}
+
+// setGitProxy installs an http client using the configured proxy, if any, as
+// the transport go-git uses for http and https clones
+func setGitProxy(d *DDConfig) error {
+	cl, err := newHTTPClient(d, 0)
+	if err != nil {
+		return err
+	}
+	client.InstallProtocol("http", githttp.NewClient(cl))
+	client.InstallProtocol("https", githttp.NewClient(cl))
+
+	return nil
+}
+
+// tlsConfig returns the TLS config for downloads and clones which trusts the
+// system CAs plus any in CABundle, or skips verification entirely with
+// -insecure-skip-verify.  nil is returned to use Go's defaults when neither is set.
+func tlsConfig(d *DDConfig) (*tls.Config, error) {
+	if d.insecure {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	if len(d.conf.Install.CABundle) == 0 {
+		return nil, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		d.traceMsg(fmt.Sprintf("Unable to load the system CAs, only CABundle will be trusted, error was: %+v", err))
+		pool = x509.NewCertPool()
+	}
+	n, err := loadCABundle(pool, d.conf.Install.CABundle)
+	if err != nil {
+		return nil, err
+	}
+	d.verboseMsg(fmt.Sprintf("Trusting %d CA certificate file(s) from %+v in addition to the system CAs", n, d.conf.Install.CABundle))
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// loadCABundle adds the PEM certificates in the file p, or in every file in
+// the directory p, to pool and returns how many files had certificates.  An
+// error is returned if p can't be read or has no PEM certificates.
+func loadCABundle(pool *x509.CertPool, p string) (int, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read CABundle %s: %w", p, err)
+	}
+	files := []string{p}
+	if fi.IsDir() {
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return 0, fmt.Errorf("unable to read the CABundle directory %s: %w", p, err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			// Follow symlinks like the hashed links in /etc/ssl/certs
+			f := filepath.Join(p, e.Name())
+			if fi, err := os.Stat(f); err == nil && fi.Mode().IsRegular() {
+				files = append(files, f)
+			}
+		}
+	}
+
+	n := 0
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return 0, fmt.Errorf("unable to read CA file %s: %w", f, err)
+		}
+		if pool.AppendCertsFromPEM(b) {
+			n++
+		} else if !fi.IsDir() {
+			return 0, fmt.Errorf("CABundle %s doesn't contain any PEM encoded certificates", f)
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("CABundle directory %s doesn't contain any PEM encoded certificates", p)
+	}
+
+	return n, nil
+}
+
+// proxyFunc returns the proxy function for http requests.  A configured Proxy
+// is used if set, otherwise the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environmental variables are honored.
+func proxyFunc(d *DDConfig) (func(*http.Request) (*url.URL, error), error) {
+	if len(d.conf.Options.Proxy) == 0 {
+		d.traceMsg("No proxy configured, using HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment if set")
+		return http.ProxyFromEnvironment, nil
+	}
+
+	pu, err := url.Parse(d.conf.Options.Proxy)
+	if err != nil || len(pu.Host) == 0 {
+		// Don't echo the configured value as it may contain credentials
+		return nil, fmt.Errorf("configured Proxy isn't a valid URL like http://proxy.example.com:3128")
+	}
+	if len(d.conf.Options.ProxyUser) > 0 {
+		pu.User = url.UserPassword(d.conf.Options.ProxyUser, d.conf.Options.ProxyPass)
+	}
+	if pass, ok := pu.User.Password(); ok && len(pass) > 0 {
+		d.addRedact(pass)
+	}
+	d.verboseMsg(fmt.Sprintf("Using configured proxy %s", pu.Redacted()))
+
+	return http.ProxyURL(pu), nil
+}
+
+// countingReader wraps the body of a download and reports the bytes read so
+// far in the spinner prefix, or as a status line when output is plain
+type countingReader struct {
+	d     *DDConfig
+	r     io.Reader
+	n     int64         // Bytes read so far
+	total int64         // Content-Length of the download, -1 if unknown
+	every time.Duration // How often progress is reported
+	last  time.Time     // When progress was last reported
+}
+
+// newCountingReader returns a countingReader for r, total is the expected size
+// in bytes or -1 if it is unknown
+func newCountingReader(d *DDConfig, r io.Reader, total int64) *countingReader {
+	every := 250 * time.Millisecond
+	if d.plain {
+		// Keep CI logs from filling up with progress lines
+		every = 5 * time.Second
+	}
+
+	return &countingReader{d: d, r: r, total: total, every: every, last: time.Now()}
+}
+
+// Read reads from the wrapped reader, reporting progress periodically
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if time.Since(c.last) >= c.every && c.d.spin != nil {
+		c.last = time.Now()
+		if c.total > 0 {
+			c.d.spin.setPrefix(fmt.Sprintf("Downloading release %s of %s...", humanBytes(c.n), humanBytes(c.total)))
+		} else {
+			c.d.spin.setPrefix(fmt.Sprintf("Downloading release %s...", humanBytes(c.n)))
+		}
+	}
+
+	return n, err
+}
+
+// rateLimitedReader wraps the body of a download so it's read no faster than
+// rate bytes per second on average
+type rateLimitedReader struct {
+	d     *DDConfig
+	r     io.Reader
+	rate  int64     // Most bytes read per second
+	n     int64     // Bytes read so far
+	start time.Time // When the first read happened
+}
+
+// newRateLimitedReader returns r limited to rate bytes per second, or r itself
+// if rate is 0 which means the download isn't limited
+func newRateLimitedReader(d *DDConfig, r io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		d.traceMsg("MaxDownloadRate is 0, the release download isn't rate limited")
+		return r
+	}
+	d.verboseMsg(fmt.Sprintf("Release download rate limited to %s/s by MaxDownloadRate", humanBytes(rate)))
+
+	return &rateLimitedReader{d: d, r: r, rate: rate}
+}
+
+// Read reads at most a second's worth of bytes from the wrapped reader then
+// sleeps until the average rate is back under the limit
+func (l *rateLimitedReader) Read(p []byte) (int, error) {
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	if int64(len(p)) > l.rate {
+		p = p[:l.rate]
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+
+	// Wait until reading l.n bytes took as long as the rate allows
+	wait := time.Duration(float64(l.n)/float64(l.rate)*float64(time.Second)) - time.Since(l.start)
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-l.d.ctx.Done():
+			return n, l.d.ctx.Err()
+		}
+	}
+
+	return n, err
+}
+
+// humanBytes returns a readable version of a byte count like 1.5 MB
+func humanBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// gitAuth returns the credentials to clone a private DefectDojo repo with, or
+// nil if none are configured.  An SSH key is used for ssh:// and git@host:
+// CloneURLs and a token for https:// ones, the token can be read from the env
+// variable named in GitTokenEnv so it doesn't have to be in the config file.
+func gitAuth(d *DDConfig) (transport.AuthMethod, error) {
+	if len(d.conf.Install.GitSSHKey) > 0 {
+		if len(d.conf.Install.GitSSHKeyPass) > 0 {
+			d.addRedact(d.conf.Install.GitSSHKeyPass)
+		}
+		d.verboseMsg(fmt.Sprintf("Using the SSH key at %+v to clone the DefectDojo repo", d.conf.Install.GitSSHKey))
+		auth, err := gitssh.NewPublicKeysFromFile(d.conf.Install.GitUser, d.conf.Install.GitSSHKey, d.conf.Install.GitSSHKeyPass)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use GitSSHKey %s: %w", d.conf.Install.GitSSHKey, err)
+		}
+		return auth, nil
+	}
+
+	token := d.conf.Install.GitToken
+	if len(d.conf.Install.GitTokenEnv) > 0 {
+		d.verboseMsg(fmt.Sprintf("Reading the git token from the %+v env variable", d.conf.Install.GitTokenEnv))
+		token = os.Getenv(d.conf.Install.GitTokenEnv)
+		if len(token) == 0 {
+			return nil, fmt.Errorf("GitTokenEnv is set but the %s env variable is empty", d.conf.Install.GitTokenEnv)
+		}
+	}
+	if len(token) == 0 {
+		d.verboseMsg("No git credentials configured, cloning anonymously")
+		return nil, nil
+	}
+	d.addRedact(token)
+	d.verboseMsg(fmt.Sprintf("Using a token for user %+v to clone the DefectDojo repo", d.conf.Install.GitUser))
+
+	return &githttp.BasicAuth{Username: d.conf.Install.GitUser, Password: token}, nil
+}
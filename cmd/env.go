@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"strings"
 	"text/template"
 )
 
@@ -66,7 +67,7 @@ type envVals struct {
 	DD_PORT_SCAN_SOURCE_IP                string
 }
 
-func genAndWriteEnv(d *DDConfig, dbURL string) {
+func genAndWriteEnv(d *DDConfig, dbURL string) error {
 	// Generate randon values for the two keys below
 	secretKey := d.conf.Settings.SecretKey
 	if len(secretKey) < 28 {
@@ -74,8 +75,7 @@ func genAndWriteEnv(d *DDConfig, dbURL string) {
 		s1 := make([]byte, 42)
 		_, err := rand.Read(s1)
 		if err != nil {
-			d.errorMsg("Error generating random data for encryption keys")
-			os.Exit(1)
+			return fmt.Errorf("Error generating random data for encryption keys: %w", err)
 		}
 		secretKey = base64.StdEncoding.EncodeToString(s1)
 	}
@@ -85,8 +85,7 @@ func genAndWriteEnv(d *DDConfig, dbURL string) {
 		s2 := make([]byte, 42)
 		_, err := rand.Read(s2)
 		if err != nil {
-			d.errorMsg("Error generating random data for encryption keys")
-			os.Exit(1)
+			return fmt.Errorf("Error generating random data for encryption keys: %w", err)
 		}
 		credentialKey = base64.StdEncoding.EncodeToString(s2)
 	}
@@ -125,15 +124,38 @@ func genAndWriteEnv(d *DDConfig, dbURL string) {
 	d.traceMsg(fmt.Sprintf("Location of env file is %+v/django-DefectDojo/dojo/settings/.env.prod\n", d.conf.Install.Root))
 	f, err := os.Create(d.conf.Install.Root + "/django-DefectDojo/dojo/settings/.env.prod")
 	if err != nil {
-		d.errorMsg("Unable to create .env.prod file for settings.py configuration")
-		os.Exit(1)
+		return fmt.Errorf("Unable to create .env.prod file for settings.py configuration: %w", err)
 	}
 	defer f.Close()
 
 	// Make substitutions in the template
 	err = t.Execute(f, env)
 	if err != nil {
-		d.errorMsg("Failed to create .env.prod from template")
-		os.Exit(1)
+		return fmt.Errorf("Failed to create .env.prod from template: %w", err)
 	}
+
+	return nil
+}
+
+// setEnvProd sets key to val in the .env.prod file at p, replacing the key if
+// it's already set and leaving the rest of the file as is so keys like
+// DD_SECRET_KEY aren't regenerated
+func setEnvProd(p string, key string, val string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(b), "\n")
+	found := false
+	for i := range lines {
+		if strings.HasPrefix(lines[i], key+"=") {
+			lines[i] = key + "=" + val
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, key+"="+val)
+	}
+
+	return os.WriteFile(p, []byte(strings.Join(lines, "\n")), 0600)
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Categories of install failures.  Errors returned by the install steps and
+// the Installer wrap one of these when the failure falls into a category so
+// callers can check for it with errors.Is, more detail is available with
+// errors.As for an *InstallError or *ChecksumError.
+var (
+	ErrUnsupportedDistro = errors.New("distro is not supported")
+	ErrDownloadFailed    = errors.New("download failed")
+	ErrChecksumMismatch  = errors.New("checksum mismatch")
+	ErrPythonVersion     = errors.New("no supported python version")
+	ErrDatabase          = errors.New("database setup failed")
+)
+
+// InstallError is an install failure in the category Kind, one of the Err
+// variables above, from the step Op.  It matches Kind with errors.Is and
+// unwraps to the underlying error Err.
+type InstallError struct {
+	Kind error  // Category of the failure like ErrDownloadFailed
+	Op   string // What godojo was doing when it failed, may be ""
+	Err  error  // Underlying error
+}
+
+func (e *InstallError) Error() string {
+	if len(e.Op) == 0 {
+		return e.Err.Error()
+	}
+
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *InstallError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the category of the failure
+func (e *InstallError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// ChecksumError is a file whose SHA256 checksum didn't match the published or
+// configured checksum.  A checksum mismatch is also a failed download so it
+// matches both ErrChecksumMismatch and ErrDownloadFailed with errors.Is.
+type ChecksumError struct {
+	File string // Path of the file checked
+	Want string // Expected hex encoded SHA256 checksum
+	Got  string // Hex encoded SHA256 checksum of the file
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("SHA256 checksum mismatch for %s, expected %s but got %s", e.File, e.Want, e.Got)
+}
+
+// Is reports whether target is ErrChecksumMismatch or ErrDownloadFailed
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch || target == ErrDownloadFailed
+}
+
+// Exit codes for each category of install failure so automation can tell why
+// godojo failed, these are documented in printHelp and the README
+const (
+	exitUnknown     = 1  // Unexpected errors without a category
+	exitUnsupported = 10 // ErrUnsupportedDistro
+	exitDownload    = 20 // ErrDownloadFailed including ErrChecksumMismatch
+	exitPython      = 30 // ErrPythonVersion
+	exitDatabase    = 40 // ErrDatabase
+)
+
+// exitCode returns the exit code for the category of the install failure err,
+// or exitUnknown if it doesn't match any of them
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUnsupportedDistro):
+		return exitUnsupported
+	case errors.Is(err, ErrDownloadFailed):
+		return exitDownload
+	case errors.Is(err, ErrPythonVersion):
+		return exitPython
+	case errors.Is(err, ErrDatabase):
+		return exitDatabase
+	}
+
+	return exitUnknown
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/defectdojo/godojo/distros"
+)
+
+func TestChecksumMismatchError(t *testing.T) {
+	d := newTestConfig()
+	tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+	if err := os.WriteFile(tarball, []byte("not the release"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "0000000000000000000000000000000000000000000000000000000000000000"
+	err := compareChecksum(d, tarball, want, "", false)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch, got %v", err)
+	}
+	if !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("Expected a checksum mismatch to also be ErrDownloadFailed, got %v", err)
+	}
+	var ce *ChecksumError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Expected a *ChecksumError, got %T", err)
+	}
+	if ce.File != tarball || ce.Want != want || len(ce.Got) != 64 {
+		t.Errorf("Unexpected ChecksumError %+v", ce)
+	}
+
+	// Still matches once wrapped by the steps above it
+	err = fmt.Errorf("installing DefectDojo from a release tarball failed: %w", err)
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &ce) {
+		t.Errorf("Wrapped checksum error no longer matches, got %v", err)
+	}
+}
+
+func TestDownloadFailedError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	d := newTestConfig()
+	d.conf.Install.DownloadAttempts = 1
+	_, err := downloadRelease(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", 0)
+	if !errors.Is(err, ErrDownloadFailed) {
+		t.Fatalf("Expected ErrDownloadFailed, got %v", err)
+	}
+	if errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("A failed download isn't a checksum mismatch, got %v", err)
+	}
+	var ie *InstallError
+	if !errors.As(err, &ie) || ie.Kind != ErrDownloadFailed {
+		t.Errorf("Expected an *InstallError of kind ErrDownloadFailed, got %#v", err)
+	}
+}
+
+func TestMissingChecksumError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+	if err := os.WriteFile(tarball, []byte("the release"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A release with no checksum to verify it against fails closed
+	d := newTestConfig()
+	err := verifyRelease(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", tarball, "")
+	if !errors.Is(err, ErrDownloadFailed) || !strings.Contains(err.Error(), "AllowUnverified") {
+		t.Fatalf("Expected ErrDownloadFailed mentioning AllowUnverified for a release without a checksum, got %v", err)
+	}
+	err = verifyLocalRelease(d, tarball)
+	if !errors.Is(err, ErrDownloadFailed) {
+		t.Fatalf("Expected ErrDownloadFailed for a local tarball without a checksum, got %v", err)
+	}
+
+	// Unless the user opted out of verifying it
+	d.conf.Install.AllowUnverified = true
+	if err := verifyRelease(d, srv.Client(), srv.URL+"/2.30.0.tar.gz", tarball, ""); err != nil {
+		t.Errorf("Expected no error with AllowUnverified set, got %v", err)
+	}
+	if err := verifyLocalRelease(d, tarball); err != nil {
+		t.Errorf("Expected no error for a local tarball with AllowUnverified set, got %v", err)
+	}
+}
+
+// roundTripFunc lets a func be used as an http.Client's Transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestDefaultReleaseWithoutChecksum(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "dojo-v2.30.0.tar.gz")
+	if err := os.WriteFile(tarball, []byte("the release"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default Github archive never publishes a checksum so it's only a warning
+	var warn bytes.Buffer
+	d := newTestConfig()
+	d.Warning = log.New(&warn, "", 0)
+	cl := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("Expected no request for a checksum Github doesn't publish, got %s", r.URL)
+		return nil, errors.New("unexpected request")
+	})}
+	if err := verifyRelease(d, cl, defaultReleaseURL+"2.30.0.tar.gz", tarball, ""); err != nil {
+		t.Fatalf("Expected no error for the default release URL without a checksum, got %v", err)
+	}
+	if !strings.Contains(warn.String(), "Install.Checksum") {
+		t.Errorf("Expected a warning the release wasn't verified, got %q", warn.String())
+	}
+
+	// A configured Checksum is still checked
+	d.conf.Install.Checksum = strings.Repeat("0", 64)
+	err := verifyRelease(d, cl, defaultReleaseURL+"2.30.0.tar.gz", tarball, "")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch for a wrong Checksum, got %v", err)
+	}
+}
+
+func TestPythonVersionError(t *testing.T) {
+	py := filepath.Join(t.TempDir(), "python")
+	if err := os.WriteFile(py, []byte("#!/bin/sh\necho 'Python 2.7.18'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestConfig()
+	d.conf.Install.PythonMin = "3.11"
+	_, err := pythonVersion(d, py)
+	if !errors.Is(err, ErrPythonVersion) {
+		t.Fatalf("Expected ErrPythonVersion, got %v", err)
+	}
+	if !errors.Is(err, errPythonUnsupported) {
+		t.Errorf("Expected errPythonUnsupported, got %v", err)
+	}
+	if errors.Is(err, ErrUnsupportedDistro) {
+		t.Errorf("A python version error isn't an unsupported distro, got %v", err)
+	}
+}
+
+func TestInstallErrorWrapping(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("set up failed: %w", &InstallError{Kind: ErrDatabase, Op: "preparing the PostgreSQL database", Err: cause})
+
+	if !errors.Is(err, ErrDatabase) {
+		t.Errorf("Expected ErrDatabase, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("Expected the underlying error to be unwrapped, got %v", err)
+	}
+	for _, e := range []error{ErrUnsupportedDistro, ErrDownloadFailed, ErrChecksumMismatch, ErrPythonVersion} {
+		if errors.Is(err, e) {
+			t.Errorf("Database error shouldn't match %v", e)
+		}
+	}
+	want := "set up failed: preparing the PostgreSQL database: connection refused"
+	if err.Error() != want {
+		t.Errorf("Expected message %q, got %q", want, err.Error())
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "unknown", err: errors.New("something else"), want: exitUnknown},
+		{name: "unsupported distro", err: fmt.Errorf("%w: alpine:3.19", ErrUnsupportedDistro), want: exitUnsupported},
+		{name: "download", err: &InstallError{Kind: ErrDownloadFailed, Err: errors.New("status was 404")}, want: exitDownload},
+		{name: "checksum", err: &ChecksumError{File: "dojo.tar.gz"}, want: exitDownload},
+		{name: "python", err: fmt.Errorf("%w 2.7.18 found", errPythonUnsupported), want: exitPython},
+		{name: "database", err: &InstallError{Kind: ErrDatabase, Err: errors.New("connection refused")}, want: exitDatabase},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exitCode(tc.err); got != tc.want {
+				t.Errorf("Expected exit code %d for %v, got %d", tc.want, tc.err, got)
+			}
+		})
+	}
+}
+
+func TestInstallerHardCommandError(t *testing.T) {
+	dir := t.TempDir()
+	cmds := `ID: "Debian:12"
+Commands:
+  bootstrap:
+    Cmds:
+      - Cmd: "exit 3"
+        Errmsg: "Bootstrap failed on purpose"
+        Hard: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "fail.yml"), []byte(cmds), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := distros.LoadCmdDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = distros.LoadCmdDir("") }()
+
+	d := newTestConfig()
+	d.logger = discardLogger{}
+	i := &Installer{d: d, t: &targetOS{distro: "debian", release: "12", id: "debian:12"}}
+
+	// A hard failure that exits would end the test binary rather than get here
+	err := i.Bootstrap()
+	if err == nil || !strings.Contains(err.Error(), "Bootstrap failed on purpose") {
+		t.Fatalf("Expected the failed bootstrap command's error, got %v", err)
+	}
+}
+
+func TestReadEnvVarsBadValue(t *testing.T) {
+	t.Setenv("DD_DEBUG", "maybe")
+	conf := Config{}
+	err := readEnvVars(&conf)
+	if err == nil || !strings.Contains(err.Error(), "DD_DEBUG") {
+		t.Errorf("Expected an error naming DD_DEBUG for a value that isn't a boolean, got %v", err)
+	}
+
+	t.Setenv("DD_DEBUG", "true")
+	t.Setenv("DD_CELERY_BROKER_PORT", "70000")
+	err = readEnvVars(&conf)
+	if err == nil || !strings.Contains(err.Error(), "DD_CELERY_BROKER_PORT") {
+		t.Errorf("Expected an error naming DD_CELERY_BROKER_PORT for a port that's too large, got %v", err)
+	}
+}
+
+func TestPlainProgressFail(t *testing.T) {
+	d := newTestConfig()
+	var buf bytes.Buffer
+	d.Info = log.New(&buf, "", 0)
+	d.plain = true
+
+	d.spin = d.newSpinner("Installing the database...")
+	d.spin.Start()
+	d.spin.Fail()
+	// run fails the spinner again when the install returns the error
+	d.spin.Fail()
+
+	want := "Installing the database...\nInstalling the database... failed\n"
+	if buf.String() != want {
+		t.Errorf("Expected plain progress output %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkHealth takes a pointer to a DDConfig struct and starts the DefectDojo
+// systemd units, if godojo manages them, then polls the health check URL until
+// it returns a 200 or Install.HealthCheck.TimeoutSeconds passes.  The install
+// fails if DefectDojo never answers as the files being in place doesn't mean
+// the app runs.  It's skipped unless Install.HealthCheck.Enabled is true.
+func checkHealth(d *DDConfig) error {
+	hc := d.conf.Install.HealthCheck
+	if !hc.Enabled {
+		d.verboseMsg("Install.HealthCheck.Enabled is false, skipping the post-install health check")
+		return nil
+	}
+	if len(hc.URL) == 0 && len(d.conf.Settings.UwsgiMode) > 0 && d.conf.Settings.UwsgiMode != "http" {
+		d.warnMsg(fmt.Sprintf("uwsgi is in %s mode which doesn't answer HTTP requests, set Install.HealthCheck.URL "+
+			"to the web server in front of DefectDojo to check it.  Skipping the health check", d.conf.Settings.UwsgiMode))
+		return nil
+	}
+
+	d.sectionMsg("Checking DefectDojo starts")
+	err := startServices(d)
+	if err != nil {
+		return err
+	}
+	u := healthURL(d)
+	timeout := time.Duration(hc.TimeoutSeconds) * time.Second
+	if d.dryRun {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would wait up to %v for %s to return 200 OK", timeout, u))
+		return nil
+	}
+
+	d.spin = d.newSpinner("Waiting for DefectDojo to answer at " + u + "...")
+	d.spin.Start()
+	took, err := waitForHealthy(d, u, timeout, time.Duration(hc.IntervalSeconds)*time.Second)
+	if err != nil {
+		d.spin.Fail()
+		hint := ""
+		if d.conf.Install.Systemd.Manage {
+			hint = "\n  Check why it didn't start with: journalctl -u " + appUnitName
+		}
+		return fmt.Errorf("DefectDojo didn't answer at %s within %v, last result was:\n    %w%s", u, timeout, err, hint)
+	}
+	d.spin.Stop()
+	d.statusMsg(fmt.Sprintf("DefectDojo answered at %s after %v", u, took.Round(100*time.Millisecond)))
+
+	return nil
+}
+
+// startServices starts the systemd units created by setupSystemd and
+// setupCelery, or the celery supervisor programs, so there's a running
+// DefectDojo to check, doing nothing for the ones godojo doesn't manage
+func startServices(d *DDConfig) error {
+	err := startCelery(d)
+	if err != nil {
+		return err
+	}
+	var units []string
+	if d.conf.Install.Systemd.Manage {
+		units = append(units, appUnitName)
+	}
+	if celeryUnits(d) {
+		units = append(units, workerUnitName, beatUnitName)
+	}
+	if len(units) == 0 {
+		d.verboseMsg("Install.Systemd.Manage is false, expecting DefectDojo to already be running")
+		return nil
+	}
+	if _, err := os.Stat("/run/systemd/system"); err != nil && !d.dryRun {
+		d.verboseMsg("systemd isn't running, expecting DefectDojo to already be running")
+		return nil
+	}
+
+	d.verboseMsg("Starting the DefectDojo systemd units for the health check")
+	return sendCmd(d, d.cmdLogger, "systemctl start "+strings.Join(units, " "),
+		"Unable to start the DefectDojo systemd units", true)
+}
+
+// healthURL returns the URL the health check polls, which is
+// Install.HealthCheck.URL if set or the app's local uwsgi address otherwise
+func healthURL(d *DDConfig) string {
+	hc := d.conf.Install.HealthCheck
+	if len(hc.URL) > 0 {
+		return hc.URL
+	}
+
+	port := d.conf.Settings.UwsgiPort
+	if hc.Port > 0 {
+		port = strconv.Itoa(hc.Port)
+	}
+	if len(port) == 0 {
+		port = "8000"
+	}
+
+	return "http://127.0.0.1:" + port + hc.Path
+}
+
+// waitForHealthy GETs u every interval until it returns a 200, returning how
+// long that took or the last error or status once timeout has passed
+func waitForHealthy(d *DDConfig, u string, timeout time.Duration, interval time.Duration) (time.Duration, error) {
+	// The check is against the local host so it skips the proxy and -offline
+	tc, err := tlsConfig(d)
+	if err != nil {
+		return 0, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil
+	tr.TLSClientConfig = tc
+	cl := &http.Client{Timeout: interval + 10*time.Second, Transport: tr}
+
+	ctx, cancel := context.WithTimeout(d.ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	for tries := 1; ; tries++ {
+		err = healthy(ctx, cl, u)
+		if err == nil {
+			d.traceMsg(fmt.Sprintf("%+v returned 200 OK on try %d", u, tries))
+			return time.Since(start), nil
+		}
+		d.traceMsg(fmt.Sprintf("Health check try %d of %+v failed: %+v", tries, u, err))
+
+		select {
+		case <-ctx.Done():
+			return time.Since(start), err
+		case <-time.After(interval):
+		}
+	}
+}
+
+// healthy returns nil if a GET of u returns a 200 or an error saying why not
+func healthy(ctx context.Context, cl *http.Client, u string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status was %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+)
+
+// newTestConfig returns a DDConfig that discards its messages
+func newTestConfig() *DDConfig {
+	d := &DDConfig{quiet: true, ctx: context.Background()}
+	d.Trace = log.New(io.Discard, "", 0)
+	d.Info = log.New(io.Discard, "", 0)
+	d.Warning = log.New(io.Discard, "", 0)
+	d.Error = log.New(io.Discard, "", 0)
+	d.cmdLogger = log.New(io.Discard, "", 0)
+
+	return d
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+// runHooks takes a pointer to a DDConfig struct, the point in the install like
+// post-extract and the Install.Hooks commands for that point and runs them in
+// order with bash from Install.Root.  Each command is logged like any other OS
+// command and a failed command with Hard set stops the hooks, returning an
+// error so the install stops too.  Other failures are logged and skipped.
+func runHooks(d *DDConfig, point string, hooks []hookCmd) error {
+	if len(hooks) == 0 {
+		d.traceMsg(fmt.Sprintf("No %+v hook commands configured", point))
+		return nil
+	}
+
+	d.statusMsg(fmt.Sprintf("Running %d %s hook command(s)", len(hooks), point))
+	for i := range hooks {
+		d.verboseMsg(fmt.Sprintf("Running %+v hook command %d: %+v", point, i+1, hooks[i].Cmd))
+		lerr := fmt.Sprintf("The %s hook command %q failed", point, hooks[i].Cmd)
+		err := execCmd(d, d.cmdLogger, "cd \""+d.conf.Install.Root+"\" && "+hooks[i].Cmd, lerr, 0)
+		if err != nil && hooks[i].Hard {
+			return fmt.Errorf("%s and has Hard set: %w", lerr, err)
+		}
+		if err != nil {
+			d.warnMsg(lerr + ", continuing as Hard isn't set for it")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Logger receives the messages godojo would otherwise print to stdout and
+// write to its log files when it's used as a library through an Installer.
+// Messages are redacted the same way as the CLI's log files.
+type Logger interface {
+	Trace(msg string) // Trace messages and the output of the OS commands run
+	Info(msg string)  // Section and status messages
+	Warn(msg string)  // Warnings
+	Error(msg string) // Errors, the error returned by the Installer has the details
+}
+
+// Options changes how an Installer runs, the zero value runs every step for real
+type Options struct {
+	Logger  Logger          // Receives every message, nil discards them
+	Context context.Context // Cancels running commands and downloads, nil never cancels them
+	DryRun  bool            // Log the commands and downloads instead of running them
+	Yes     bool            // Allow steps that could lose data like dropping an existing database
+	Offline bool            // Fail any HTTP or git network call, see -offline
+	Upgrade bool            // Replace an existing install of a different DefectDojo version, see -upgrade
+}
+
+// Installer runs the core godojo install steps for a Config, returning errors
+// instead of exiting so godojo can be embedded in other programs.  Nothing is
+// prompted for, steps that would ask for confirmation fail unless Options.Yes
+// is set.  Failures in a known category match one of the Err variables like
+// ErrDownloadFailed with errors.Is.
+type Installer struct {
+	d *DDConfig
+	t *targetOS // Target OS, nil until a step needs it
+}
+
+// LoadConfig reads the config file at path p, or ./dojoConfig.yml if p is "",
+// with the same defaults and DD_ environmental variable overrides as the CLI
+func LoadConfig(p string) (*Config, error) {
+	d := &DDConfig{logger: discardLogger{}}
+	d.setGodojoDefaults()
+	d.cfPath = p
+	err := loadConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	err = readEnvVars(&d.conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d.conf, nil
+}
+
+// NewInstaller returns an Installer for a copy of the config cfg, usually from
+// LoadConfig, after checking it the same way the CLI does
+func NewInstaller(cfg *Config, o Options) (*Installer, error) {
+	if cfg == nil {
+		return nil, errors.New("a config is required, see LoadConfig")
+	}
+	l := o.Logger
+	if l == nil {
+		l = discardLogger{}
+	}
+
+	d := &DDConfig{logger: l}
+	d.setGodojoDefaults()
+	pyPath := d.conf.Options.PyPath
+	d.conf = *cfg
+	if len(d.conf.Options.PyPath) == 0 {
+		d.conf.Options.PyPath = pyPath
+	}
+	d.quiet = true
+	d.plain = true
+	d.noColor = true
+	d.dryRun = o.DryRun
+	d.yes = o.Yes
+	d.offline = o.Offline
+	d.upgrade = o.Upgrade
+	if o.Context != nil {
+		d.ctx = o.Context
+	}
+	d.cmdLogger = log.New(redactWriter{d: d, w: traceWriter{l: l}}, "", 0)
+
+	d.initRedact()
+	r := checkConfig(d)
+	if r.err != nil {
+		return nil, fmt.Errorf("the configuration has the following problems:\n%w", r.err)
+	}
+	err := loadDistroCmds(d)
+	if err != nil {
+		return nil, err
+	}
+	setSourceURLs(d)
+	setDBEngine(d)
+	err = resolveVersion(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Installer{d: d}, nil
+}
+
+// Bootstrap installs the OS packages the rest of the install needs
+func (i *Installer) Bootstrap() error {
+	t, err := i.target()
+	if err != nil {
+		return err
+	}
+
+	return bootstrapInstall(i.d, t)
+}
+
+// Download downloads, verifies and extracts the configured DefectDojo release
+// or clones the source for source installs into Install.Root
+func (i *Installer) Download() error {
+	err := getDojo(i.d)
+	if err != nil {
+		return err
+	}
+	finishRollback(i.d)
+
+	return nil
+}
+
+// Extract verifies and extracts the release tarball at path p, which doesn't
+// need to be the configured LocalTarball, into Install.Root
+func (i *Installer) Extract(p string) error {
+	d := i.d
+	if d.dryRun {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would extract %s to %s", p, filepath.Join(d.conf.Install.Root, d.conf.Install.Source)))
+		return nil
+	}
+	err := checkInstalledVersion(d)
+	if err != nil {
+		return err
+	}
+	err = checkRoot(d.conf.Install.Root)
+	if err != nil {
+		return err
+	}
+	err = ensureDir(d.conf.Install.Root)
+	if err != nil {
+		return err
+	}
+
+	d.spin = d.newSpinner("Extracting release...")
+	d.spin.Start()
+	err = localRelease(d, p)
+	if err != nil {
+		return err
+	}
+	finishRollback(d)
+
+	return nil
+}
+
+// SetupDB creates the DefectDojo database and database user, dropping an
+// existing database first if Install.DB.Drop is set, and starts a local
+// database.  The database server must already be installed.
+func (i *Installer) SetupDB() error {
+	t, err := i.target()
+	if err != nil {
+		return err
+	}
+
+	return setupDB(i.d, t)
+}
+
+// target returns the target OS, determining it the first time it's needed
+func (i *Installer) target() (*targetOS, error) {
+	if i.t != nil {
+		return i.t, nil
+	}
+	t := targetOS{}
+	err := determineOS(i.d, &t)
+	if err != nil {
+		return nil, err
+	}
+	i.d.traceMsg(fmt.Sprintf("Target OS is %+v", t.id))
+	i.t = &t
+
+	return i.t, nil
+}
+
+// traceWriter sends what's written to the command log to Logger.Trace
+type traceWriter struct {
+	l Logger
+}
+
+func (w traceWriter) Write(p []byte) (int, error) {
+	w.l.Trace(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// discardLogger is the Logger used when Options.Logger is nil
+type discardLogger struct{}
+
+func (discardLogger) Trace(string) {}
+func (discardLogger) Info(string)  {}
+func (discardLogger) Warn(string)  {}
+func (discardLogger) Error(string) {}
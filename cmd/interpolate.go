@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// interpolateConfig expands ${VAR}, ${VAR:-default} and $VAR references to
+// env variables in every string value of the config so secrets like passwords
+// can come from the environment instead of dojoConfig.yml.  A $$ is a literal
+// $ and every reference to an unset variable without a default is returned in
+// configErrors.
+func interpolateConfig(c *Config) error {
+	var errs configErrors
+	interpolateValue(reflect.ValueOf(c).Elem(), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// interpolateValue expands the env variables in v, recursing into structs and
+// slices, name is the dotted config path of v used in errors
+func interpolateValue(v reflect.Value, name string, errs *configErrors) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			interpolateValue(v.Field(i), strings.TrimPrefix(name+"."+f.Name, "."), errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+		}
+	case reflect.String:
+		s, err := expandEnv(v.String())
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s %w", name, err))
+			return
+		}
+		v.SetString(s)
+	}
+}
+
+// expandEnv returns s with its env variable references replaced by their values
+func expandEnv(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			// Escaped literal $
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("has a ${ without a closing }, use $$ for a literal $")
+			}
+			ref := s[i+2 : i+2+end]
+			name, def, hasDef := strings.Cut(ref, ":-")
+			if !isEnvName(name) {
+				return "", fmt.Errorf("references ${%s} which isn't a valid env variable name", ref)
+			}
+			val, ok := os.LookupEnv(name)
+			switch {
+			case hasDef && len(val) == 0:
+				val = def
+			case !ok:
+				return "", fmt.Errorf("references the env variable %s which isn't set, set it or use ${%s:-default}", name, name)
+			}
+			b.WriteString(val)
+			i += 2 + end
+		case isEnvStart(next):
+			end := i + 2
+			for end < len(s) && (isEnvStart(s[end]) || (s[end] >= '0' && s[end] <= '9')) {
+				end++
+			}
+			name := s[i+1 : end]
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("references the env variable %s which isn't set, set it or use ${%s:-default}", name, name)
+			}
+			b.WriteString(val)
+			i = end - 1
+		default:
+			// Not a reference like the $5 in a password so keep it as is
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// isEnvName returns true if n is a valid env variable name like DB_PASSWORD
+func isEnvName(n string) bool {
+	if len(n) == 0 || !isEnvStart(n[0]) {
+		return false
+	}
+	for i := 1; i < len(n); i++ {
+		if !isEnvStart(n[i]) && (n[i] < '0' || n[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnvStart returns true if c can start an env variable name
+func isEnvStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// handleInterrupt cancels the returned context on SIGINT or SIGTERM so running
+// commands and downloads stop, then stops the install
+func handleInterrupt(d *DDConfig) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		s := <-sig
+		d.stopInstall(fmt.Sprintf("Received %v, stopping the install", s), 130)
+	}()
+
+	return ctx
+}
+
+// startOverallTimeout stops the install once -timeout-overall has passed since
+// godojo started.  Running commands, downloads and clones are cancelled even if
+// their own timeouts haven't passed yet.
+func startOverallTimeout(d *DDConfig) {
+	if d.timeoutOverall <= 0 {
+		return
+	}
+	d.traceMsg(fmt.Sprintf("The install will be stopped if it's still running after %v", d.timeoutOverall))
+	time.AfterFunc(time.Until(d.start.Add(d.timeoutOverall)), func() {
+		d.stopInstall(fmt.Sprintf("Overall timeout exceeded, the install ran longer than the -timeout-overall of %v", d.timeoutOverall), 1)
+	})
+}
+
+// stopInstall cancels running commands and downloads, stops the spinner,
+// removes anything partially written and exits with code after logging msg.
+// Only the first call stops the install, any later call waits for it to exit.
+func (d *DDConfig) stopInstall(msg string, code int) {
+	d.mu.Lock()
+	if d.stopping {
+		d.mu.Unlock()
+		select {}
+	}
+	d.stopping = true
+	d.mu.Unlock()
+
+	d.cancel()
+	if d.spin != nil {
+		d.spin.Fail()
+	}
+	d.errorMsg(msg)
+	if p := d.partialFile(); len(p) > 0 {
+		d.traceMsg(fmt.Sprintf("Removing partially written %+v", p))
+		err := os.RemoveAll(p)
+		if err != nil {
+			d.errorMsg(fmt.Sprintf("Unable to remove partially written %s, error was: %+v", p, err))
+		}
+	}
+	writeReport(d, code)
+	os.Exit(code)
+}
+
+// waitIfStopping blocks while stopInstall exits so the failure of a cancelled
+// command or download isn't reported as the reason the install stopped
+func (d *DDConfig) waitIfStopping() {
+	d.mu.Lock()
+	s := d.stopping
+	d.mu.Unlock()
+	if s {
+		select {}
+	}
+}
+
+// setPartial records p as a file or directory being written that should be
+// removed if the install is stopped, an empty string clears it
+func (d *DDConfig) setPartial(p string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.partial = p
+}
+
+// partialFile returns the file or directory currently being written, if any
+func (d *DDConfig) partialFile() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.partial
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	latestVersion        = "latest"                                                                    // Install.Version that installs the newest stable release
+	defaultLatestURL     = "https://api.github.com/repos/DefectDojo/django-DefectDojo/releases/latest" // Endpoint queried for the newest stable release
+	defaultReleaseAPIURL = "https://api.github.com/repos/DefectDojo/django-DefectDojo/releases/tags/"  // Endpoint a release is looked up at as <ReleaseAPIURL><tag>
+)
+
+// errAPINotFound is returned by githubAPI when the endpoint doesn't exist,
+// e.g. there's no release with that tag
+var errAPINotFound = errors.New("not found in the Github API")
+
+// ghRelease is the part of a Github releases API release godojo uses
+type ghRelease struct {
+	TagName    string    `json:"tag_name"`
+	Draft      bool      `json:"draft"`
+	Prerelease bool      `json:"prerelease"`
+	TarballURL string    `json:"tarball_url"`
+	Assets     []ghAsset `json:"assets"`
+}
+
+// ghAsset is a file attached to a Github release
+type ghAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// resolveVersion takes a pointer to a DDConfig struct and replaces an
+// Install.Version of latest with the newest stable release found at LatestURL
+// so every later step of the run uses that same version.  A pinned Version
+// and source installs are left as is.
+func resolveVersion(d *DDConfig) error {
+	if d.conf.Install.SourceInstall || d.conf.Install.Version != latestVersion {
+		return nil
+	}
+
+	u := d.conf.Install.LatestURL
+	d.traceMsg(fmt.Sprintf("Version is %+v, looking up the latest stable release at %+v", latestVersion, u))
+	v, err := latestRelease(d, u)
+	if err != nil {
+		return fmt.Errorf("Version is %s but the latest stable release couldn't be found at %s, "+
+			"pin Version to a release like 2.32.2 instead: %w", latestVersion, u, err)
+	}
+	d.conf.Install.Version = v
+	d.statusMsg(fmt.Sprintf("Version is %s, the latest stable DefectDojo release is %s", latestVersion, v))
+
+	return nil
+}
+
+// latestRelease returns the version of the newest stable release from the
+// Github releases API endpoint at u
+func latestRelease(d *DDConfig, u string) (string, error) {
+	b, err := githubAPI(d, u)
+	if err != nil {
+		return "", err
+	}
+
+	return parseLatest(b)
+}
+
+// githubAPI returns the body of the Github API endpoint at u, retrying up to
+// DownloadAttempts times if the unauthenticated API rate limit is hit.  A 404
+// is returned as errAPINotFound.
+func githubAPI(d *DDConfig, u string) ([]byte, error) {
+	cl, err := newHTTPClient(d, headTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, delay := retrySettings(d)
+	for i := 1; ; i++ {
+		b, resp, err := getGithubAPI(d, cl, u)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			return b, nil
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errAPINotFound
+		}
+		if !rateLimited(resp) || i >= attempts {
+			return nil, fmt.Errorf("status was %s", resp.Status)
+		}
+		wait, err := rateLimitWait(d, resp, u, delay)
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-d.ctx.Done():
+			d.waitIfStopping()
+			return nil, fmt.Errorf("request to %s cancelled: %w", u, d.ctx.Err())
+		}
+		delay *= 2
+	}
+}
+
+// getGithubAPI GETs the Github API endpoint at u and returns the body of a
+// 200 response, or nil and the response for any other status
+func getGithubAPI(d *DDConfig, cl *http.Client, u string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	err = githubAuth(d, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := cl.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to reach %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b, resp, nil
+}
+
+// releaseDownloadURL returns the URL the release for Install.Version is
+// downloaded from.  With ReleaseAPI set, it's looked up with the Github
+// releases API at ReleaseAPIURL, picking the asset matching ReleaseAsset or
+// the source tarball, otherwise it's built from ReleaseURL.  A looked up URL
+// is reused for the rest of the run.
+func releaseDownloadURL(d *DDConfig) (string, error) {
+	if !d.conf.Install.ReleaseAPI {
+		return d.releaseURL + d.conf.Install.Version + ".tar.gz", nil
+	}
+	if len(d.assetURL) > 0 {
+		return d.assetURL, nil
+	}
+
+	// DefectDojo tags releases without a leading v but forks may not
+	v := strings.TrimPrefix(d.conf.Install.Version, "v")
+	var b []byte
+	var err error
+	for _, tag := range []string{v, "v" + v} {
+		u := d.conf.Install.ReleaseAPIURL + url.PathEscape(tag)
+		d.traceMsg(fmt.Sprintf("Looking up the release %+v at %+v", tag, u))
+		b, err = githubAPI(d, u)
+		if !errors.Is(err, errAPINotFound) {
+			break
+		}
+	}
+	if err != nil {
+		return "", &InstallError{Kind: ErrDownloadFailed, Op: "looking up release " + v + " at " + d.conf.Install.ReleaseAPIURL, Err: err}
+	}
+	var rel ghRelease
+	err = json.Unmarshal(b, &rel)
+	if err != nil {
+		return "", &InstallError{Kind: ErrDownloadFailed, Op: "parsing the release information for " + v, Err: err}
+	}
+	u, err := pickAsset(rel, d.conf.Install.ReleaseAsset)
+	if err != nil {
+		return "", &InstallError{Kind: ErrDownloadFailed, Op: "choosing what to download for release " + rel.TagName, Err: err}
+	}
+	d.statusMsg(fmt.Sprintf("Release %s will be downloaded from %s", rel.TagName, u))
+	d.assetURL = u
+
+	return u, nil
+}
+
+// pickAsset returns the download URL of the first asset of the release rel
+// whose name matches the glob pattern, or the source tarball if pattern is ""
+func pickAsset(rel ghRelease, pattern string) (string, error) {
+	if len(pattern) == 0 {
+		if len(rel.TarballURL) == 0 {
+			return "", errors.New("the release has no source tarball")
+		}
+		return rel.TarballURL, nil
+	}
+
+	names := make([]string, 0, len(rel.Assets))
+	for _, a := range rel.Assets {
+		if ok, _ := path.Match(pattern, a.Name); ok {
+			return a.URL, nil
+		}
+		names = append(names, a.Name)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no asset matches ReleaseAsset %q, the release has no assets, set ReleaseAsset to \"\" for the source tarball", pattern)
+	}
+
+	return "", fmt.Errorf("no asset matches ReleaseAsset %q, the release has %s", pattern, strings.Join(names, ", "))
+}
+
+// parseLatest returns the version of the first release in b that isn't a
+// draft or pre-release.  b is either a single release, as returned by
+// /releases/latest, or a list of releases newest first, as returned by /releases.
+func parseLatest(b []byte) (string, error) {
+	var rels []ghRelease
+	if err := json.Unmarshal(b, &rels); err != nil {
+		var r ghRelease
+		if err := json.Unmarshal(b, &r); err != nil {
+			return "", fmt.Errorf("unable to parse the release information: %w", err)
+		}
+		rels = append(rels, r)
+	}
+
+	for i := range rels {
+		if rels[i].Draft || rels[i].Prerelease {
+			continue
+		}
+		v := strings.TrimPrefix(rels[i].TagName, "v")
+		if versionFormat.MatchString(v) {
+			return v, nil
+		}
+	}
+
+	return "", errors.New("no stable release with a version like 2.32.2 was found")
+}
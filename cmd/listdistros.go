@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/defectdojo/godojo/distros"
+)
+
+// listDistros takes the arguments after the list-distros subcommand and
+// prints the distro releases godojo has built-in commands for, the package
+// manager each family uses and which releases share a command set so users
+// can tell if their target is supported before starting an install
+func listDistros(args []string) {
+	fs := flag.NewFlagSet("list-distros", flag.ExitOnError)
+	fs.Usage = printListDistrosHelp
+	_ = fs.Parse(args)
+
+	fmt.Println("")
+	fmt.Println("Distros godojo can install DefectDojo on:")
+	for _, f := range distros.Supported() {
+		fmt.Println("")
+		fmt.Printf("  %s (%s)\n", f.Name, f.PkgMgr)
+		if len(f.Also) > 0 {
+			fmt.Printf("    Also used for %s\n", strings.Join(f.Also, ", "))
+		}
+		for _, r := range f.Targets {
+			set := "own command set"
+			if r.CmdSet != r.ID {
+				set = "same commands as " + r.CmdSet
+			}
+			fmt.Printf("    %-16s %s\n", r.ID, set)
+		}
+	}
+	fmt.Println("")
+	fmt.Println("  Any release can also be given its own commands with DistroCmdsDir, see the README")
+	fmt.Println("")
+}
+
+func printListDistrosHelp() {
+	fmt.Println("")
+	fmt.Println("Usage of godojo list-distros")
+	fmt.Println("")
+	fmt.Println("./godojo list-distros")
+	fmt.Println("")
+	fmt.Println("  Prints the distro releases godojo has built-in commands for as the Distro:Release IDs")
+	fmt.Println("  godojo identifies the OS as, with the package manager each distro family uses.  Releases")
+	fmt.Println("  that use the same commands as an earlier release of the family are noted so it's clear")
+	fmt.Println("  where the commands change between releases")
+	fmt.Println("")
+}
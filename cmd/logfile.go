@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// openLogFile takes a pointer to a DDConfig struct and opens the file set with
+// -log-file, creating any missing parent directories.  Every message logged
+// after this, along with the OS command output, is also written to that file.
+func openLogFile(d *DDConfig) error {
+	if len(d.logFile) == 0 {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(d.logFile), 0755)
+	if err != nil {
+		return fmt.Errorf("Unable to create the directory for the -log-file %s: %v", d.logFile, err)
+	}
+	f, err := os.OpenFile(d.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to write to the -log-file %s: %v", d.logFile, err)
+	}
+	d.teeLog = log.New(f, "", log.Ldate|log.Ltime)
+	d.traceMsg(fmt.Sprintf("Writing a copy of all log messages and command output to %+v", d.logFile))
+
+	return nil
+}
+
+// teeWriter returns w or, when -log-file is set, a writer that also writes to
+// the -log-file
+func (gd *DDConfig) teeWriter(w io.Writer) io.Writer {
+	if gd.teeLog == nil {
+		return w
+	}
+
+	return io.MultiWriter(w, gd.teeLog.Writer())
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// dojoUnits are the systemd units DefectDojo runs as, in the order they're stopped
+var dojoUnits = []string{appUnitName, workerUnitName, beatUnitName}
+
+// runMaintenance takes a pointer to a DDConfig struct and runs the maintenance
+// phase for -upgrade.  Without -upgrade nothing is stopped so the phase isn't
+// recorded, otherwise re-running with -upgrade would skip it and replace
+// DefectDojo while it's still running.
+func runMaintenance(d *DDConfig) error {
+	if !d.upgrade {
+		d.traceMsg("-upgrade isn't set, no DefectDojo services to stop")
+		addPhaseResult(d, phaseMaintenance, resultSkipped, time.Time{})
+		return nil
+	}
+
+	return runPhase(d, phaseMaintenance, func() error { return startMaintenance(d) })
+}
+
+// startMaintenance takes a pointer to a DDConfig struct and stops the running
+// DefectDojo systemd units before -upgrade replaces the source and runs the
+// migrations so neither happens under a live app.  Units that don't exist or
+// aren't running are left alone and the ones stopped are started again by
+// endMaintenance, or by the rollback if the upgrade fails.  The upgrade stops
+// if a unit is still running after being stopped.
+func startMaintenance(d *DDConfig) error {
+	if _, err := os.Stat("/run/systemd/system"); err != nil && !d.dryRun {
+		d.traceMsg("systemd isn't running, no DefectDojo services to stop before the upgrade")
+		return nil
+	}
+
+	d.sectionMsg("Stopping DefectDojo for the upgrade")
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would stop the running DefectDojo services " + strings.Join(dojoUnits, ", ") +
+			" and start them again once the upgrade is in place")
+		return nil
+	}
+
+	var running []string
+	for _, u := range dojoUnits {
+		if !unitExists(d, u) {
+			d.traceMsg(fmt.Sprintf("The systemd unit %+v doesn't exist, nothing to stop", u))
+			continue
+		}
+		if !unitActive(d, u) {
+			d.statusMsg(fmt.Sprintf("%s isn't running, nothing to stop", u))
+			continue
+		}
+		running = append(running, u)
+	}
+	if len(running) == 0 {
+		d.statusMsg("No DefectDojo services are running, continuing the upgrade")
+		return nil
+	}
+
+	var failed []string
+	for _, u := range running {
+		d.statusMsg("Stopping " + u)
+		err := execCmd(d, d.cmdLogger, "systemctl stop "+u, "Unable to stop "+u, 0)
+		if err != nil || unitActive(d, u) {
+			failed = append(failed, u)
+			continue
+		}
+		d.stopped = append(d.stopped, u)
+	}
+	if len(d.stopped) > 0 {
+		d.addRollback("start the DefectDojo services stopped for the upgrade", func() error {
+			return restartStopped(d)
+		})
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Unable to stop %s before the upgrade, stop them by hand and re-run godojo with -upgrade",
+			strings.Join(failed, ", "))
+	}
+	d.statusMsg("Stopped " + strings.Join(d.stopped, ", ") + " for the upgrade")
+
+	return nil
+}
+
+// endMaintenance takes a pointer to a DDConfig struct and starts the systemd
+// units startMaintenance stopped now the upgraded DefectDojo is in place,
+// reporting any that fail to start.  Nothing is done if none were stopped.
+func endMaintenance(d *DDConfig) {
+	if len(d.stopped) == 0 {
+		return
+	}
+
+	d.sectionMsg("Starting DefectDojo after the upgrade")
+	units := strings.Join(d.stopped, ", ")
+	err := restartStopped(d)
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("%+v\n  Check why with: journalctl -u %s", err, appUnitName))
+		return
+	}
+	d.statusMsg("Started " + units + " after the upgrade")
+}
+
+// restartStopped starts each unit stopped by startMaintenance, returning an
+// error naming the ones that didn't start.  The stopped units are cleared so
+// they're only started once.
+func restartStopped(d *DDConfig) error {
+	units := d.stopped
+	d.stopped = nil
+	var failed []string
+	for _, u := range units {
+		d.statusMsg("Starting " + u)
+		err := execCmd(d, d.cmdLogger, "systemctl start "+u, "Unable to start "+u, 0)
+		if err != nil {
+			failed = append(failed, u)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to start %s after stopping it for the upgrade", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// unitExists returns true if systemd has a unit file for the unit u
+func unitExists(d *DDConfig, u string) bool {
+	out, err := inspectCmd(d, "systemctl show -p LoadState --value "+u, "Unable to check the systemd unit "+u, false)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to check if the systemd unit %+v exists, error was: %+v", u, err))
+		return false
+	}
+
+	return strings.TrimSpace(out) == "loaded"
+}
+
+// unitActive returns true if the systemd unit u is running, is-active exits
+// non-zero for any unit that isn't
+func unitActive(d *DDConfig, u string) bool {
+	_, err := inspectCmd(d, "systemctl is-active --quiet "+u, "Unable to check if "+u+" is running", false)
+
+	return err == nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// phaseMetric is the timing of a phase that ran, one row of the -metrics file
+type phaseMetric struct {
+	Phase         string  `json:"phase"`          // Phase name
+	Result        string  `json:"result"`         // completed or failed
+	Started       string  `json:"started"`        // When the phase started as RFC 3339 in UTC
+	Finished      string  `json:"finished"`       // When the phase completed or failed as RFC 3339 in UTC
+	Seconds       float64 `json:"seconds"`        // How long the phase ran
+	Distro        string  `json:"distro"`         // Distro of the target OS, e.g. ubuntu
+	DistroRelease string  `json:"distro_release"` // Release of the distro, e.g. 22.04
+	Version       string  `json:"version"`        // DefectDojo release or the source branch, tag or commit installed
+	Godojo        string  `json:"godojo"`         // Version of godojo that did the run
+}
+
+// metricsHeader is the CSV header row of the -metrics file, in phaseMetric's field order
+var metricsHeader = []string{"phase", "result", "started", "finished", "seconds", "distro", "distro_release", "version", "godojo"}
+
+// writeMetrics takes a pointer to a DDConfig struct and writes the timings of
+// the phases that ran to the -metrics path.  Skipped phases have no timings
+// so they're left out.  Not being able to write the file is only warned about.
+func writeMetrics(d *DDConfig) {
+	b, err := renderMetrics(newMetrics(d), strings.EqualFold(filepath.Ext(d.metrics), ".json"))
+	if err == nil {
+		err = ensureDir(filepath.Dir(d.metrics))
+	}
+	if err == nil {
+		err = os.WriteFile(d.metrics, b, 0644)
+	}
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("Unable to write the phase metrics to %s, error was: %+v", d.metrics, err))
+		return
+	}
+	d.traceMsg(fmt.Sprintf("Phase metrics written to %+v", d.metrics))
+}
+
+// newMetrics returns the timing of each phase that ran in the order they ran
+func newMetrics(d *DDConfig) []phaseMetric {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	m := []phaseMetric{}
+	for _, p := range d.phaseResults {
+		if p.Result == resultSkipped {
+			continue
+		}
+		m = append(m, phaseMetric{
+			Phase:         p.Name,
+			Result:        p.Result,
+			Started:       p.Started,
+			Finished:      p.Finished,
+			Seconds:       p.Seconds,
+			Distro:        d.target.distro,
+			DistroRelease: d.target.release,
+			Version:       installVersion(d),
+			Godojo:        d.ver,
+		})
+	}
+
+	return m
+}
+
+// renderMetrics returns the phase timings m as a JSON array if asJSON is true,
+// otherwise as CSV with a header row
+func renderMetrics(m []phaseMetric, asJSON bool) ([]byte, error) {
+	if asJSON {
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	_ = w.Write(metricsHeader)
+	for _, r := range m {
+		_ = w.Write([]string{r.Phase, r.Result, r.Started, r.Finished, strconv.FormatFloat(r.Seconds, 'f', 3, 64),
+			r.Distro, r.DistroRelease, r.Version, r.Godojo})
+	}
+	w.Flush()
+
+	return b.Bytes(), w.Error()
+}
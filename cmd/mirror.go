@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Package mirror preflight settings.  The mirror is timed with TCP connects
+// rather than ICMP pings which need raw sockets and are often blocked.
+const (
+	mirrorSlow        = 300 * time.Millisecond // Average connect time above which the mirror is reported as slow
+	mirrorDialTimeout = 5 * time.Second        // How long each connect to the mirror can take
+	mirrorProbes      = 3                      // Number of connects to average
+)
+
+// defaultMirrors are the package mirrors used by each distro when none can be
+// found in the package manager's config
+var defaultMirrors = map[string]string{
+	"ubuntu": "http://archive.ubuntu.com/ubuntu",
+	"debian": "http://deb.debian.org/debian",
+	"rhel":   "https://cdn.redhat.com",
+	"amazon": "https://cdn.amazonlinux.com",
+	"fedora": "https://mirrors.fedoraproject.org",
+	"arch":   "https://geo.mirror.pkgbuild.com",
+	"gentoo": "https://distfiles.gentoo.org",
+	"suse":   "https://download.opensuse.org",
+}
+
+// checkMirror takes a pointer to a DDConfig struct and a pointer to the
+// target OS struct and times connecting to the package mirror the OS package
+// commands will use, warning if it's slow or can't be reached so a bad mirror
+// is found before the bootstrap rather than part way through it.  Nothing is
+// stopped, the package manager may reach the mirror through its own proxy.
+func checkMirror(d *DDConfig, t *targetOS) {
+	if d.skipMirror {
+		d.verboseMsg("-skip-mirror-check set, not checking the package mirror")
+		return
+	}
+
+	u, from := findMirror(t)
+	if u == nil {
+		d.verboseMsg(fmt.Sprintf("No package mirror known for %s, skipping the mirror check", t.id))
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Checking the package mirror %s from %s", u.Host, from))
+	addr := mirrorAddr(u)
+
+	d.spin = d.newSpinner("Checking the package mirror " + u.Hostname() + "...")
+	d.spin.Start()
+	avg, err := mirrorLatency(d, addr)
+	if err != nil {
+		d.spin.Fail()
+		d.warnMsg(fmt.Sprintf("The package mirror %s from %s couldn't be reached: %v\n"+
+			"  The OS package commands will likely fail, fix the mirror or network, or use -skip-mirror-check\n"+
+			"  if the package manager reaches the mirror through a proxy", addr, from, err))
+		return
+	}
+	d.spin.Stop()
+	if avg > mirrorSlow {
+		d.warnMsg(fmt.Sprintf("The package mirror %s from %s is slow, connecting took %s on average\n"+
+			"  Installing the OS packages may take a long time, consider a closer mirror", addr, from, avg.Round(time.Millisecond)))
+		return
+	}
+	d.statusMsg(fmt.Sprintf("Package mirror %s from %s answered in %s", addr, from, avg.Round(time.Millisecond)))
+}
+
+// mirrorLatency returns the average time to open a TCP connection to addr
+// over mirrorProbes attempts, or an error if none of them connected
+func mirrorLatency(d *DDConfig, addr string) (time.Duration, error) {
+	dl := net.Dialer{Timeout: mirrorDialTimeout}
+	var total time.Duration
+	var ok int
+	var err error
+	for i := 0; i < mirrorProbes; i++ {
+		start := time.Now()
+		var conn net.Conn
+		conn, err = dl.DialContext(d.ctx, "tcp", addr)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Connect %d to %s failed: %+v", i+1, addr, err))
+			continue
+		}
+		took := time.Since(start)
+		_ = conn.Close()
+		d.traceMsg(fmt.Sprintf("Connect %d to %s took %s", i+1, addr, took))
+		total += took
+		ok++
+	}
+	if ok == 0 {
+		return 0, err
+	}
+
+	return total / time.Duration(ok), nil
+}
+
+// mirrorAddr returns the host:port to connect to for the mirror URL u
+func mirrorAddr(u *url.URL) string {
+	if len(u.Port()) > 0 {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// findMirror returns the first package mirror in the package manager's config
+// for the target OS t and the file it's from, or the distro's default mirror.
+// nil is returned if the distro has no known mirror.
+func findMirror(t *targetOS) (*url.URL, string) {
+	var found []string
+	switch t.distro {
+	case "ubuntu", "debian":
+		// The distro's own repos come first, third party repos are usually in sources.list.d
+		found = append(found, "/etc/apt/sources.list", "/etc/apt/sources.list.d/"+t.distro+".sources")
+		found = append(found, globFiles("/etc/apt/sources.list.d/*.list")...)
+		found = append(found, globFiles("/etc/apt/sources.list.d/*.sources")...)
+	case "rhel", "amazon", "fedora":
+		found = globFiles("/etc/yum.repos.d/*.repo")
+	case "suse":
+		found = globFiles("/etc/zypp/repos.d/*.repo")
+	case "arch":
+		found = []string{"/etc/pacman.d/mirrorlist"}
+	case "gentoo":
+		found = []string{"/etc/portage/make.conf"}
+	}
+	for _, f := range found {
+		if u := mirrorInFile(f); u != nil {
+			return u, f
+		}
+	}
+
+	def, ok := defaultMirrors[t.distro]
+	if !ok {
+		return nil, ""
+	}
+	u, err := url.Parse(def)
+	if err != nil {
+		return nil, ""
+	}
+
+	return u, "the " + t.distro + " default"
+}
+
+// globFiles returns the files matching the pattern p in sorted order
+func globFiles(p string) []string {
+	m, _ := filepath.Glob(p)
+	return m
+}
+
+// mirrorInFile returns the first http or https mirror URL in the package
+// manager config file f, or nil if there isn't one
+func mirrorInFile(f string) *url.URL {
+	fh, err := os.Open(f)
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+
+	s := bufio.NewScanner(fh)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if len(l) == 0 || strings.HasPrefix(l, "#") {
+			continue
+		}
+		for _, fld := range mirrorFields(l) {
+			u, err := url.Parse(strings.Trim(fld, `"'`))
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Hostname()) == 0 ||
+				strings.Contains(u.Host, "$") {
+				continue
+			}
+			return u
+		}
+	}
+
+	return nil
+}
+
+// mirrorFields returns the parts of the config line l that may be a mirror
+// URL for the apt, dnf, zypper, pacman and portage config formats
+func mirrorFields(l string) []string {
+	switch {
+	case strings.HasPrefix(l, "deb "):
+		// deb [options] uri suite components
+		return strings.Fields(l)[1:]
+	case strings.HasPrefix(l, "URIs:"):
+		return strings.Fields(strings.TrimPrefix(l, "URIs:"))
+	case strings.HasPrefix(l, "baseurl="), strings.HasPrefix(l, "mirrorlist="), strings.HasPrefix(l, "metalink="):
+		return []string{strings.TrimSpace(l[strings.Index(l, "=")+1:])}
+	case strings.HasPrefix(l, "Server"), strings.HasPrefix(l, "GENTOO_MIRRORS"):
+		i := strings.Index(l, "=")
+		if i < 0 {
+			return nil
+		}
+		return strings.Fields(strings.Trim(strings.TrimSpace(l[i+1:]), `"'`))
+	}
+
+	return nil
+}
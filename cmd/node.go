@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Ways Install.Node.Method can install Node.js
+const (
+	nodeDistro     = "distro"     // The distro's own Node.js packages for the major version
+	nodeNodesource = "nodesource" // NodeSource's packages for the major version on deb and rpm distros
+	nodeNVM        = "nvm"        // nvm, which can install any exact version on any distro
+)
+
+// nodeVersion matches the Node.js versions that can be set in Install.Node.Version
+var nodeVersion = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+){0,2}$`)
+
+// defaultNVMURL is the nvm install script used when Install.Node.NVMURL isn't set
+const defaultNVMURL = "https://raw.githubusercontent.com/nvm-sh/nvm/v0.39.7/install.sh"
+
+// nvmDir is where the nvm Method installs nvm and the Node.js versions it manages
+const nvmDir = "/usr/local/nvm"
+
+// gentooNodeTimeout is the timeout for emerging Node.js, which can take hours to compile
+const gentooNodeTimeout = 6 * time.Hour
+
+// installNode takes a pointer to a DDConfig struct and a pointer to the target
+// OS struct and installs the Node.js version in Install.Node.Version with
+// Install.Node.Method for building DefectDojo's frontend, then checks node
+// --version is that version.  It's skipped when Version is blank, leaving
+// Node.js to the OS packages, or the DefectDojo source has no frontend to build.
+func installNode(d *DDConfig, t *targetOS) error {
+	n := d.conf.Install.Node
+	if len(n.Version) == 0 {
+		d.verboseMsg("Install.Node.Version is blank, using the Node.js from the OS packages")
+		return nil
+	}
+	if !needsFrontend(d) {
+		d.statusMsg("The DefectDojo source has no frontend to build, skipping the Node.js install")
+		return nil
+	}
+
+	d.sectionMsg(fmt.Sprintf("Installing Node.js %s with %s", n.Version, n.Method))
+	if n.Method != nodeDistro {
+		err := requireOnline(d, "install Node.js with "+n.Method)
+		if err != nil {
+			return fmt.Errorf("%w, set Install.Node.Method to %s to use the OS packages", err, nodeDistro)
+		}
+	}
+	cmds, err := nodeInstallCmds(d, t)
+	if err != nil {
+		return err
+	}
+
+	var timeout time.Duration
+	if t.distro == "gentoo" && n.Method == nodeDistro {
+		timeout = gentooNodeTimeout
+	}
+	d.spin = d.newSpinner("Installing Node.js " + n.Version + "...")
+	d.spin.Start()
+	for i := range cmds {
+		err = execCmd(d, d.cmdLogger, cmds[i], "Unable to install Node.js "+n.Version, timeout)
+		if err != nil {
+			d.spin.Fail()
+			return fmt.Errorf("Unable to install Node.js %s: %w", n.Version, err)
+		}
+	}
+	d.spin.Stop()
+
+	if d.dryRun {
+		d.statusMsg(fmt.Sprintf("[dry-run] Would check node --version is %s", n.Version))
+		return nil
+	}
+	out, err := inspectCmd(d, "node --version", "Unable to get the installed Node.js version", false)
+	if err != nil {
+		return fmt.Errorf("Node.js was installed but node --version failed, error was: %w", err)
+	}
+	got := firstLine(out)
+	if !nodeVersionMatches(n.Version, got) {
+		return fmt.Errorf("node --version is %s after the install but Install.Node.Version is %s\n"+
+			"  Another Node.js may be first in PATH, or the %s Method doesn't provide that version for %s",
+			got, n.Version, n.Method, t.id)
+	}
+	d.statusMsg(fmt.Sprintf("Node.js %s installed for the frontend build", got))
+
+	return nil
+}
+
+// needsFrontend returns true unless the DefectDojo source is in place and has
+// no components/package.json for yarn to build.  Before the source is
+// downloaded, like in a dry run, it's assumed to have one.
+func needsFrontend(d *DDConfig) bool {
+	src := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+	if _, err := os.Stat(src); err != nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(src, "components", "package.json"))
+
+	return err == nil
+}
+
+// nodeInstallCmds returns the commands that install Install.Node.Version with
+// Install.Node.Method on the target distro or an error if the Method isn't
+// available for it
+func nodeInstallCmds(d *DDConfig, t *targetOS) ([]string, error) {
+	n := d.conf.Install.Node
+	v := strings.TrimPrefix(n.Version, "v")
+	major := strings.SplitN(v, ".", 2)[0]
+
+	switch n.Method {
+	case nodeNVM:
+		// Same on every distro, the node, npm and npx it installs are linked into /usr/local/bin
+		nvm := "export NVM_DIR=" + nvmDir + " && . " + nvmDir + "/nvm.sh && "
+		return []string{
+			"mkdir -p " + nvmDir + " && curl -fsSL " + n.NVMURL + " | NVM_DIR=" + nvmDir + " PROFILE=/dev/null bash",
+			nvm + "nvm install " + v,
+			nvm + "for b in node npm npx; do ln -sf \"$(dirname \"$(nvm which " + v + ")\")/$b\" /usr/local/bin/$b; done",
+		}, nil
+	case nodeNodesource:
+		if t.arch != "amd64" && t.arch != "arm64" && !(t.arch == "arm" && (t.distro == "ubuntu" || t.distro == "debian")) {
+			return nil, fmt.Errorf("NodeSource doesn't provide Node.js packages for %s on %s, set Install.Node.Method to %s or %s",
+				t.id, t.arch, nodeDistro, nodeNVM)
+		}
+		switch t.distro {
+		case "ubuntu", "debian":
+			return []string{
+				"curl -fsSL https://deb.nodesource.com/setup_" + major + ".x | bash -",
+				"DEBIAN_FRONTEND=noninteractive apt-get install -y nodejs",
+			}, nil
+		case "rhel", "amazon", "fedora":
+			return []string{
+				"curl -fsSL https://rpm.nodesource.com/setup_" + major + ".x | bash -",
+				"dnf install -y nodejs",
+			}, nil
+		}
+		return nil, fmt.Errorf("NodeSource doesn't provide Node.js packages for %s, set Install.Node.Method to %s or %s",
+			t.id, nodeDistro, nodeNVM)
+	case nodeDistro:
+		switch t.distro {
+		case "ubuntu", "debian":
+			return []string{"DEBIAN_FRONTEND=noninteractive apt-get install -y nodejs"}, nil
+		case "rhel":
+			return []string{"dnf module reset -y nodejs && dnf module install -y nodejs:" + major}, nil
+		case "amazon", "fedora":
+			return []string{"dnf install -y nodejs" + major}, nil
+		case "suse":
+			return []string{"zypper --non-interactive install nodejs" + major + " npm" + major}, nil
+		case "arch":
+			return []string{"pacman -S --noconfirm --needed nodejs npm"}, nil
+		case "gentoo":
+			return []string{"emerge --ask=n --noreplace --quiet-build \"=net-libs/nodejs-" + major + "*\""}, nil
+		}
+		return nil, fmt.Errorf("godojo doesn't know the Node.js packages for %s, set Install.Node.Method to %s", t.id, nodeNVM)
+	}
+
+	return nil, fmt.Errorf("unknown Install.Node.Method %q, it must be one of %s, %s or %s", n.Method, nodeDistro, nodeNodesource, nodeNVM)
+}
+
+// nodeVersionMatches returns true if the node --version output got is the
+// wanted version, where a major or major.minor want matches any release of it
+func nodeVersionMatches(want string, got string) bool {
+	want = strings.TrimPrefix(strings.TrimSpace(want), "v")
+	got = strings.TrimPrefix(strings.TrimSpace(got), "v")
+
+	return got == want || strings.HasPrefix(got, want+".")
+}
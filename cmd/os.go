@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -10,41 +11,47 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/defectdojo/godojo/distros"
 	c "github.com/mtesauro/commandeer"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// osReleaseFile is where the distro is read from on freedesktop.org and
+// systemd based Linux distros
+var osReleaseFile = "/etc/os-release"
+
 // Supported OSes
 type targetOS struct {
 	id      string
 	os      string
 	distro  string
 	release string
+	arch    string // CPU architecture the OS packages are for using Go's names like amd64, arm64 or arm
 }
 
-func checkOS(d *DDConfig) targetOS {
+func checkOS(d *DDConfig) (targetOS, error) {
 	// Check install OS
 	d.sectionMsg("Determining OS for installation")
 
 	// TODO: write OS determination code for OS X
 	// TODO: test OS detection on Alpine Linux docker
 	target := targetOS{}
-	determineOS(d, &target)
+	err := determineOS(d, &target)
+	if err != nil {
+		return target, err
+	}
 
 	// Use Caser to correctly do the title case for Enlish (golang.org/x/text/cases)
 	c := cases.Title(language.English)
-	d.statusMsg(fmt.Sprintf("OS was determined to be %+v, %+v", c.String(target.os), c.String(target.id)))
+	d.statusMsg(fmt.Sprintf("OS was determined to be %+v, %+v on %+v", c.String(target.os), c.String(target.id), target.arch))
 	d.statusMsg("DefectDojo installation on this OS is supported, continuing")
 
-	return target
+	return target, nil
 }
 
-func determineOS(d *DDConfig, tOS *targetOS) {
+func determineOS(d *DDConfig, tOS *targetOS) error {
 	// Determine OS first
 	tOS.os = runtime.GOOS
 	d.traceMsg(fmt.Sprintf("Determining OS based on GOOS: %+v", tOS.os))
@@ -52,52 +59,123 @@ func determineOS(d *DDConfig, tOS *targetOS) {
 	switch tOS.os {
 	case "linux":
 		d.traceMsg("OS determined to be Linux")
-		determineLinux(d, tOS)
+		tOS.arch = determineArch(d)
+		d.traceMsg(fmt.Sprintf("Architecture determined to be %+v", tOS.arch))
+		return determineLinux(d, tOS)
 	case "darwin":
 		d.traceMsg("OS determined to be Darwin/OS X")
-		fmt.Println("OS X/Darwin")
-		d.errorMsg("OS X is not YET a supported installation platform")
-		os.Exit(1)
+		return &InstallError{Kind: ErrUnsupportedDistro, Err: errors.New("OS X is not YET a supported installation platform")}
 	case "windows":
 		d.traceMsg("OS determined to be Windows")
-		d.errorMsg("Windows is not a supported installation platform")
-		os.Exit(1)
+		return &InstallError{Kind: ErrUnsupportedDistro, Err: errors.New("Windows is not a supported installation platform")}
 	}
+
+	return nil
 }
 
-func determineLinux(d *DDConfig, tOS *targetOS) {
+func determineLinux(d *DDConfig, tOS *targetOS) error {
 	// Determine the Linux Distro the installer is running on
 	// Based on Based on https://unix.stackexchange.com/questions/6345/how-can-i-get-distribution-name-and-version-number-in-a-simple-shell-script
 	d.traceMsg("Determining what Linux distro is the target OS")
 
 	// freedesktop.org and systemd
-	_, err := os.Stat("/etc/os-release")
+	_, err := os.Stat(osReleaseFile)
 	if err == nil {
 		// That file exists
-		d.traceMsg("Determining Linux distro from /etc/os-release")
-		tOS.distro, tOS.release, tOS.id = parseOSRelease(d, "/etc/os-release")
-		if strings.Contains(strings.ToLower(tOS.distro), "rocky") {
-			d.traceMsg("Linux distro is Rocky Linux")
-			d.traceMsg("Treating Rocky Linux as RHEL for remainder of the install")
-			d.statusMsg("Identified Rocky Linux which is compatible with RHEL.")
+		d.traceMsg("Determining Linux distro from " + osReleaseFile)
+		tOS.distro, tOS.release, tOS.id, err = parseOSRelease(d, osReleaseFile)
+		if err != nil {
+			return err
+		}
+		if name, ok := rhelCompatible(tOS.distro); ok {
+			d.traceMsg(fmt.Sprintf("Linux distro is %s", name))
+			d.traceMsg(fmt.Sprintf("Detected OS family is RHEL, treating %s as RHEL for remainder of the install", name))
+			d.statusMsg(fmt.Sprintf("Identified %s which is compatible with RHEL.", name))
 			d.statusMsg("Using RHEL install method going forward...")
 			tOS.distro = "rhel"
 			tOS.release = onlyMajorVer(tOS.release)
 			tOS.id = tOS.distro + ":" + tOS.release
 			// Check to make sure we're using a newer Python than the OS ships with
-			checkOldPythonForRHEL(d)
-			return
+			return checkOldPythonForRHEL(d)
 		}
 		if strings.Contains(strings.ToLower(tOS.distro), "rhel") {
 			d.traceMsg("Linux distro is RHEL")
+			d.traceMsg("Detected OS family is RHEL")
 			tOS.distro = "rhel"
 			tOS.release = onlyMajorVer(tOS.release)
 			tOS.id = tOS.distro + ":" + tOS.release
 			// Check to make sure we're using a newer Python than the OS ships with
-			checkOldPythonForRHEL(d)
-			return
+			return checkOldPythonForRHEL(d)
 		}
-		return
+		if strings.ToLower(tOS.distro) == "amzn" {
+			d.traceMsg(fmt.Sprintf("Linux distro is Amazon Linux %s", tOS.release))
+			tOS.distro = "amazon"
+			tOS.release = onlyMajorVer(tOS.release)
+			tOS.id = tOS.distro + ":" + tOS.release
+			return nil
+		}
+		if strings.ToLower(tOS.distro) == "fedora" {
+			// Fedora uses dnf like RHEL but has its own command set so RHEL's EPEL setup isn't used
+			d.traceMsg(fmt.Sprintf("Linux distro is Fedora %s", tOS.release))
+			d.statusMsg("Using Fedora install method going forward...")
+			// Fedora's VERSION_ID is only the release number like 39, there's no minor version to drop
+			tOS.distro = "fedora"
+			tOS.id = tOS.distro + ":" + tOS.release
+			// Check the Python that will be used on newer Fedora releases
+			checkNewPythonForFedora(d, tOS.release)
+			return nil
+		}
+		if strings.Contains(strings.ToLower(tOS.distro), "arch") {
+			// Arch has no VERSION_ID in /etc/os-release so every Arch install uses the rolling target
+			d.traceMsg(fmt.Sprintf("Linux distro is Arch Linux (%s), treating it as rolling-release", tOS.distro))
+			d.statusMsg("Using Arch Linux install method going forward...")
+			tOS.distro = "arch"
+			tOS.release = "rolling"
+			tOS.id = tOS.distro + ":" + tOS.release
+			return nil
+		}
+		if strings.ToLower(tOS.distro) == "gentoo" {
+			// Gentoo's VERSION_ID is the baselayout version, not a release, so every Gentoo install uses the rolling target
+			d.traceMsg(fmt.Sprintf("Linux distro is Gentoo (baselayout %s), treating it as rolling-release", tOS.release))
+			d.traceMsg("Portage compiles packages from source so package installs on Gentoo may take a while")
+			d.statusMsg("Using Gentoo install method going forward...")
+			tOS.distro = "gentoo"
+			tOS.release = "rolling"
+			tOS.id = tOS.distro + ":" + tOS.release
+			return nil
+		}
+		if isSUSE(tOS.distro) {
+			d.traceMsg(fmt.Sprintf("Linux distro is SUSE (%s %s)", tOS.distro, tOS.release))
+			d.statusMsg("Using SUSE install method going forward...")
+			tOS.distro = "suse"
+			tOS.release = onlyMajorVer(tOS.release)
+			tOS.id = tOS.distro + ":" + tOS.release
+			// Check to make sure we're using a newer Python than the OS ships with
+			checkOldPythonForSUSE(d)
+			return nil
+		}
+		if strings.ToLower(tOS.distro) == "raspbian" {
+			// 32-bit Raspberry Pi OS has its own ID but is Debian with Debian's release numbers
+			d.traceMsg(fmt.Sprintf("Linux distro is Raspberry Pi OS %s", tOS.release))
+			d.statusMsg("Identified Raspberry Pi OS which is based on Debian.")
+			d.statusMsg("Using Debian install method going forward...")
+			tOS.distro = "debian"
+			tOS.release = debianMajorVer(tOS.release)
+			tOS.id = tOS.distro + ":" + tOS.release
+			return nil
+		}
+		if strings.Contains(strings.ToLower(tOS.distro), "debian") {
+			d.traceMsg("Linux distro is Debian")
+			if _, err := os.Stat("/etc/rpi-issue"); err == nil {
+				// 64-bit Raspberry Pi OS uses Debian's ID
+				d.statusMsg("Identified Raspberry Pi OS which is based on Debian.")
+			}
+			tOS.distro = "debian"
+			tOS.release = debianMajorVer(tOS.release)
+			tOS.id = tOS.distro + ":" + tOS.release
+			return nil
+		}
+		return nil
 	}
 
 	// lsb_release command is present
@@ -105,8 +183,8 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// The command was found
 		d.traceMsg("Determining Linux distro from lsb_release command")
-		tOS.distro, tOS.release, tOS.id = parseLsbCmd(d, lsbCmd)
-		return
+		tOS.distro, tOS.release, tOS.id, err = parseLsbCmd(d, lsbCmd)
+		return err
 	}
 
 	// /etc/lsb-release is present
@@ -114,8 +192,8 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// The file was found
 		d.traceMsg("Determining Linux distro from /etc/lsb-release")
-		tOS.distro, tOS.release, tOS.id = parseEtcLsb(d, "/etc/lsb-release")
-		return
+		tOS.distro, tOS.release, tOS.id, err = parseEtcLsb(d, "/etc/lsb-release")
+		return err
 	}
 
 	// /etc/issue is present
@@ -123,8 +201,8 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// The file was found
 		d.traceMsg("Determining Linux distro from /etc/issue")
-		tOS.distro, tOS.release, tOS.id = parseEtcIss(d, "/etc/issue")
-		return
+		tOS.distro, tOS.release, tOS.id, err = parseEtcIss(d, "/etc/issue")
+		return err
 	}
 
 	// /etc/debian_version is present
@@ -132,8 +210,13 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// The file was found
 		d.traceMsg("Determining Linux distro from /etc/debian_version")
-		tOS.distro, tOS.release, tOS.id = parseEtcDeb(d, "/etc/debian_version")
-		return
+		tOS.distro, tOS.release, tOS.id, err = parseEtcDeb(d, "/etc/debian_version")
+		if err != nil {
+			return err
+		}
+		tOS.release = debianMajorVer(tOS.release)
+		tOS.id = tOS.distro + ":" + tOS.release
+		return nil
 	}
 
 	// Older SUSE Linux installation
@@ -141,8 +224,7 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// Distro is too old, not supported
 		d.traceMsg("Older SuSe Linux distro isn't supported by this installer")
-		d.errorMsg("Older versions of SuSe Linux are not suppported, quitting")
-		os.Exit(1)
+		return &InstallError{Kind: ErrUnsupportedDistro, Err: errors.New("Older versions of SuSe Linux are not suppported")}
 	}
 
 	// RHEL's way of doing this
@@ -150,65 +232,180 @@ func determineLinux(d *DDConfig, tOS *targetOS) {
 	if err == nil {
 		// Distro is too old, not supported
 		d.traceMsg("Older RedHat Linux distros aren't supported by this installer")
-		d.errorMsg("Older versions of Redhat Linux are not suppported, quitting")
-		os.Exit(1)
+		return &InstallError{Kind: ErrUnsupportedDistro, Err: errors.New("Older versions of Redhat Linux are not suppported")}
 	}
 
 	d.traceMsg("Unable to determine the linux distro, assuming unsupported.")
-	d.errorMsg("Unable to determine the Linux install target, quitting")
-	os.Exit(1)
+	return &InstallError{Kind: ErrUnsupportedDistro, Err: errors.New("Unable to determine the Linux install target")}
+}
+
+// rhelCompatible takes the distro ID from /etc/os-release and returns the
+// distro's name and true if it is a binary compatible rebuild of RHEL
+func rhelCompatible(distro string) (string, bool) {
+	// Map of os-release IDs to the distro's name
+	compat := map[string]string{
+		"rocky":     "Rocky Linux",
+		"almalinux": "AlmaLinux",
+	}
+
+	for id, name := range compat {
+		if strings.Contains(strings.ToLower(distro), id) {
+			return name, true
+		}
+	}
+
+	return "", false
 }
 
-func checkOldPythonForRHEL(d *DDConfig) {
+func checkOldPythonForRHEL(d *DDConfig) error {
 	d.traceMsg(fmt.Sprintf("Python path is %s\n", d.conf.Options.PyPath))
 	// RHEL 8's latest Python is 3.9
 	// Python 3.9 is too old for DB migrations so PyPath is must be set to install on RHEL 8
 	// If PyPath is set to Python 3.9, then error out
 	if strings.Compare(d.conf.Options.PyPath, "/usr/bin/python3.9") == 0 {
 		// For DD versions greater than 2.31.0, ENV variable PYPATH needs to be sent to an alternate install of Python
-		d.errorMsg("RHEL 8 requires setting PYPATH environmental variable to a Python 3.11.x installation\n" +
+		return &InstallError{Kind: ErrPythonVersion, Err: errors.New("RHEL 8 requires setting PYPATH environmental variable to a Python 3.11.x installation\n" +
 			"         Either set an explicit path to a Python 3.11.x install or\n" +
 			"         Use update-alternatives / symlinks to have default Python be v3.11.x\n" +
-			"         godojo assumes the default Python is at /usr/bin/python3")
-		os.Exit(1)
+			"         godojo assumes the default Python is at /usr/bin/python3")}
 	}
 
-	return
+	return nil
+}
+
+// isSUSE returns true if the os-release ID is SLES or any openSUSE flavor
+func isSUSE(id string) bool {
+	id = strings.ToLower(id)
+	return strings.Contains(id, "suse") || strings.Contains(id, "sles")
 }
 
-func parseOSRelease(d *DDConfig, f string) (string, string, string) {
+func checkOldPythonForSUSE(d *DDConfig) {
+	d.traceMsg(fmt.Sprintf("Python path is %s\n", d.conf.Options.PyPath))
+	// SLES 15 and Leap 15 ship Python 3.6 as python3 which is too old for DefectDojo
+	// The bootstrap installs python311 so PyPath should point at it
+	if strings.Compare(d.conf.Options.PyPath, "/usr/bin/python3") == 0 {
+		d.warnMsg("SUSE 15's default python3 is Python 3.6, godojo installs Python 3.11 during bootstrap\n" +
+			"         Set the PYPATH environmental variable to /usr/bin/python3.11 if the Python checks fail")
+	}
+}
+
+func checkNewPythonForFedora(d *DDConfig, rel string) {
+	d.traceMsg(fmt.Sprintf("Python path is %s\n", d.conf.Options.PyPath))
+	// Fedora 39 and later ship Python 3.12 as python3, the bootstrap installs python3.11 alongside it
+	r, err := strconv.Atoi(rel)
+	if err != nil || r < 39 {
+		return
+	}
+	if strings.Compare(d.conf.Options.PyPath, "/usr/bin/python3") == 0 {
+		d.warnMsg("Fedora " + rel + "'s default python3 is Python 3.12, godojo installs Python 3.11 during bootstrap\n" +
+			"         Set the PYPATH environmental variable to /usr/bin/python3.11 if DefectDojo's requirements fail to install")
+	}
+}
+
+func parseOSRelease(d *DDConfig, f string) (string, string, string, error) {
 	// Setup a map of what we need to what /etc/os-release uses
 	fields := map[string]string{
 		"distro":  "ID",
 		"release": "VERSION_ID",
 	}
-	linMap := parseFile(d, f, "=", fields)
+	linMap, err := parseFile(d, f, "=", fields)
+	if err != nil {
+		return "", "", "", err
+	}
 
-	return linMap["distro"], linMap["release"], linMap["distro"] + ":" + linMap["release"]
+	return linMap["distro"], linMap["release"], linMap["distro"] + ":" + linMap["release"], nil
 
 }
 
+// determineArch returns the architecture of the OS packages with Go's names.
+// dpkg is asked first as a 32-bit Debian userland, like 32-bit Raspberry Pi
+// OS, can run on a 64-bit kernel, otherwise it's the architecture godojo was
+// built for.
+func determineArch(d *DDConfig) string {
+	if _, err := exec.LookPath("dpkg"); err == nil {
+		out, err := exec.CommandContext(d.ctx, "dpkg", "--print-architecture").Output()
+		if err == nil {
+			a := strings.TrimSpace(string(out))
+			d.traceMsg(fmt.Sprintf("dpkg architecture is %+v", a))
+			switch a {
+			case "armhf", "armel":
+				return "arm"
+			case "i386":
+				return "386"
+			}
+			if len(a) > 0 {
+				return a
+			}
+		}
+		d.traceMsg(fmt.Sprintf("Unable to get the architecture from dpkg, error was: %+v", err))
+	}
+
+	return runtime.GOARCH
+}
+
+// archPackages returns the command installing the extra OS packages DefectDojo
+// needs on the target's architecture or "" if there are none.  PyPI has fewer
+// prebuilt wheels for ARM so pip builds more of the requirements from source
+// on Debian-family ARM systems like Raspberry Pi OS.
+func archPackages(t *targetOS) string {
+	switch t.distro {
+	case "ubuntu", "debian":
+	default:
+		return ""
+	}
+
+	switch t.arch {
+	case "arm64":
+		return "DEBIAN_FRONTEND=noninteractive apt-get install -y libffi-dev libpq-dev libxml2-dev libxslt1-dev zlib1g-dev"
+	case "arm":
+		// Even fewer wheels are built for 32-bit ARM so Rust is needed to build packages like cryptography
+		return "DEBIAN_FRONTEND=noninteractive apt-get install -y libffi-dev libpq-dev libxml2-dev libxslt1-dev zlib1g-dev rustc cargo"
+	}
+
+	return ""
+}
+
+// onlyMajorVer returns the part of the version v before the first dot, or all
+// of v if it has no dot like Amazon Linux's 2023
 func onlyMajorVer(v string) string {
-	major, _, found := strings.Cut(v, ".")
-	if found {
-		return major
+	if len(v) == 0 {
+		return "Bad Version Number"
+	}
+	major, _, _ := strings.Cut(v, ".")
+
+	return major
+}
+
+// debianMajorVer takes a Debian release as a version number (12.5) or as a
+// codename (bookworm or bookworm/sid) and returns only the major version
+func debianMajorVer(v string) string {
+	// Codenames for the supported Debian releases
+	codenames := map[string]string{
+		"bullseye": "11",
+		"bookworm": "12",
 	}
 
-	return "Bad Version Number"
+	rel := strings.ToLower(strings.TrimSpace(v))
+	rel, _, _ = strings.Cut(rel, "/")
+	if num, ok := codenames[rel]; ok {
+		return num
+	}
+	major, _, _ := strings.Cut(rel, ".")
+
+	return major
 }
 
-func parseLsbCmd(d *DDConfig, cmd string) (string, string, string) {
+func parseLsbCmd(d *DDConfig, cmd string) (string, string, string, error) {
 	// Setup map to hold parsed values
 	vals := make(map[string]string)
 
 	// Execute the lsb_release command with -a (all) and parse the output
-	runCmd := exec.Command(cmd, "-a")
+	runCmd := exec.CommandContext(d.ctx, cmd, "-a")
 
 	// Run command and gather its output
 	cmdOut, err := runCmd.CombinedOutput()
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Failed to run OS command, error was: %+v", err))
-		os.Exit(1)
+		return "", "", "", fmt.Errorf("Failed to run OS command %s, error was: %w", cmd, err)
 	}
 
 	// Parse command output for the strings we need
@@ -231,44 +428,43 @@ func parseLsbCmd(d *DDConfig, cmd string) (string, string, string) {
 
 	if _, ok := vals["distro"]; !ok {
 		// The distro key hasn't been set above
-		d.errorMsg("Unable to determine distro from lsb_release command, quitting.")
-		os.Exit(1)
+		return "", "", "", errors.New("Unable to determine distro from lsb_release command")
 	}
 	if _, ok := vals["release"]; !ok {
 		// The distro key hasn't been set above
-		d.errorMsg("Unable to determine release from lsb_release command, quitting.")
-		os.Exit(1)
+		return "", "", "", errors.New("Unable to determine release from lsb_release command")
 	}
 
-	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"]
+	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"], nil
 }
 
-func parseEtcLsb(d *DDConfig, f string) (string, string, string) {
+func parseEtcLsb(d *DDConfig, f string) (string, string, string, error) {
 	// Setup a map of what we need to what /etc/lsb-release uses
 	fields := map[string]string{
 		"distro":  "DISTRIB_ID",
 		"release": "DISTRIB_RELEASE",
 	}
-	linMap := parseFile(d, f, "=", fields)
+	linMap, err := parseFile(d, f, "=", fields)
+	if err != nil {
+		return "", "", "", err
+	}
 
-	return linMap["distro"], linMap["release"], linMap["distro"] + ":" + linMap["release"]
+	return linMap["distro"], linMap["release"], linMap["distro"] + ":" + linMap["release"], nil
 }
 
-func parseEtcIss(d *DDConfig, f string) (string, string, string) {
+func parseEtcIss(d *DDConfig, f string) (string, string, string, error) {
 	// Setup return map
 	vals := make(map[string]string)
 
 	// Open the file for parsing
 	file, err := os.Open(f)
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to open file: %+v\nError was: %v", f, err))
-		os.Exit(1)
+		return "", "", "", fmt.Errorf("Unable to open file: %+v\nError was: %w", f, err)
 	}
 	defer func() {
 		err := file.Close()
 		if err != nil {
 			d.traceMsg(fmt.Sprintf("Erro closing file\nError was: %v", err))
-			os.Exit(1)
 		}
 	}()
 
@@ -276,8 +472,7 @@ func parseEtcIss(d *DDConfig, f string) (string, string, string) {
 	reader := bufio.NewReader(file)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to read file: %+v\nError was: %v", f, err))
-		os.Exit(1)
+		return "", "", "", fmt.Errorf("Unable to read file: %+v\nError was: %w", f, err)
 	}
 	fields := strings.Split(line, " ")
 	vals["distro"] = strings.ToLower(fields[0])
@@ -289,10 +484,10 @@ func parseEtcIss(d *DDConfig, f string) (string, string, string) {
 		vals["release"] = tmp[0] + "." + tmp[1]
 	}
 
-	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"]
+	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"], nil
 }
 
-func parseEtcDeb(d *DDConfig, f string) (string, string, string) {
+func parseEtcDeb(d *DDConfig, f string) (string, string, string, error) {
 	// Setup map to hold parsed values
 	vals := make(map[string]string)
 	vals["distro"] = "debian"
@@ -300,14 +495,12 @@ func parseEtcDeb(d *DDConfig, f string) (string, string, string) {
 	// Open the file for parsing
 	file, err := os.Open(f)
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to open file: %+v\nError was: %v", f, err))
-		os.Exit(1)
+		return "", "", "", fmt.Errorf("Unable to open file: %+v\nError was: %w", f, err)
 	}
 	defer func() {
 		err := file.Close()
 		if err != nil {
 			d.errorMsg(fmt.Sprintf("Unable to close file\nError was: %v", err))
-			os.Exit(1)
 		}
 	}()
 
@@ -315,30 +508,27 @@ func parseEtcDeb(d *DDConfig, f string) (string, string, string) {
 	reader := bufio.NewReader(file)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to read file: %+v\nError was: %v", f, err))
-		os.Exit(1)
+		return "", "", "", fmt.Errorf("Unable to read file: %+v\nError was: %w", f, err)
 	}
 	// TODO: Test this with a Debian docker
 	vals["release"] = strings.ToLower(strings.Trim(line, "\n\t "))
 
-	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"]
+	return vals["distro"], vals["release"], vals["distro"] + ":" + vals["release"], nil
 }
 
-func parseFile(d *DDConfig, f string, sep string, flds map[string]string) map[string]string {
+func parseFile(d *DDConfig, f string, sep string, flds map[string]string) (map[string]string, error) {
 	// Setup return map
 	vals := make(map[string]string)
 
 	// Open the file for parsing
 	file, err := os.Open(f)
 	if err != nil {
-		d.errorMsg(fmt.Sprintf("Unable to open file: %+v\nError was: %v", f, err))
-		os.Exit(1)
+		return nil, fmt.Errorf("Unable to open file: %+v\nError was: %w", f, err)
 	}
 	defer func() {
 		err := file.Close()
 		if err != nil {
 			d.errorMsg(fmt.Sprintf("Unable to close file\nError was: %v", err))
-			os.Exit(1)
 		}
 	}()
 
@@ -363,13 +553,13 @@ func parseFile(d *DDConfig, f string, sep string, flds map[string]string) map[st
 		}
 	}
 
-	return vals
+	return vals, nil
 }
 
 // prepOSForDojo takes a pointer to a DDConfig struct and a string representing
 // the id for the target OS and installs the necessary OS software required by
 // DefectDojo
-func prepOSForDojo(d *DDConfig, t *targetOS) {
+func prepOSForDojo(d *DDConfig, t *targetOS) error {
 	// Gather OS commands to bootstrap the install
 	d.sectionMsg("Installing OS packages needed for DefectDojo")
 
@@ -383,50 +573,93 @@ func prepOSForDojo(d *DDConfig, t *targetOS) {
 		d.traceMsg("Searching for commands to prep for the installer on Ubuntu")
 		err := distros.GetUbuntu(cInstallerPrep, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to bootstrap target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case t.distro == "debian":
+		d.traceMsg("Searching for commands to prep for the installer on Debian")
+		err := distros.GetDebian(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
 		}
 	case strings.ToLower(t.distro) == "rhel":
 		d.traceMsg("Searching for commands for bootstrapping RHEL")
 		err := distros.GetRHEL(cInstallerPrep, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to bootstrap target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case strings.ToLower(t.distro) == "amazon":
+		d.traceMsg("Searching for commands for bootstrapping Amazon Linux")
+		err := distros.GetAmazon(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case strings.ToLower(t.distro) == "fedora":
+		d.traceMsg("Searching for commands for bootstrapping Fedora")
+		err := distros.GetFedora(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case strings.ToLower(t.distro) == "arch":
+		d.traceMsg("Searching for commands for bootstrapping Arch Linux")
+		err := distros.GetArch(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case strings.ToLower(t.distro) == "gentoo":
+		d.traceMsg("Searching for commands for bootstrapping Gentoo")
+		d.traceMsg("Portage builds packages from source, Node.js alone can take hours to compile")
+		err := distros.GetGentoo(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
+		}
+	case strings.ToLower(t.distro) == "suse":
+		d.traceMsg("Searching for commands for bootstrapping SUSE Linux")
+		err := distros.GetSUSE(cInstallerPrep, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to bootstrap target OS %s: %w", t.id, err)
 		}
 	default:
 		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+		return fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
 	}
 
 	// Install the OS packages
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Installing OS packages..."
+	d.spin = d.newSpinner("Installing OS packages...")
 	d.spin.Start()
 	// Run the installer prep commands for the target OS
 	d.traceMsg(fmt.Sprintf("Getting commands to bootstrap %s", t.id))
 	tCmds, err := distros.CmdsForTarget(cInstallerPrep, t.id)
 	if err != nil {
-		fmt.Printf("Error getting commands to bootstrap target OS %s\n", t.id)
-		os.Exit(1)
+		d.spin.Fail()
+		return fmt.Errorf("Error getting commands to bootstrap target OS %s: %w", t.id, err)
 	}
 
 	// Inject values from config into commands
 	d.injectConfigVals(tCmds)
 
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return err
+	}
+
+	// Some architectures need more packages than the distro's command set installs
+	if extra := archPackages(t); len(extra) > 0 {
+		d.traceMsg(fmt.Sprintf("Installing the extra OS packages needed on %+v", t.arch))
+		err = sendCmd(d, d.cmdLogger, extra, "Unable to install the OS packages needed on "+t.arch, true)
+		if err != nil {
+			d.spin.Fail()
+			return err
+		}
 	}
 	d.spin.Stop()
 	d.statusMsg("Installing OS packages complete")
+
+	return nil
 }
 
 // prepDjango(d, &osTarget)
-func prepDjango(d *DDConfig, t *targetOS) {
+func prepDjango(d *DDConfig, t *targetOS) error {
 	// Prep OS for Django framework (user, virtualenv, chownership)
 	d.sectionMsg("Preparing the OS for DefectDojo installation")
 
@@ -439,57 +672,109 @@ func prepDjango(d *DDConfig, t *targetOS) {
 		d.traceMsg("Searching for commands to prep Django on Ubuntu")
 		err := distros.GetUbuntu(cPrepDjango, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to prep Django target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "debian":
+		d.traceMsg("Searching for commands to prep Django on Debian")
+		err := distros.GetDebian(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
 		}
 	case t.distro == "rhel":
 		d.traceMsg("Searching for commands to prep Django on RHEL")
 		err := distros.GetRHEL(cPrepDjango, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to prep Django target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "amazon":
+		d.traceMsg("Searching for commands to prep Django on Amazon Linux")
+		err := distros.GetAmazon(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "fedora":
+		d.traceMsg("Searching for commands to prep Django on Fedora")
+		err := distros.GetFedora(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "arch":
+		d.traceMsg("Searching for commands to prep Django on Arch Linux")
+		err := distros.GetArch(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "gentoo":
+		d.traceMsg("Searching for commands to prep Django on Gentoo")
+		err := distros.GetGentoo(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
+		}
+	case t.distro == "suse":
+		d.traceMsg("Searching for commands to prep Django on SUSE Linux")
+		err := distros.GetSUSE(cPrepDjango, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to prep Django target OS %s: %w", t.id, err)
 		}
 	default:
 		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+		return fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
+	}
+
+	// Make sure any pinned pip or virtualenv exists before building the virtualenv
+	err := checkPins(d)
+	if err != nil {
+		return fmt.Errorf("Pinned pip or virtualenv version isn't available: %w", err)
+	}
+	err = installVirtualenvPin(d)
+	if err != nil {
+		return err
+	}
+	if len(d.conf.Install.Requirements) > 0 {
+		d.statusMsg(fmt.Sprintf("Installing the Python requirements from %s set in Requirements instead of the source's requirements.txt", requirementsFile(d)))
+	} else {
+		d.statusMsg(fmt.Sprintf("Installing the Python requirements from %s", requirementsFile(d)))
 	}
 
 	// Start the spinner
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Preparing the OS for DefectDojo..."
+	d.spin = d.newSpinner("Preparing the OS for DefectDojo...")
 	d.spin.Start()
 	// Run the prep Django commands for the target OS
 	d.traceMsg(fmt.Sprintf("Getting commands to prep Django on %s", t.id))
 	tCmds, err := distros.CmdsForTarget(cPrepDjango, t.id)
 	if err != nil {
-		fmt.Printf("Error getting commands to bootstrap target OS %s\n", t.id)
-		os.Exit(1)
+		d.spin.Fail()
+		return fmt.Errorf("Error getting commands to bootstrap target OS %s: %w", t.id, err)
 	}
 
 	// Inject values from config into commands
 	d.injectConfigVals(tCmds)
 
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return err
 	}
 	d.spin.Stop()
+	removeVirtualenvPin(d)
+	tracePipVersion(d)
 	d.statusMsg("Preparing the OS complete")
+
+	return nil
 }
 
 // createSettings
-func createSettings(d *DDConfig, t *targetOS) {
+func createSettings(d *DDConfig, t *targetOS) error {
 	// Create settings.py for DefectDojo
 	// TODO: Update this to use local_settings.py
 	d.sectionMsg("Creating settings.py for DefectDojo")
 
 	// Write out the settings file
 	// TODO: Update this to local_settings.py
-	createSettingsPy(d)
+	err := createSettingsPy(d)
+	if err != nil {
+		return err
+	}
 
 	// Create new create settings command package
 	cCreateSettings := c.NewPkg("createsettings")
@@ -500,56 +785,91 @@ func createSettings(d *DDConfig, t *targetOS) {
 		d.traceMsg("Searching for commands to create settings on Ubuntu")
 		err := distros.GetUbuntu(cCreateSettings, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to create settings target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "debian":
+		d.traceMsg("Searching for commands to create settings on Debian")
+		err := distros.GetDebian(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
 		}
 	case t.distro == "rhel":
 		d.traceMsg("Searching for commands to create settings on RHEL")
 		err := distros.GetRHEL(cCreateSettings, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to create settings target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "amazon":
+		d.traceMsg("Searching for commands to create settings on Amazon Linux")
+		err := distros.GetAmazon(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "fedora":
+		d.traceMsg("Searching for commands to create settings on Fedora")
+		err := distros.GetFedora(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "arch":
+		d.traceMsg("Searching for commands to create settings on Arch Linux")
+		err := distros.GetArch(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "gentoo":
+		d.traceMsg("Searching for commands to create settings on Gentoo")
+		err := distros.GetGentoo(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
+		}
+	case t.distro == "suse":
+		d.traceMsg("Searching for commands to create settings on SUSE Linux")
+		err := distros.GetSUSE(cCreateSettings, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to create settings target OS %s: %w", t.id, err)
 		}
 	default:
 		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+		return fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
 	}
 
 	// Start the spinner
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Creating settings.py for DefectDojo..."
+	d.spin = d.newSpinner("Creating settings.py for DefectDojo...")
 	d.spin.Start()
 	// Run the create settings commands for the target OS
 	d.traceMsg(fmt.Sprintf("Getting commands to create settings on %s", t.id))
 	tCmds, err := distros.CmdsForTarget(cCreateSettings, t.id)
 	if err != nil {
-		fmt.Printf("Error getting commands to bootstrap target OS %s\n", t.id)
-		os.Exit(1)
+		d.spin.Fail()
+		return fmt.Errorf("Error getting commands to bootstrap target OS %s: %w", t.id, err)
 	}
 
 	// Inject values from config into commands
 	d.injectConfigVals(tCmds)
 
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return err
 	}
 	d.spin.Stop()
 	d.statusMsg("Creating settings.py for DefectDojo complete")
 
+	return nil
 }
 
 // createSettingsPy
-func createSettingsPy(d *DDConfig) {
-	// Setup the env.prod file used by settings.py
+func createSettingsPy(d *DDConfig) error {
+	// Setup env file for production
+	return genAndWriteEnv(d, databaseURL(d))
+}
 
+// databaseURL returns the DD_DATABASE_URL for the configured database
+func databaseURL(d *DDConfig) string {
 	// Create the database URL for the env file - https://github.com/kennethreitz/dj-database-url
 	dbURL := ""
-	switch d.conf.Install.DB.Engine {
+	switch dbFamily(d) {
 	case "SQLite":
 		// sqlite:///PATH
 		dbURL = "sqlite:///defectdojo.db"
@@ -563,17 +883,18 @@ func createSettingsPy(d *DDConfig) {
 			strconv.Itoa(d.conf.Install.DB.Port) + "/" + d.conf.Install.DB.Name
 	}
 
-	// Setup env file for production
-	genAndWriteEnv(d, dbURL)
-
+	return dbURL
 }
 
 // setupDefectDojo
-func setupDefectDojo(d *DDConfig, t *targetOS) {
+func setupDefectDojo(d *DDConfig, t *targetOS) error {
 	d.sectionMsg("Setting up Django for DefectDojo")
 
 	// Do some preliminary work to the install root
-	prepAndPatch(d, t.id)
+	err := prepAndPatch(d, t.id)
+	if err != nil {
+		return err
+	}
 
 	// Create new setup DefectDojo command package
 	cSetupDojo := c.NewPkg("setupdojo")
@@ -584,57 +905,87 @@ func setupDefectDojo(d *DDConfig, t *targetOS) {
 		d.traceMsg("Searching for commands to setup DefectDojo on Ubuntu")
 		err := distros.GetUbuntu(cSetupDojo, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to setup DefectDojo on target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "debian":
+		d.traceMsg("Searching for commands to setup DefectDojo on Debian")
+		err := distros.GetDebian(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
 		}
 	case t.distro == "rhel":
 		d.traceMsg("Searching for commands to setup DefectDojo on RHEL")
 		err := distros.GetRHEL(cSetupDojo, t.id)
 		if err != nil {
-			fmt.Printf("Error searching for commands to setup DefectDojo on target OS %s\n", t.id)
-			os.Exit(1)
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "amazon":
+		d.traceMsg("Searching for commands to setup DefectDojo on Amazon Linux")
+		err := distros.GetAmazon(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "fedora":
+		d.traceMsg("Searching for commands to setup DefectDojo on Fedora")
+		err := distros.GetFedora(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "arch":
+		d.traceMsg("Searching for commands to setup DefectDojo on Arch Linux")
+		err := distros.GetArch(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "gentoo":
+		d.traceMsg("Searching for commands to setup DefectDojo on Gentoo")
+		err := distros.GetGentoo(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
+		}
+	case t.distro == "suse":
+		d.traceMsg("Searching for commands to setup DefectDojo on SUSE Linux")
+		err := distros.GetSUSE(cSetupDojo, t.id)
+		if err != nil {
+			return fmt.Errorf("Error searching for commands to setup DefectDojo on target OS %s: %w", t.id, err)
 		}
 	default:
 		d.traceMsg(fmt.Sprintf("Distro identified (%s) is not supported", t.id))
-		fmt.Printf("Distro identified by godojo (%s) is not supported, exiting...\n", t.id)
-		os.Exit(1)
+		return fmt.Errorf("%w: %s", ErrUnsupportedDistro, t.id)
 	}
 
 	// Start the spinner
-	d.spin = spinner.New(spinner.CharSets[34], 100*time.Millisecond)
-	d.spin.Prefix = "Setting up Django for DefectDojo..."
+	d.spin = d.newSpinner("Setting up Django for DefectDojo...")
 	d.spin.Start()
 	// Run the setup DefectDojo commands for the target OS
 	d.traceMsg(fmt.Sprintf("Getting commands to setup DefectDojo on %s", t.id))
 	tCmds, err := distros.CmdsForTarget(cSetupDojo, t.id)
 	if err != nil {
-		fmt.Printf("Error getting commands to setup DefectDojo on target OS %s\n", t.id)
-		os.Exit(1)
+		d.spin.Fail()
+		return fmt.Errorf("Error getting commands to setup DefectDojo on target OS %s: %w", t.id, err)
 	}
 
 	// Inject values from config into commands
 	d.injectConfigVals(tCmds)
 
-	for i := range tCmds {
-		sendCmd(d,
-			d.cmdLogger,
-			tCmds[i].Cmd,
-			tCmds[i].Errmsg,
-			tCmds[i].Hard)
+	err = runCmds(d, tCmds)
+	if err != nil {
+		d.spin.Fail()
+		return err
 	}
 	d.spin.Stop()
 	d.statusMsg("Setting up Django complete")
+
+	return nil
 }
 
-func prepAndPatch(d *DDConfig, id string) {
+func prepAndPatch(d *DDConfig, id string) error {
 	// Setup expect script needed to set initial admin password
 	d.traceMsg(fmt.Sprintf("Injecting file %s at %s", "setup-superuser.expect", d.conf.Install.Root+"/django-DefectDojo"))
 	// Inject expect script to change admin password
 	terr := injectFile(d, suExpect, d.conf.Install.Root+"/django-DefectDojo", 0755)
 	if terr != nil {
-		fmt.Println("Unable to add expect script to installation")
-		fmt.Printf("Error was: %+v\n", terr)
-		os.Exit(1)
+		return fmt.Errorf("Unable to add expect script to installation: %w", terr)
 	}
 
 	err := patchOMatic(d)
@@ -651,6 +1002,8 @@ func prepAndPatch(d *DDConfig, id string) {
 
 	// Make sure special characters don't break adding admin user
 	d.conf.Install.Admin.Pass = escSpCar(d.conf.Install.Admin.Pass)
+
+	return nil
 }
 
 // injectFile
@@ -659,9 +1012,7 @@ func injectFile(d *DDConfig, n string, p string, mask fs.FileMode) error {
 	f, err := embd.ReadFile(n)
 	if err != nil {
 		// Embeded file was not found.
-		fmt.Println("Unable to extract embedded patch file")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to extract embedded patch file: %w", err)
 	}
 
 	// Strip off embedded directory from filename
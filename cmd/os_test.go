@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetermineLinuxFromOSRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		want      string
+	}{
+		{name: "amazon linux 2", osRelease: "NAME=\"Amazon Linux\"\nVERSION=\"2\"\nID=\"amzn\"\nID_LIKE=\"centos rhel fedora\"\nVERSION_ID=\"2\"\n", want: "amazon:2"},
+		{name: "amazon linux 2023", osRelease: "NAME=\"Amazon Linux\"\nVERSION=\"2023\"\nID=\"amzn\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"2023\"\n", want: "amazon:2023"},
+		{name: "fedora", osRelease: "NAME=\"Fedora Linux\"\nVERSION=\"39 (Server Edition)\"\nID=fedora\nVERSION_ID=39\n", want: "fedora:39"},
+		{name: "ubuntu", osRelease: "NAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nID=ubuntu\n", want: "ubuntu:22.04"},
+		{name: "debian", osRelease: "NAME=\"Debian GNU/Linux\"\nVERSION_ID=\"12\"\nID=debian\n", want: "debian:12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osReleaseFile = filepath.Join(t.TempDir(), "os-release")
+			defer func() { osReleaseFile = "/etc/os-release" }()
+			if err := os.WriteFile(osReleaseFile, []byte(tt.osRelease), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			tOS := targetOS{}
+			if err := determineLinux(newTestConfig(), &tOS); err != nil {
+				t.Fatal(err)
+			}
+			if tOS.id != tt.want {
+				t.Errorf("Expected the target ID %s, got %s", tt.want, tOS.id)
+			}
+		})
+	}
+}
+
+func TestFedoraPythonWarning(t *testing.T) {
+	osReleaseFile = filepath.Join(t.TempDir(), "os-release")
+	defer func() { osReleaseFile = "/etc/os-release" }()
+	if err := os.WriteFile(osReleaseFile, []byte("ID=fedora\nVERSION_ID=40\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warn bytes.Buffer
+	d := newTestConfig()
+	d.Warning = log.New(&warn, "", 0)
+	d.conf.Options.PyPath = "/usr/bin/python3"
+	tOS := targetOS{}
+	if err := determineLinux(d, &tOS); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(warn.Bytes(), []byte("Fedora 40's default python3 is Python 3.12")) {
+		t.Errorf("Expected the Fedora 40 Python warning, got %q", warn.String())
+	}
+}
+
+func TestOnlyMajorVer(t *testing.T) {
+	tests := map[string]string{
+		"9.3":  "9",
+		"8":    "8",
+		"2023": "2023",
+		"15.5": "15",
+		"":     "Bad Version Number",
+	}
+	for v, want := range tests {
+		if got := onlyMajorVer(v); got != want {
+			t.Errorf("Expected onlyMajorVer(%q) to be %q, got %q", v, want, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// pinFormat matches the exact versions accepted for PipVersion and
+// VirtualenvVersion like 23.3.2 or 24.0
+var pinFormat = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// pypiURL is the PyPI JSON API used to check pinned versions exist
+const pypiURL = "https://pypi.org/pypi/"
+
+// pinSpec returns the pip requirement for pkg, pinned to ver if it isn't ""
+func pinSpec(pkg string, ver string) string {
+	if len(ver) == 0 {
+		return pkg
+	}
+
+	return pkg + "==" + ver
+}
+
+// venvPath returns the directory DefectDojo's virtualenv is created in, which
+// is Install.VenvPath if set or Install.Root itself by default
+func venvPath(d *DDConfig) string {
+	if len(d.conf.Install.VenvPath) == 0 {
+		return d.conf.Install.Root
+	}
+
+	return filepath.Clean(d.conf.Install.VenvPath)
+}
+
+// requirementsFile returns the absolute path of the pip requirements file
+// installed into DefectDojo's virtualenv, which is Install.Requirements if set
+// or the source's requirements.txt by default
+func requirementsFile(d *DDConfig) string {
+	if len(d.conf.Install.Requirements) == 0 {
+		return filepath.Join(d.conf.Install.Root, d.conf.Install.Source, "requirements.txt")
+	}
+	p, err := filepath.Abs(d.conf.Install.Requirements)
+	if err != nil {
+		return d.conf.Install.Requirements
+	}
+
+	return p
+}
+
+// virtualenvDir returns the directory a pinned virtualenv is installed into
+// so it's used to create DefectDojo's virtualenv instead of the distro's
+func virtualenvDir(d *DDConfig) string {
+	return filepath.Join(d.conf.Install.Root, ".godojo-virtualenv")
+}
+
+// virtualenvEnv returns the env prefix for the virtualenv command which points
+// Python at the pinned virtualenv, or "" if no version is pinned
+func virtualenvEnv(d *DDConfig) string {
+	if len(d.conf.Install.VirtualenvVersion) == 0 {
+		return ""
+	}
+
+	return "PYTHONPATH=" + virtualenvDir(d) + " "
+}
+
+// checkPins checks each pinned pip and virtualenv version is published on PyPI
+// so a typo fails before the virtualenv is built instead of part way through
+func checkPins(d *DDConfig) error {
+	pins := []struct{ pkg, ver string }{
+		{"pip", d.conf.Install.PipVersion},
+		{"virtualenv", d.conf.Install.VirtualenvVersion},
+	}
+	cl, err := newHTTPClient(d, time.Duration(d.conf.Install.DownloadTimeoutSeconds)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pins {
+		if len(p.ver) == 0 {
+			continue
+		}
+		u := pypiURL + url.PathEscape(p.pkg) + "/" + url.PathEscape(p.ver) + "/json"
+		d.traceMsg(fmt.Sprintf("Checking %+v is published at %+v", pinSpec(p.pkg, p.ver), u))
+		if d.dryRun {
+			d.statusMsg("[dry-run] Would check " + pinSpec(p.pkg, p.ver) + " is published on PyPI")
+			continue
+		}
+		resp, err := getWithContext(d, cl, u)
+		if err != nil {
+			return fmt.Errorf("unable to check %s is published on PyPI: %w", pinSpec(p.pkg, p.ver), err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%s version %s isn't published on PyPI", p.pkg, p.ver)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unable to check %s is published on PyPI, status was %s", pinSpec(p.pkg, p.ver), resp.Status)
+		}
+	}
+
+	return nil
+}
+
+// installVirtualenvPin installs the pinned virtualenv version into
+// virtualenvDir using PyPath's pip, doing nothing if no version is pinned
+func installVirtualenvPin(d *DDConfig) error {
+	if len(d.conf.Install.VirtualenvVersion) == 0 {
+		return nil
+	}
+
+	d.verboseMsg(fmt.Sprintf("Installing %+v into %+v", pinSpec("virtualenv", d.conf.Install.VirtualenvVersion), virtualenvDir(d)))
+	return sendCmd(d, d.cmdLogger,
+		d.conf.Options.PyPath+" -m pip install --upgrade --target "+virtualenvDir(d)+" "+
+			pinSpec("virtualenv", d.conf.Install.VirtualenvVersion),
+		"Unable to install the pinned virtualenv version", true)
+}
+
+// tracePipVersion traces the pip version in DefectDojo's virtualenv after it's built
+func tracePipVersion(d *DDConfig) {
+	if d.dryRun {
+		return
+	}
+
+	out, err := exec.CommandContext(d.ctx, filepath.Join(venvPath(d), "bin", "python3"), "-m", "pip", "--version").CombinedOutput()
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to get the pip version in the virtualenv, error was: %+v", err))
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("pip in the DefectDojo virtualenv is %+v", strings.TrimSpace(string(out))))
+	if len(d.conf.Install.PipVersion) > 0 && !strings.HasPrefix(string(out), "pip "+d.conf.Install.PipVersion+" ") {
+		d.warnMsg(fmt.Sprintf("pip in the virtualenv isn't the pinned version %s", d.conf.Install.PipVersion))
+	}
+}
+
+// removeVirtualenvPin removes the pinned virtualenv once DefectDojo's
+// virtualenv is built as it's only needed to create it
+func removeVirtualenvPin(d *DDConfig) {
+	if len(d.conf.Install.VirtualenvVersion) == 0 || d.dryRun {
+		return
+	}
+
+	err := os.RemoveAll(virtualenvDir(d))
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to remove %+v, error was: %+v", virtualenvDir(d), err))
+	}
+}
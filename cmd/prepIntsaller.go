@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"os/user"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +17,9 @@ func prepInstaller(d *DDConfig) {
 	// Read the command-line arguments
 	readArgs(d)
 
+	// Cap how long the install can run, if asked to
+	startOverallTimeout(d)
+
 	// Setup logging
 
 	// Handle default and dev installs
@@ -30,19 +32,67 @@ func prepInstaller(d *DDConfig) {
 	}
 
 	// Read in any environmental variables
-	readEnvVars(&d.conf)
+	err := readEnvVars(&d.conf)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Install.Quiet works like -quiet for -trace-to-syslog
+	if d.conf.Install.Quiet {
+		syslogQuiet(d)
+	}
 
 	// Write final install configuration to a file
-	writeFinalConfig(d)
+	err = writeFinalConfig(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
 
 	// Initialize Redactatron
 	d.initRedact()
 
 	// Ensure installer has sufficient privileges
-	checkUserPrivs(d)
+	checkUserPrivs(d, installPrivReason)
 
 	// Check that configured DB configuration is sane
-	saneDBConfig(d)
+	err = saneDBConfig(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+
+	// Check the rest of the config and report every problem found at once
+	err = validateConfig(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("The configuration has the following problems:\n%v\n"+
+			"  Please correct the configuration and run the installer again", err))
+		os.Exit(1)
+	}
+	err = loadDistroCmds(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+
+	// Make it obvious in the logs when TLS isn't verified
+	if d.insecure {
+		d.warnMsg("-insecure-skip-verify is set, TLS certificates for downloads and clones WON'T be verified")
+	}
+
+	// Use the configured release and clone URLs
+	setSourceURLs(d)
+
+	// Look up the release to install once for the whole run if Version is latest
+	err = resolveVersion(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+
+	// Use the canonical name of the configured database engine
+	setDBEngine(d)
 
 	// Logging is setup, start using statusMsg and errorMsg functions for output
 	d.traceMsg("Logging established, trace log begins here")
@@ -55,7 +105,11 @@ func prepInstaller(d *DDConfig) {
 func defaultConfig(d *DDConfig) {
 	d.traceMsg("Inside of defaultConfig")
 	// Temporarily write out the config file into current working directory
-	writeDefaultConfig(d.cf, false)
+	err := writeDefaultConfig(d.cf, false)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// Read the config file
 	readConfigFile(d)
@@ -96,8 +150,9 @@ func defaultConfig(d *DDConfig) {
 // readEnvVars reads the DefectDojo supported environmental variables and
 // overrides any options set in the configuration file. These variables
 // are used to supply either install-time configurations or provide values
-// that are used in DefectDojo's settings.py configuration file
-func readEnvVars(gdConf *dojoConfig) {
+// that are used in DefectDojo's settings.py configuration file.  It returns an
+// error if a variable's value can't be converted to the setting's type.
+func readEnvVars(gdConf *Config) error {
 	// Env variables pulled from repo. Add newly supported env vars below and
 	// to the switch statement below after the for that ranges over overrides
 	// TODO: Add non-setting.py ENV vars like DD_SourcCommit
@@ -214,10 +269,11 @@ func readEnvVars(gdConf *dojoConfig) {
 
 	// Return early if no env variables are matched
 	if !match {
-		return
+		return nil
 	}
 
 	// Override config values if we found matching Env vars
+	var err error
 	for k, v := range overrides {
 		// Set DojoConfig struct values from Env variables to override config values
 		// Have to do this as a switch statement as there's no sanity to DefectDojo env var naming
@@ -245,9 +301,10 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_CELERY_BROKER_PATH":
 			gdConf.Settings.CeleryBrokerPath = v
 		case "DD_CELERY_BROKER_PORT":
-			port := convInt(v, "DD_CELERY_BROKER_PORT provided via environmental variable isn't a valid port number")
-			intLessThan(port, 65535, "DD_CELERY_BROKER_PORT provided via environmental variable is too large")
-			gdConf.Settings.CeleryBrokerPort = port
+			gdConf.Settings.CeleryBrokerPort, err = convInt(v, "DD_CELERY_BROKER_PORT provided via environmental variable isn't a valid port number")
+			if err == nil {
+				err = intLessThan(gdConf.Settings.CeleryBrokerPort, 65535, "DD_CELERY_BROKER_PORT provided via environmental variable is too large")
+			}
 		case "DD_CELERY_BROKER_SCHEME":
 			gdConf.Settings.CeleryBrokerScheme = v
 		case "DD_CELERY_BROKER_URL":
@@ -259,17 +316,17 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_CELERY_RESULT_BACKEND":
 			gdConf.Settings.CeleryResultBackend = v
 		case "DD_CELERY_RESULT_EXPIRES":
-			gdConf.Settings.CeleryResultExpires = convInt(v, "DD_CELERY_RESULT_EXPIRES provided via environmental variable isn't a valid number")
+			gdConf.Settings.CeleryResultExpires, err = convInt(v, "DD_CELERY_RESULT_EXPIRES provided via environmental variable isn't a valid number")
 		case "DD_CELERY_TASK_IGNORE_RESULT":
-			gdConf.Settings.CeleryTaskIgnoreResult = convBool(v, "DD_CELERY_TASK_IGNORE_RESULT environmental variable was not a boolean.")
+			gdConf.Settings.CeleryTaskIgnoreResult, err = convBool(v, "DD_CELERY_TASK_IGNORE_RESULT environmental variable was not a boolean.")
 		case "DD_CELERY_TASK_SERIALIZER":
 			gdConf.Settings.CeleryTaskSerializer = v
 		case "DD_CREDENTIAL_AES_256_KEY":
 			gdConf.Settings.CredentialAES256Key = v
 		case "DD_CSRF_COOKIE_HTTPONLY":
-			gdConf.Settings.CSRFCookieHTTPOnly = convBool(v, "DD_CSRF_COOKIE_HTTPONLY environmental variable was not a boolean.")
+			gdConf.Settings.CSRFCookieHTTPOnly, err = convBool(v, "DD_CSRF_COOKIE_HTTPONLY environmental variable was not a boolean.")
 		case "DD_CSRF_COOKIE_SECURE":
-			gdConf.Settings.CSRFCookieSecure = convBool(v, "DD_CSRF_COOKIE_SECURE environmental variable was not a boolean.")
+			gdConf.Settings.CSRFCookieSecure, err = convBool(v, "DD_CSRF_COOKIE_SECURE environmental variable was not a boolean.")
 		case "DD_DATABASE_ENGINE":
 			gdConf.Settings.DatabaseEngine = v
 		case "DD_DATABASE_HOST":
@@ -287,11 +344,11 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_DATABASE_USER":
 			gdConf.Settings.DatabaseUser = v
 		case "DD_DATA_UPLOAD_MAX_MEMORY_SIZE":
-			gdConf.Settings.DataUploadMaxMemorySize = convInt(v, "DD_DATA_UPLOAD_MAX_MEMORY_SIZE provided via environmental variable isn't a valid number")
+			gdConf.Settings.DataUploadMaxMemorySize, err = convInt(v, "DD_DATA_UPLOAD_MAX_MEMORY_SIZE provided via environmental variable isn't a valid number")
 		case "DD_DEBUG":
-			gdConf.Settings.Debug = convBool(v, "DD_DEBUG environmental variable was not a boolean.")
+			gdConf.Settings.Debug, err = convBool(v, "DD_DEBUG environmental variable was not a boolean.")
 		case "DD_DJANGO_ADMIN_ENABLED":
-			gdConf.Settings.DjangoAdminEnabled = convBool(v, "DD_DJANGO_ADMIN_ENABLED environmental variable was not a boolean.")
+			gdConf.Settings.DjangoAdminEnabled, err = convBool(v, "DD_DJANGO_ADMIN_ENABLED environmental variable was not a boolean.")
 		case "DD_EMAIL_URL":
 			gdConf.Settings.EmailURL = v
 		case "DD_ENV":
@@ -299,7 +356,7 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_ENV_PATH":
 			gdConf.Settings.EnvPath = v
 		case "DD_FORCE_LOWERCASE_TAGS":
-			gdConf.Settings.ForceLowercaseTags = convBool(v, "DD_FORCE_LOWERCASE_TAGS environmental variable was not a boolean.")
+			gdConf.Settings.ForceLowercaseTags, err = convBool(v, "DD_FORCE_LOWERCASE_TAGS environmental variable was not a boolean.")
 		case "DD_HOST":
 			gdConf.Settings.Host = v
 		case "DD_INITIALIZE":
@@ -312,7 +369,7 @@ func readEnvVars(gdConf *dojoConfig) {
 			gdConf.Settings.LoginRedirectURL = v
 		case "DD_MAX_TAG_LENGTH":
 			// TODO: Look up maximum tag length in data model and check for that too
-			gdConf.Settings.MaxTagLength = convInt(v, "DD_MAX_TAG_LENGTH provided via environmental variable isn't a valid number")
+			gdConf.Settings.MaxTagLength, err = convInt(v, "DD_MAX_TAG_LENGTH provided via environmental variable isn't a valid number")
 		case "DD_MEDIA_ROOT":
 			gdConf.Settings.MediaRoot = v
 		case "DD_MEDIA_URL":
@@ -332,23 +389,23 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_SECRET_KEY":
 			gdConf.Settings.SecretKey = v
 		case "DD_SECURE_BROWSER_XSS_FILTER":
-			gdConf.Settings.SecureBrowserXSSFilter = convBool(v, "DD_SECURE_BROWSER_XSS_FILTER environmental variable was not a boolean.")
+			gdConf.Settings.SecureBrowserXSSFilter, err = convBool(v, "DD_SECURE_BROWSER_XSS_FILTER environmental variable was not a boolean.")
 		case "DD_SECURE_CONTENT_TYPE_NOSNIFF":
 			gdConf.Settings.SecureContentTypeNosniff = v
 		case "DD_SECURE_HSTS_INCLUDE_SUBDOMAINS":
-			gdConf.Settings.SecureHSTSIncludeSubdomains = convBool(v, "DD_SECURE_HSTS_INCLUDE_SUBDOMAINS environmental variable was not a boolean.")
+			gdConf.Settings.SecureHSTSIncludeSubdomains, err = convBool(v, "DD_SECURE_HSTS_INCLUDE_SUBDOMAINS environmental variable was not a boolean.")
 		case "DD_SECURE_HSTS_SECONDS":
-			gdConf.Settings.SecureHSTSSeconds = convInt(v, "DD_SECURE_HSTS_SECONDS provided via environmental variable isn't a valid number")
+			gdConf.Settings.SecureHSTSSeconds, err = convInt(v, "DD_SECURE_HSTS_SECONDS provided via environmental variable isn't a valid number")
 		case "DD_SECURE_PROXY_SSL_HEADER":
-			gdConf.Settings.SecureProxySSLHeader = convBool(v, "DD_SECURE_PROXY_SSL_HEADER environmental variable was not a boolean.")
+			gdConf.Settings.SecureProxySSLHeader, err = convBool(v, "DD_SECURE_PROXY_SSL_HEADER environmental variable was not a boolean.")
 		case "DD_SECURE_SSL_REDIRECT":
-			gdConf.Settings.SecureSSLRedirect = convBool(v, "DD_SECURE_SSL_REDIRECT environmental variable was not a boolean.")
+			gdConf.Settings.SecureSSLRedirect, err = convBool(v, "DD_SECURE_SSL_REDIRECT environmental variable was not a boolean.")
 		case "DD_SESSION_COOKIE_HTTPONLY":
-			gdConf.Settings.SessionCookieHTTPOnly = convBool(v, "DD_SESSION_COOKIE_HTTPONLY environmental variable was not a boolean.")
+			gdConf.Settings.SessionCookieHTTPOnly, err = convBool(v, "DD_SESSION_COOKIE_HTTPONLY environmental variable was not a boolean.")
 		case "DD_SESSION_COOKIE_SECURE":
-			gdConf.Settings.SessionCookieSecure = convBool(v, "DD_SESSION_COOKIE_SECURE environmental variable was not a boolean.")
+			gdConf.Settings.SessionCookieSecure, err = convBool(v, "DD_SESSION_COOKIE_SECURE environmental variable was not a boolean.")
 		case "DD_SITE_ID":
-			gdConf.Settings.SiteID = convInt(v, "DD_SITE_ID provided via environmental variable isn't a valid number")
+			gdConf.Settings.SiteID, err = convInt(v, "DD_SITE_ID provided via environmental variable isn't a valid number")
 		case "DD_SOCIAL_AUTH_AZUREAD_TENANT_OAUTH2_ENABLED":
 			gdConf.Settings.SocialAuthAzureadTenantOauth2Enabled = v
 		case "DD_SOCIAL_AUTH_AZUREAD_TENANT_OAUTH2_KEY":
@@ -386,15 +443,15 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_TIME_ZONE":
 			gdConf.Settings.TimeZone = v
 		case "DD_TRACK_MIGRATIONS":
-			gdConf.Settings.TrackMigrations = convBool(v, "DD_TRACK_MIGRATIONS environmental variable was not a boolean.")
+			gdConf.Settings.TrackMigrations, err = convBool(v, "DD_TRACK_MIGRATIONS environmental variable was not a boolean.")
 		case "DD_URL_PREFIX":
 			gdConf.Settings.URLPrefix = v
 		case "DD_USE_I18N":
-			gdConf.Settings.UseI18N = convBool(v, "DD_USE_I18N environmental variable was not a boolean.")
+			gdConf.Settings.UseI18N, err = convBool(v, "DD_USE_I18N environmental variable was not a boolean.")
 		case "DD_USE_L10N":
-			gdConf.Settings.UseL10N = convBool(v, "DD_USE_L10N environmental variable was not a boolean.")
+			gdConf.Settings.UseL10N, err = convBool(v, "DD_USE_L10N environmental variable was not a boolean.")
 		case "DD_USE_TZ":
-			gdConf.Settings.UseTZ = convBool(v, "DD_USE_TZ environmental variable was not a boolean.")
+			gdConf.Settings.UseTZ, err = convBool(v, "DD_USE_TZ environmental variable was not a boolean.")
 		case "DD_UUID":
 			gdConf.Settings.UUID = v
 		case "DD_UWSGI_ENDPOINT":
@@ -408,62 +465,77 @@ func readEnvVars(gdConf *dojoConfig) {
 		case "DD_UWSGI_PORT":
 			gdConf.Settings.UwsgiPort = v
 		case "DD_WHITENOISE":
-			gdConf.Settings.Whitenoise = convBool(v, "DD_WHITENOISE environmental variable was not a boolean.")
+			gdConf.Settings.Whitenoise, err = convBool(v, "DD_WHITENOISE environmental variable was not a boolean.")
 		case "DD_WKHTMLTOPDF":
 			gdConf.Settings.Wkhtmltopdf = v
 		case "DOJO_ADMIN_USER":
 			gdConf.Settings.DojoAdminUser = v
 			// TODO: Deprecate me
 		}
+		if err != nil {
+			return err
+		}
 	}
 
+	return nil
 }
 
-func convInt(i string, s string) int {
+func convInt(i string, s string) (int, error) {
 	convI, err := strconv.Atoi(i)
 	if err != nil {
-		fmt.Println("ERROR:")
-		fmt.Printf("  %s\n", s)
-		fmt.Printf("  Error was: %v\n", err)
-		os.Exit(1)
+		return 0, fmt.Errorf("%s\n  Error was: %w", s, err)
 	}
-	return convI
+	return convI, nil
 }
 
-func intLessThan(i int, max int, s string) {
+func intLessThan(i int, max int, s string) error {
 	if i > max {
-		fmt.Println("ERROR:")
-		fmt.Printf("  %s\n", s)
-		os.Exit(1)
+		return errors.New(s)
 	}
+	return nil
 }
 
-func convBool(b string, s string) bool {
+func convBool(b string, s string) (bool, error) {
 	res, err := strconv.ParseBool(b)
 	if err != nil {
-		fmt.Println("ERROR:")
-		fmt.Printf("  %s\n", s)
-		fmt.Println("  Valid values are 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.")
-		fmt.Printf("  Error was: %v\n", err)
-		os.Exit(1)
+		return false, fmt.Errorf("%s\n  Valid values are 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.\n  Error was: %w", s, err)
 	}
-	return res
+	return res, nil
 }
 
-// checkUserPrivs takes a pointer to DDConfig struct and verifies that the
-// user running godojo has sufficient privileges to complete the install and
-// exits with a 1 if privileges are lacking
-func checkUserPrivs(d *DDConfig) {
-	usr, err := user.Current()
-	if err != nil {
-		log.Fatal(err)
+// checkUserPrivs takes a pointer to DDConfig struct and why the commands godojo
+// will run need root and verifies that the user running godojo has sufficient
+// privileges to complete the install and exits with a 1 if privileges are
+// lacking.  Dry runs only print the commands so they don't need root.
+func checkUserPrivs(d *DDConfig, reason string) {
+	euid := os.Geteuid()
+	if euid == -1 {
+		// Platforms like Windows don't have an effective UID
+		d.traceMsg("Effective UID isn't available on this platform, skipping the privilege check")
+		return
 	}
-	if usr.Uid != "0" && !d.conf.Options.UsrInst {
-		fmt.Println("")
-		fmt.Println("##############################################################################")
-		fmt.Println("  ERROR: This program must be run as root or with sudo\n  Please correct and run installer again")
-		fmt.Println("##############################################################################")
-		fmt.Println("")
-		os.Exit(1)
+	d.traceMsg(fmt.Sprintf("Effective UID of godojo is %d", euid))
+	if euid == 0 {
+		return
 	}
+	if d.conf.Options.UsrInst || d.allowUnpriv {
+		d.traceMsg("Running without root as UsrInst or -allow-unprivileged is set")
+		return
+	}
+
+	if d.dryRun {
+		d.traceMsg("Dry runs don't run any commands, continuing as an unprivileged user")
+		return
+	}
+	fmt.Println("")
+	fmt.Println("##############################################################################")
+	fmt.Printf("  ERROR: This program must be run as root or with sudo %s\n", reason)
+	fmt.Println("  Please run the installer again with sudo, e.g. sudo ./godojo")
+	fmt.Println("  If godojo is already root-equivalent, e.g. in a container, use -allow-unprivileged")
+	fmt.Println("##############################################################################")
+	fmt.Println("")
+	os.Exit(1)
 }
+
+// installPrivReason is why an install needs root
+const installPrivReason = "to install OS packages and create the DefectDojo OS user"
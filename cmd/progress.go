@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"io"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+)
+
+// progress wraps the spinner so it can be replaced by plain status lines when
+// output isn't a terminal or -quiet is used, and silenced for dry runs
+type progress struct {
+	*spinner.Spinner
+	d       *DDConfig
+	plain   bool
+	stopped bool // Stop or Fail already ended this progress
+}
+
+// newSpinner returns a progress spinner with the provided prefix, the spinner's
+// output is discarded for dry runs so the commands listed are easily readable.
+// The spinner is colored like status messages unless colors are turned off.
+func (gd *DDConfig) newSpinner(p string) *progress {
+	// The spinner colors with fatih/color, which only checks for a terminal
+	color.NoColor = gd.noColor
+	s := spinner.New(spinner.CharSets[34], 100*time.Millisecond)
+	s.Prefix = p
+	if !gd.noColor {
+		_ = s.Color("green")
+	}
+	if gd.dryRun || gd.plain {
+		s.Writer = io.Discard
+	}
+	return &progress{Spinner: s, d: gd, plain: gd.plain && !gd.dryRun}
+}
+
+// Start starts the spinner or prints a status line when output is plain
+func (p *progress) Start() {
+	p.stopped = false
+	if p.plain {
+		p.d.statusMsg(p.Prefix)
+		return
+	}
+	p.Spinner.Start()
+}
+
+// Stop stops the spinner or prints a status line when output is plain
+func (p *progress) Stop() {
+	p.end(" done")
+}
+
+// Fail stops the spinner or prints a status line saying the step failed when
+// output is plain, it's used instead of Stop when the step returns an error
+func (p *progress) Fail() {
+	p.end(" failed")
+}
+
+// end stops the spinner or prints its prefix followed by s when output is
+// plain.  Only the first Stop or Fail after Start does anything so a step's
+// failure isn't reported twice.
+func (p *progress) end(s string) {
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	if p.plain {
+		p.d.statusMsg(p.Prefix + s)
+		return
+	}
+	p.Spinner.Stop()
+}
+
+// setPrefix updates the prefix of a running spinner or prints it as a status
+// line when output is plain
+func (p *progress) setPrefix(s string) {
+	p.Lock()
+	p.Prefix = s
+	p.Unlock()
+	if p.plain {
+		p.d.statusMsg(s)
+	}
+}
@@ -1,21 +1,55 @@
 package cmd
 
 import (
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
 	"strings"
 )
 
+// redactMark replaces every sensitive value written to the console and logs
+const redactMark = "***"
+
+// sensitiveFields is the registry of config values that are secrets.  Their
+// values are redacted from status and trace messages, the log files and the
+// OS commands echoed to the command output log along with their output.
+// Add any new password, token or key config value here.
+var sensitiveFields = []string{
+	"Install.DB.Rpass",
+	"Install.DB.Pass",
+	"Install.OS.Pass",
+	"Install.Admin.Pass",
+	"Install.GitToken",
+	"Install.GithubToken",
+	"Install.ReleasePass",
+	"Install.Broker.Pass",
+	"Install.GitSSHKeyPass",
+	"Settings.AdminPassword",
+	"Settings.CeleryBrokerPassword",
+	"Settings.DatabasePassword",
+	"Settings.SecretKey",
+	"Settings.CredentialAES256Key",
+	"Settings.SocialAuthAzureadTenantOauth2Key",
+	"Settings.SocialAuthAzureadTenantOauth2Secret",
+	"Settings.SocialAuthGoogleOauth2Key",
+	"Settings.SocialAuthGoogleOauth2Secret",
+	"Settings.SocialAuthOktaOauth2Key",
+	"Settings.SocialAuthOktaOauth2Secret",
+	"Options.ProxyPass",
+}
+
 // Redactatron - redacts sensitive information from being written to the logs
-// Redaction is configurable with Install's Redact boolean config.
-// If true (the default), sensitive info will be redacted
+// Redaction is configurable with Install's Redact boolean config or forced on
+// with -trace-redact.  If true (the default), sensitive info will be redacted
 func (d *DDConfig) redactatron(l string, on bool) string {
 	// Redact sensitive data if it's turned on
 	if on {
 		// Redact sensitive info from the files in ./logs/
 		clean := l
-		r := "[~REDACTED~]"
 		for i := range d.sensStr {
 			// Replacement will only be for redacted values
-			clean = strings.Replace(clean, d.sensStr[i], r, -1)
+			clean = strings.Replace(clean, d.sensStr[i], redactMark, -1)
 		}
 		return clean
 	}
@@ -24,31 +58,74 @@ func (d *DDConfig) redactatron(l string, on bool) string {
 
 // initRedact - sets up the data to be redacted by Redactatron
 func (d *DDConfig) initRedact() {
-	// Setup Default strings to redact
-	l := []string{
-		d.conf.Install.DB.Rpass,
-		d.conf.Install.DB.Pass,
-		d.conf.Install.OS.Pass,
-		d.conf.Install.Admin.Pass,
-		d.conf.Settings.CeleryBrokerPassword,
-		d.conf.Settings.DatabasePassword,
-		d.conf.Settings.SecretKey,
-		d.conf.Settings.CredentialAES256Key,
-		d.conf.Settings.SocialAuthGoogleOauth2Key,
-		d.conf.Settings.SocialAuthGoogleOauth2Secret,
-		d.conf.Settings.SocialAuthOktaOauth2Key,
-		d.conf.Settings.SocialAuthOktaOauth2Secret,
-	}
-
-	// Add the strings from DojoConfig to be redacted if they have content
+	d.redact = d.conf.Install.Redact || d.traceRedact
+
+	// Add the values from the registry of sensitive fields that have content
+	for _, f := range sensitiveFields {
+		v, _ := configValue(&d.conf, f)
+		d.addRedact(v)
+	}
+
+	// Celery.Env is free-form so any of its values could be a credential
+	for _, v := range d.conf.Install.Celery.Env {
+		d.addRedact(v)
+	}
+}
+
+// addRedact adds s to the strings redacted from the logs, along with the
+// URL-encoded forms it takes in database URLs and the like
+func (d *DDConfig) addRedact(s string) {
+	if len(s) == 0 {
+		return
+	}
+	for _, v := range []string{s, url.QueryEscape(s), url.PathEscape(s)} {
+		if !inList(d.sensStr, v) {
+			d.sensStr = append(d.sensStr, v)
+		}
+	}
+
+	// Longest first so a secret containing another is redacted whole
+	sort.SliceStable(d.sensStr, func(i, j int) bool { return len(d.sensStr[i]) > len(d.sensStr[j]) })
+}
+
+// configValue returns the string value of the config field at the dotted path
+// p, e.g. Install.DB.Pass, and false if there's no string field at p
+func configValue(c *Config, p string) (string, bool) {
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range strings.Split(p, ".") {
+		if v.Kind() != reflect.Struct {
+			return "", false
+		}
+		v = v.FieldByName(f)
+		if !v.IsValid() {
+			return "", false
+		}
+	}
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+
+	return v.String(), true
+}
+
+// inList returns true if s is in l
+func inList(l []string, s string) bool {
 	for i := range l {
-		if len(l[i]) > 0 {
-			d.sensStr = append(d.sensStr, l[i])
+		if l[i] == s {
+			return true
 		}
 	}
+
+	return false
 }
 
-func (d *DDConfig) addRedact(s string) {
-	// Add an additional string to redact from the logs
-	d.sensStr = append(d.sensStr, s)
+// redactWriter redacts the sensitive values from everything written through it
+type redactWriter struct {
+	d *DDConfig
+	w io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(r.w, r.d.redactatron(string(p), r.d.redact))
+	return len(p), err
 }
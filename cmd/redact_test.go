@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newRedactConfig returns a DDConfig with the DB password set to pass whose
+// messages and command output are all captured in the returned buffer
+func newRedactConfig(t *testing.T, pass string) (*DDConfig, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	d := &DDConfig{quiet: true, traceOn: true, ctx: context.Background()}
+	d.Trace = log.New(buf, "TRACE:   ", 0)
+	d.Info = log.New(buf, "INFO:    ", 0)
+	d.Warning = log.New(buf, "WARNING: ", 0)
+	d.Error = log.New(buf, "ERROR:   ", 0)
+	d.cmdLogger = log.New(redactWriter{d: d, w: buf}, "[godojo] # ", 0)
+	d.conf.Install.Redact = true
+	d.conf.Install.DB.Pass = pass
+	d.initRedact()
+
+	return d, buf
+}
+
+func TestSensitiveFieldsExist(t *testing.T) {
+	for _, f := range sensitiveFields {
+		if _, ok := configValue(&Config{}, f); !ok {
+			t.Errorf("Sensitive field %s isn't a string field in the config", f)
+		}
+	}
+}
+
+func TestRedactPasswordFromLogs(t *testing.T) {
+	pass := "Sup3r S3cret/pa$$"
+	d, buf := newRedactConfig(t, pass)
+
+	d.sectionMsg("Section with " + pass)
+	d.statusMsg("Status with " + pass)
+	d.traceMsg("Trace with " + pass)
+	d.warnMsg("Warning with " + pass)
+	d.errorMsg("Error with " + pass)
+	d.traceMsg("Database URL is postgres://dojo:" + url.QueryEscape(pass) + "@localhost/dojodb")
+	err := execCmd(d, d.cmdLogger, "echo 'output with "+pass+"'", "Unable to echo", 0)
+	if err != nil {
+		t.Fatalf("Unable to run the test command: %v", err)
+	}
+	err = tryCmd(d, "echo 'try output with "+pass+"'", "Unable to echo", false)
+	if err != nil {
+		t.Fatalf("Unable to run the test command: %v", err)
+	}
+	_, err = inspectCmd(d, "echo 'inspect output with "+pass+"'", "Unable to echo", false)
+	if err != nil {
+		t.Fatalf("Unable to run the test command: %v", err)
+	}
+
+	out := buf.String()
+	for _, s := range []string{pass, url.QueryEscape(pass), url.PathEscape(pass)} {
+		if strings.Contains(out, s) {
+			t.Errorf("Expected %q to be redacted from the logs, got:\n%s", s, out)
+		}
+	}
+	if !strings.Contains(out, "output with "+redactMark) {
+		t.Errorf("Expected the command output to contain %s in place of the password, got:\n%s", redactMark, out)
+	}
+}
+
+func TestRedactOffAndForced(t *testing.T) {
+	pass := "n0t-so-s3cret"
+	d, buf := newRedactConfig(t, pass)
+	d.conf.Install.Redact = false
+	d.initRedact()
+	d.statusMsg("Status with " + pass)
+	if !strings.Contains(buf.String(), pass) {
+		t.Errorf("Expected the password to be logged with Redact false, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	d.traceRedact = true
+	d.initRedact()
+	d.statusMsg("Status with " + pass)
+	if strings.Contains(buf.String(), pass) {
+		t.Errorf("Expected -trace-redact to redact the password with Redact false, got:\n%s", buf.String())
+	}
+}
+
+func TestRedactCeleryEnv(t *testing.T) {
+	d, buf := newRedactConfig(t, "")
+	d.conf.Install.Celery.Env = map[string]string{"BROKER_TOKEN": "t0k3n\"with\\quotes"}
+	d.initRedact()
+
+	d.traceMsg("Celery broker token is " + d.conf.Install.Celery.Env["BROKER_TOKEN"])
+	if strings.Contains(buf.String(), "t0k3n") {
+		t.Errorf("Expected the Celery.Env value to be redacted from the logs, got:\n%s", buf.String())
+	}
+	if got, want := string(renderCeleryEnv(d.conf.Install.Celery.Env)), `BROKER_TOKEN="t0k3n\"with\\quotes"`+"\n"; got != want {
+		t.Errorf("Expected the environment file %q, got %q", want, got)
+	}
+}
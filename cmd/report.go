@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Phase results recorded in the -report
+const (
+	resultCompleted = "completed" // The phase ran and succeeded
+	resultSkipped   = "skipped"   // The phase wasn't selected or an earlier run completed it
+	resultFailed    = "failed"    // The phase was running when the install stopped
+)
+
+// phaseResult is how a phase went in the -report
+type phaseResult struct {
+	Name     string  `json:"name"`               // Phase name
+	Result   string  `json:"result"`             // completed, skipped or failed
+	Started  string  `json:"started,omitempty"`  // When the phase started, "" if it was skipped
+	Finished string  `json:"finished,omitempty"` // When the phase completed or failed, "" if it was skipped
+	Seconds  float64 `json:"seconds"`            // How long the phase ran, 0 if it was skipped
+	Duration string  `json:"duration"`           // Seconds as a duration like 1m30s for people reading the report
+}
+
+// installReport is the summary of a run written to the path set with -report
+type installReport struct {
+	Status    string        `json:"status"`           // success, failed, interrupted or dry-run
+	ExitCode  int           `json:"exit_code"`        // Code godojo exited with
+	Error     string        `json:"error,omitempty"`  // First error of a failed run
+	Version   string        `json:"version"`          // DefectDojo release or the source branch, tag or commit installed
+	Source    bool          `json:"source_install"`   // true for a source install
+	Commit    string        `json:"commit,omitempty"` // Commit a source install checked out
+	Distro    string        `json:"distro"`           // Target OS, "" if godojo stopped before it was determined
+	Arch      string        `json:"arch"`             // CPU architecture of the target OS
+	DBEngine  string        `json:"db_engine"`        // Database DefectDojo was set up to use
+	Phases    []phaseResult `json:"phases"`           // Each install phase that was reached, in the order they ran
+	Completed []string      `json:"completed_phases"` // Names of the phases that ran and succeeded
+	Started   string        `json:"started"`          // When godojo started
+	Finished  string        `json:"finished"`         // When the report was written
+	Seconds   float64       `json:"seconds"`          // How long the whole run took
+	Godojo    string        `json:"godojo"`           // Version of godojo that did the run
+	Config    string        `json:"config"`           // Config file used for the run
+}
+
+// addPhaseResult records how the phase name went for the -report and
+// -metrics, start is when it started and is zero for a skipped phase
+func addPhaseResult(d *DDConfig, name string, result string, start time.Time) {
+	r := phaseResult{Name: name, Result: result, Duration: "0s"}
+	if !start.IsZero() {
+		end := time.Now()
+		took := end.Sub(start)
+		r.Started = start.UTC().Format(time.RFC3339Nano)
+		r.Finished = end.UTC().Format(time.RFC3339Nano)
+		r.Seconds = took.Seconds()
+		r.Duration = took.Round(time.Millisecond).String()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.phaseResults = append(d.phaseResults, r)
+}
+
+// writeReport takes a pointer to a DDConfig struct and the code godojo is
+// about to exit with and writes the summary of the run to the -report path
+// and the phase timings to the -metrics path, recording any phase still
+// running as failed.  Only the first call writes them so an interrupt during a
+// failed exit doesn't overwrite them.  Not being able to write either is
+// warned about but doesn't change the exit.
+func writeReport(d *DDConfig, code int) {
+	if len(d.report) == 0 && len(d.metrics) == 0 {
+		return
+	}
+	d.reportOnce.Do(func() {
+		if len(d.phase) > 0 {
+			addPhaseResult(d, d.phase, resultFailed, d.phaseStart)
+		}
+		if len(d.metrics) > 0 {
+			writeMetrics(d)
+		}
+		if len(d.report) == 0 {
+			return
+		}
+		r := newReport(d, code)
+		err := saveReport(d.report, r)
+		if err != nil {
+			d.warnMsg(fmt.Sprintf("Unable to write the install report, error was: %+v", err))
+			return
+		}
+		d.traceMsg(fmt.Sprintf("Install report written to %+v", d.report))
+	})
+}
+
+// newReport returns the summary of the run for a godojo exiting with code
+func newReport(d *DDConfig, code int) installReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	r := installReport{
+		Status:   "success",
+		ExitCode: code,
+		Error:    d.firstErr,
+		Version:  installVersion(d),
+		Source:   d.conf.Install.SourceInstall,
+		Commit:   d.commit,
+		Distro:   d.target.id,
+		Arch:     d.target.arch,
+		DBEngine: d.conf.Install.DB.Engine,
+		Phases:   append([]phaseResult{}, d.phaseResults...),
+		Started:  d.start.UTC().Format(time.RFC3339),
+		Finished: now.UTC().Format(time.RFC3339),
+		Seconds:  now.Sub(d.start).Seconds(),
+		Godojo:   d.ver,
+		Config:   d.cf,
+	}
+	if len(d.cfPath) > 0 {
+		r.Config = d.cfPath
+	}
+	switch {
+	case code == 130:
+		r.Status = "interrupted"
+	case code != 0:
+		r.Status = "failed"
+	case d.dryRun:
+		r.Status = "dry-run"
+	}
+	r.Completed = []string{}
+	for _, p := range r.Phases {
+		if p.Result == resultCompleted {
+			r.Completed = append(r.Completed, p.Name)
+		}
+	}
+
+	return r
+}
+
+// installVersion returns the DefectDojo release installed, or the source
+// commit, tag or branch for a source install
+func installVersion(d *DDConfig) string {
+	i := d.conf.Install
+	switch {
+	case !i.SourceInstall:
+		return i.Version
+	case len(i.SourceCommit) > 0:
+		return i.SourceCommit
+	case len(i.SourceTag) > 0:
+		return i.SourceTag
+	}
+
+	return i.SourceBranch
+}
+
+// saveReport writes the report r as JSON to the file at p, creating any
+// missing parent directories
+func saveReport(p string, r installReport) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ensureDir(filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(p, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write the install report %s: %w", p, err)
+	}
+
+	return nil
+}
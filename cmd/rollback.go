@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// rollbackStep undoes one change made by an install phase of this run
+type rollbackStep struct {
+	phase string       // Phase that made the change, "" outside of a phase
+	desc  string       // What undoing the change does, logged when it runs
+	fn    func() error // Undoes the change
+	done  func() error // Runs instead of fn once the install succeeds, may be nil
+}
+
+// addRollback records fn as undoing a change the running phase just made so
+// it's undone if the install fails later in this run.  desc describes what fn
+// does, e.g. "remove the extracted source at /opt/dojo/django-DefectDojo".
+// Nothing is recorded for dry runs as nothing was changed.
+func (d *DDConfig) addRollback(desc string, fn func() error) {
+	if d.dryRun {
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Recorded the rollback action to %+v", desc))
+	d.rollbacks = append(d.rollbacks, rollbackStep{phase: d.phase, desc: desc, fn: fn})
+}
+
+// addRollbackDone is addRollback with done run instead of fn once the install
+// succeeds, e.g. to remove a copy that was only kept so fn could put it back
+func (d *DDConfig) addRollbackDone(desc string, fn func() error, done func() error) {
+	if d.dryRun {
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Recorded the rollback action to %+v", desc))
+	d.rollbacks = append(d.rollbacks, rollbackStep{phase: d.phase, desc: desc, fn: fn, done: done})
+}
+
+// finishRollback takes a pointer to a DDConfig struct and, as the install
+// succeeded, forgets the changes recorded with addRollback after running the
+// done action of each so nothing kept for a rollback is left behind
+func finishRollback(d *DDConfig) {
+	rb := d.rollbacks
+	d.rollbacks = nil
+	for _, r := range rb {
+		if r.done == nil {
+			continue
+		}
+		err := r.done()
+		if err != nil {
+			d.warnMsg(fmt.Sprintf("Unable to clean up after the change to %s, error was: %+v", r.desc, err))
+		}
+	}
+}
+
+// exit rolls back the changes made by this run when code isn't 0 and then
+// exits godojo with code
+func (d *DDConfig) exit(code int) {
+	if code != 0 {
+		rollback(d)
+	}
+	writeReport(d, code)
+	os.Exit(code)
+}
+
+// rollback takes a pointer to a DDConfig struct and undoes the changes recorded
+// with addRollback in the reverse order they were made, logging each action.
+// Phases with a change undone are removed from the phase state so the next run
+// repeats them.  Nothing is undone with -no-rollback so a failed install can
+// be debugged as it was left.
+func rollback(d *DDConfig) {
+	rb := d.rollbacks
+	d.rollbacks = nil
+	if len(rb) == 0 {
+		return
+	}
+	if d.noRollback {
+		d.warnMsg("-no-rollback is set, the changes made by this run were left in place")
+		return
+	}
+
+	d.sectionMsg("Rolling back the changes made by this run")
+	var undone []string
+	for i := len(rb) - 1; i >= 0; i-- {
+		if len(rb[i].phase) > 0 {
+			d.statusMsg(fmt.Sprintf("Rolling back the %s phase: %s", rb[i].phase, rb[i].desc))
+			undone = append(undone, rb[i].phase)
+		} else {
+			d.statusMsg("Rolling back: " + rb[i].desc)
+		}
+		err := rb[i].fn()
+		if err != nil {
+			d.warnMsg(fmt.Sprintf("Unable to %s, error was: %+v", rb[i].desc, err))
+		}
+	}
+	unmarkPhases(d, undone)
+	d.statusMsg("Rollback complete, fix the error above and run godojo again")
+}
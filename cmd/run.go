@@ -6,56 +6,194 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// run takes a pointer to a DDConfig struct and installs DefectDojo with
+// install.  The install phases return their errors here so this is the one
+// place a failed install is reported, rolled back and exited with the exit
+// code for the category of the failure.
 func run(d *DDConfig) {
+	err := install(d)
+	if err != nil {
+		if d.spin != nil {
+			d.spin.Fail()
+		}
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		d.exit(exitCode(err))
+	}
+}
+
+// install takes a pointer to a DDConfig struct and runs each install phase in
+// order, returning the first error one of them hits
+func install(d *DDConfig) error {
 	// Print the install banner
 	if !(d.quiet || d.conf.Options.Embd) {
 		d.dojoBanner()
 	}
 
 	// Setup command logging
-	d.cmdLogger = setCmdLogging(d)
+	var err error
+	d.cmdLogger, err = setCmdLogging(d)
+	if err != nil {
+		return err
+	}
 
 	// Check embedded
-	embdCk(d)
+	done, err := embdCk(d)
+	if err != nil || done {
+		return err
+	}
 
 	// Check install OS
-	osTarget := checkOS(d)
+	osTarget, err := checkOS(d)
+	if err != nil {
+		return err
+	}
+	d.target = osTarget
+
+	// Make sure phases selected with -phase can run
+	err = checkPhaseDeps(d)
+	if err != nil {
+		return err
+	}
+
+	// Warn about a slow or unreachable package mirror before the OS package commands
+	if len(d.phases) == 0 || phaseSelected(d, phaseBootstrap) || phaseSelected(d, phaseOSPrep) || phaseSelected(d, phaseDBInstall) {
+		checkMirror(d, &osTarget)
+	}
+
+	// Start over if asked to, otherwise phases completed by an earlier run are skipped
+	if d.restart {
+		d.traceMsg("-restart set, running every install phase")
+		clearPhases(d)
+	}
 
-	// Bootstrap install
-	bootstrapInstall(d, &osTarget)
+	// Bootstrap install unless the OS dependencies are already provided
+	if d.skipBootstrap {
+		d.statusMsg("Skipping the bootstrap phase as requested by -skip-bootstrap")
+	} else {
+		err = runPhase(d, phaseBootstrap, func() error {
+			err := bootstrapInstall(d, &osTarget)
+			if err != nil {
+				return fmt.Errorf("Bootstrapping the installer failed: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Validate Python version, only needed to build the virtualenv for a subset of phases
+	if len(d.phases) == 0 || phaseSelected(d, phaseDjangoPrep) {
+		err = validPython(d)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Validate Python version
-	validPython(d)
+	// Stop a running DefectDojo before an upgrade replaces it
+	err = runMaintenance(d)
+	if err != nil {
+		return err
+	}
 
-	// Download DefectDojo release or source
-	downloadDojo(d)
+	phases := []struct {
+		name string
+		fn   func() error
+	}{
+		// Download DefectDojo release or source
+		{phaseDownload, func() error { return getDojo(d) }},
+		// Install OS packges need by DefectDojo
+		{phaseOSPrep, func() error { return prepOSForDojo(d, &osTarget) }},
+		// Install the pinned Node.js for the frontend build if one is configured
+		{phaseNode, func() error { return installNode(d, &osTarget) }},
+		// Create the OS user and group DefectDojo runs as
+		{phaseSvcUser, func() error { return createServiceUser(d, &osTarget) }},
+		// Install DB if needed
+		{phaseDBInstall, func() error { return installDB(d, &osTarget) }},
+		// Record what the OS packages installed so installs can be diffed
+		{phaseVersions, func() error { reportVersions(d, &osTarget); return nil }},
+		// Prepare the DB for DefectDojo
+		{phaseDBSetup, func() error { return setupDB(d, &osTarget) }},
+	}
+	for _, p := range phases {
+		err = runPhase(d, p.name, p.fn)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Install OS packges need by DefectDojo
-	prepOSForDojo(d, &osTarget)
+	// Verify DefectDojo can reach the DB it will use, even if the DB phases were
+	// skipped as the DB may have stopped since the earlier run
+	if len(d.phases) == 0 || phaseSelected(d, phaseDBSetup) {
+		err = verifyDBForDojo(d)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Install DB if needed
-	installDBForDojo(d, &osTarget)
+	phases = []struct {
+		name string
+		fn   func() error
+	}{
+		// Prepare for Django - virtenv, etc
+		// TODO Convert to Commandeer
+		{phaseDjangoPrep, func() error { return prepDjango(d, &osTarget) }},
+		// Create settings.py
+		{phaseSettings, func() error { return createSettings(d, &osTarget) }},
+		// Setup DefectDojo
+		{phaseSetupDojo, func() error { return setupDefectDojo(d, &osTarget) }},
+		// Label the installed files and ports once they all exist
+		{phaseSELinux, func() error { return applySELinux(d, &osTarget) }},
+		// Run DefectDojo as services
+		{phaseSystemd, func() error { return setupSystemd(d) }},
+		// Install the broker the background tasks are queued on
+		{phaseBroker, func() error { return setupBroker(d, &osTarget) }},
+		// Run DefectDojo's background tasks
+		{phaseCelery, func() error { return setupCelery(d) }},
+	}
+	for _, p := range phases {
+		err = runPhase(d, p.name, p.fn)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Prepare the DB for DefectDojo
-	prepDBForDojo(d, &osTarget)
+	// Start the services stopped for an upgrade now the new version is in place
+	endMaintenance(d)
 
-	// Prepare for Django - virtenv, etc
-	// TODO Convert to Commandeer
-	prepDjango(d, &osTarget)
+	// Make sure DefectDojo actually starts
+	err = runPhase(d, phaseHealth, func() error { return checkHealth(d) })
+	if err != nil {
+		return err
+	}
 
-	// Create settings.py
-	createSettings(d, &osTarget)
+	if len(d.phases) > 0 {
+		finishRollback(d)
+		d.statusMsg(fmt.Sprintf("\nSuccessfully ran the %s phases using godojo version %+v", strings.Join(d.phases, ", "), d.ver))
+		writeReport(d, 0)
+		return nil
+	}
 
-	// Setup DefectDojo
-	setupDefectDojo(d, &osTarget)
+	// Run any site-specific commands now everything is installed
+	err = runHooks(d, "post-install", d.conf.Install.Hooks.PostInstall)
+	if err != nil {
+		return err
+	}
 
+	// The install is complete so a re-run starts over
+	finishRollback(d)
+	finishPhases(d)
 	d.statusMsg(fmt.Sprintf("\nSuccessfully installed DefectDojo using godojo version %+v", d.ver))
+	writeReport(d, 0)
+
+	return nil
 }
 
-func setCmdLogging(d *DDConfig) *log.Logger {
+func setCmdLogging(d *DDConfig) (*log.Logger, error) {
 	// Setup OS command logging
 	d.traceMsg("Creating log file for OS command output for debugging reasons")
 	n := time.Now()
@@ -65,16 +203,10 @@ func setCmdLogging(d *DDConfig) *log.Logger {
 	// Create command output log file in the existing logging directory
 	cmdLogger, err := os.OpenFile(cmdPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
-		fmt.Println("")
-		fmt.Println("##############################################################################")
-		fmt.Printf("  ERROR: Failed to open OS Command log file %s.  Error was:\n    %+v\n", cmdPath, err)
-		fmt.Println("##############################################################################")
-		fmt.Println("")
-		fmt.Println("Log files are required for the install, exiting install")
-		os.Exit(1)
+		return nil, fmt.Errorf("Failed to open OS Command log file %s, log files are required for the install.  Error was:\n    %w", cmdPath, err)
 	}
 	//cmdLogger = cmdFile
 	d.traceMsg(fmt.Sprintf("Successfully created OS Command log file at %+v", cmdPath))
 
-	return log.New(cmdLogger, "[godojo] # ", log.Ldate|log.Ltime)
+	return log.New(redactWriter{d: d, w: d.teeWriter(cmdLogger)}, "[godojo] # ", log.Ldate|log.Ltime), nil
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// applySELinux takes a pointer to a DDConfig struct and a pointer to the target
+// OS struct and, on RHEL-family distros with SELinux enabled, labels the install
+// root and the ports DefectDojo binds so the service can start.  It's skipped
+// when SELinux is disabled or not installed, or Install.SELinux.Manage is false.
+func applySELinux(d *DDConfig, t *targetOS) error {
+	switch t.distro {
+	case "rhel", "amazon", "fedora":
+	default:
+		d.traceMsg(fmt.Sprintf("SELinux contexts are only set on RHEL-family distros, skipping for %+v", t.distro))
+		return nil
+	}
+
+	s := d.conf.Install.SELinux
+	if !s.Manage {
+		d.statusMsg("Install.SELinux.Manage is false, leaving SELinux contexts to the site's own policy")
+		return nil
+	}
+	mode := seLinuxMode(d)
+	if mode == "" || mode == "disabled" {
+		d.traceMsg("SELinux is disabled or not installed, no contexts to set")
+		return nil
+	}
+
+	d.sectionMsg("Setting SELinux contexts for DefectDojo")
+	d.traceMsg(fmt.Sprintf("SELinux is %+v", mode))
+	if _, err := exec.LookPath("semanage"); err != nil && !d.dryRun {
+		d.warnMsg("SELinux is " + mode + " but semanage wasn't found, install policycoreutils-python-utils " +
+			"and re-run with -restart to set the SELinux contexts for DefectDojo")
+		return nil
+	}
+
+	// Label the install root, then the writable files directory which needs its own type
+	err := sendCmd(d, d.cmdLogger, fcontextCmd(s.FileContext, d.conf.Install.Root),
+		"Unable to set the SELinux file context for "+d.conf.Install.Root, true)
+	if err != nil {
+		return err
+	}
+	files := filepath.Join(d.conf.Install.Root, d.conf.Install.Files)
+	err = sendCmd(d, d.cmdLogger, fcontextCmd(s.RWContext, files),
+		"Unable to set the SELinux file context for "+files, true)
+	if err != nil {
+		return err
+	}
+	err = sendCmd(d, d.cmdLogger, "restorecon -R "+d.conf.Install.Root,
+		"Unable to apply the SELinux file contexts to "+d.conf.Install.Root, true)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range seLinuxPorts(d) {
+		err = sendCmd(d, d.cmdLogger, portCmd(s.PortType, p),
+			fmt.Sprintf("Unable to set the SELinux port type for port %d", p), true)
+		if err != nil {
+			return err
+		}
+	}
+	if !d.dryRun {
+		d.statusMsg("SELinux contexts set for " + d.conf.Install.Root)
+	}
+
+	return nil
+}
+
+// seLinuxMode returns the SELinux mode from getenforce in lower case, or "" if
+// getenforce isn't installed or fails
+func seLinuxMode(d *DDConfig) string {
+	p, err := exec.LookPath("getenforce")
+	if err != nil {
+		d.traceMsg("getenforce wasn't found in PATH")
+		return ""
+	}
+	out, err := exec.CommandContext(d.ctx, p).Output()
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Running getenforce failed, error was: %+v", err))
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(string(out)))
+}
+
+// seLinuxPorts returns the ports to label from Install.SELinux.Ports plus the
+// uwsgi port from the settings, if set
+func seLinuxPorts(d *DDConfig) []int {
+	ports := append([]int{}, d.conf.Install.SELinux.Ports...)
+	if p, err := strconv.Atoi(d.conf.Settings.UwsgiPort); err == nil && p > 0 {
+		ports = append(ports, p)
+	}
+
+	return ports
+}
+
+// fcontextCmd returns the command to set the SELinux type of path and
+// everything under it, modifying the rule if one exists for path already
+func fcontextCmd(kind string, path string) string {
+	spec := "-t " + kind + " '" + path + "(/.*)?'"
+	return "semanage fcontext -a " + spec + " || semanage fcontext -m " + spec
+}
+
+// portCmd returns the command to set the SELinux type of the TCP port,
+// modifying the rule if the port is already labelled
+func portCmd(kind string, port int) string {
+	spec := "-t " + kind + " -p tcp " + strconv.Itoa(port)
+	return "semanage port -a " + spec + " || semanage port -m " + spec
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// verifySignature takes a pointer to a DDConfig struct, an http client, the URL
+// the release was downloaded from and the path to the downloaded tarball.  It
+// downloads the detached signature published next to the release as
+// <release>.asc and verifies it against the configured SigningKey.  A missing
+// signature is an error since verification was asked for.
+func verifySignature(d *DDConfig, cl *http.Client, dwnURL string, tarball string) error {
+	u := dwnURL + ".asc"
+	d.verboseMsg(fmt.Sprintf("Downloading release signature from %+v", u))
+	resp, err := getWithContext(d, cl, u)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Error downloading signature was: %+v", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	d.traceMsg(fmt.Sprintf("Status of signature download was %+v", resp.Status))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("VerifySignature is set but no signature could be downloaded from %s, status was %s", u, resp.Status)
+	}
+
+	// Limit the read, an armored detached signature is well under 64 KB
+	sig, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+
+	return checkSignature(d, tarball, bytes.NewReader(sig))
+}
+
+// verifyLocalSignature takes a pointer to a DDConfig struct and the path to a
+// pre-staged release tarball and verifies the <tarball>.asc detached signature
+// next to it against the configured SigningKey
+func verifyLocalSignature(d *DDConfig, tarball string) error {
+	sig, err := os.Open(tarball + ".asc")
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to open %+v.asc, error was: %+v", tarball, err))
+		return fmt.Errorf("VerifySignature is set but no signature was found at %s.asc", tarball)
+	}
+	defer sig.Close()
+
+	return checkSignature(d, tarball, sig)
+}
+
+// checkSignature returns an error unless sig is a valid armored detached
+// signature of the tarball made by a key in the configured SigningKey
+func checkSignature(d *DDConfig, tarball string, sig io.Reader) error {
+	k, err := os.Open(d.conf.Install.SigningKey)
+	if err != nil {
+		return fmt.Errorf("unable to read SigningKey %s: %w", d.conf.Install.SigningKey, err)
+	}
+	defer k.Close()
+	keyring, err := openpgp.ReadArmoredKeyRing(k)
+	if err != nil {
+		return fmt.Errorf("SigningKey %s isn't an armored PGP public key: %w", d.conf.Install.SigningKey, err)
+	}
+
+	tb, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer tb.Close()
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, tb, sig)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Signature check of %+v failed with: %+v", tarball, err))
+		return fmt.Errorf("GPG signature verification failed for %s: %w", tarball, err)
+	}
+
+	d.verboseMsg(fmt.Sprintf("Release tarball signed by key %s", signer.PrimaryKey.KeyIdString()))
+	for name := range signer.Identities {
+		d.traceMsg(fmt.Sprintf("Signing key identity is %s", name))
+	}
+	d.statusMsg("GPG signature of the release tarball verified")
+	return nil
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Install phases recorded in the phase state file as each one completes
+const (
+	phaseBootstrap   = "bootstrap"        // Bootstrap the installer's OS packages
+	phaseMaintenance = "maintenance"      // Stop the running DefectDojo services before -upgrade replaces them
+	phaseDownload    = "download"         // Download and extract the release or clone the source
+	phaseOSPrep      = "os-prep"          // Install the OS packages DefectDojo needs
+	phaseNode        = "node"             // Install the pinned Node.js version for the frontend build
+	phaseSvcUser     = "service-user"     // Create the DefectDojo OS user and group
+	phaseDBInstall   = "db-install"       // Install the database or its client
+	phaseVersions    = "versions"         // Record the installed versions of the key dependencies
+	phaseDBSetup     = "db-setup"         // Create the DefectDojo database and database user
+	phaseDjangoPrep  = "django-prep"      // Create the virtualenv and install the Python requirements
+	phaseSettings    = "settings"         // Create the DefectDojo settings
+	phaseSetupDojo   = "setup"            // Run the Django migrations and DefectDojo setup commands
+	phaseSELinux     = "selinux"          // Set the SELinux contexts for the install on RHEL-family distros
+	phaseSystemd     = "systemd"          // Create and enable the DefectDojo systemd units
+	phaseBroker      = "broker"           // Install and configure the celery broker, or check an external one
+	phaseCelery      = "celery"           // Set up the celery worker and beat with the configured broker
+	phaseHealth      = "health"           // Start DefectDojo and wait for it to answer HTTP requests
+	phaseComplete    = "complete"         // Recorded once every phase has run so -phase can re-run phases of a finished install
+	phaseStateHeader = "# godojo-phases " // Start of the first line of the state file, followed by the install key
+)
+
+// installPhase describes an install phase for -phase
+type installPhase struct {
+	name string   // Phase name
+	desc string   // What the phase does, shown in the help
+	deps []string // Phases that must run first, either selected with -phase or completed earlier
+}
+
+// installPhases are the install phases in the order they run
+var installPhases = []installPhase{
+	{phaseBootstrap, "Install the OS packages godojo needs", nil},
+	{phaseMaintenance, "Stop the running DefectDojo services before -upgrade replaces them", nil},
+	{phaseDownload, "Download and extract the release or clone the source", nil},
+	{phaseOSPrep, "Install the OS packages DefectDojo needs", []string{phaseBootstrap}},
+	{phaseNode, "Install the Install.Node.Version of Node.js for the frontend build", []string{phaseBootstrap}},
+	{phaseSvcUser, "Create the DefectDojo OS user and group", []string{phaseDownload}},
+	{phaseDBInstall, "Install the database or its client", []string{phaseBootstrap}},
+	{phaseVersions, "Record the installed versions of the key dependencies", nil},
+	{phaseDBSetup, "Create the DefectDojo database and database user", []string{phaseDBInstall}},
+	{phaseDjangoPrep, "Create the virtualenv and install the Python requirements", []string{phaseDownload, phaseOSPrep}},
+	{phaseSettings, "Create the DefectDojo settings", []string{phaseDownload, phaseDBSetup}},
+	{phaseSetupDojo, "Run the Django migrations and DefectDojo setup commands", []string{phaseDjangoPrep, phaseSettings}},
+	{phaseSELinux, "Set the SELinux contexts on RHEL-family distros", []string{phaseSetupDojo}},
+	{phaseSystemd, "Create and enable the DefectDojo systemd units", []string{phaseSvcUser, phaseSetupDojo}},
+	{phaseBroker, "Install Install.Broker.Engine as the celery broker, or check an external broker can be reached", []string{phaseBootstrap}},
+	{phaseCelery, "Set up the celery worker and beat as systemd units or supervisor programs", []string{phaseSvcUser, phaseSetupDojo}},
+	{phaseHealth, "Start DefectDojo and wait for it to answer HTTP requests", []string{phaseSystemd, phaseCelery}},
+}
+
+// parsePhases returns the comma separated phase names in s in the order they
+// run, or an error naming any phase that doesn't exist
+func parsePhases(s string) ([]string, error) {
+	want := map[string]bool{}
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if len(p) == 0 {
+			continue
+		}
+		if !knownPhase(p) {
+			return nil, fmt.Errorf("Unknown phase %q for -phase, it must be one or more of %s", p, strings.Join(phaseNames(), ", "))
+		}
+		want[p] = true
+	}
+	if len(want) == 0 {
+		return nil, fmt.Errorf("-phase needs at least one of %s", strings.Join(phaseNames(), ", "))
+	}
+
+	var phases []string
+	for _, p := range installPhases {
+		if want[p.name] {
+			phases = append(phases, p.name)
+		}
+	}
+	return phases, nil
+}
+
+// knownPhase returns true if name is an install phase
+func knownPhase(name string) bool {
+	for _, p := range installPhases {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// phaseNames returns the install phase names in the order they run
+func phaseNames() []string {
+	names := make([]string, 0, len(installPhases))
+	for _, p := range installPhases {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// phaseSelected returns true if name runs, i.e. -phase wasn't used or it lists name
+func phaseSelected(d *DDConfig, name string) bool {
+	if len(d.phases) == 0 {
+		return true
+	}
+	for _, p := range d.phases {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPhaseDeps takes a pointer to a DDConfig struct and returns an error
+// if a phase selected with -phase depends on a phase that isn't selected and
+// wasn't completed by an earlier run, e.g. django-prep without a download.
+// Dry runs only warn as nothing they'd depend on is changed.
+func checkPhaseDeps(d *DDConfig) error {
+	if len(d.phases) == 0 {
+		return nil
+	}
+	d.verboseMsg(fmt.Sprintf("Running only the phases %+v selected by -phase", d.phases))
+
+	complete := phaseDone(d, phaseComplete)
+	var missing []string
+	for _, p := range installPhases {
+		if !phaseSelected(d, p.name) {
+			continue
+		}
+		for _, dep := range p.deps {
+			if phaseSelected(d, dep) || complete || phaseDone(d, dep) {
+				continue
+			}
+			if dep == phaseBootstrap && d.skipBootstrap {
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("the %s phase needs the %s phase", p.name, dep))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	msg := "Phases selected with -phase depend on phases that haven't run: " + strings.Join(missing, ", ")
+	if d.dryRun {
+		d.warnMsg(msg)
+		return nil
+	}
+
+	return errors.New(msg + "\n  Add those phases to -phase or run the full install first")
+}
+
+// runPhase takes a pointer to a DDConfig struct, the phase name and a function
+// doing the work of that phase and runs it unless an earlier run already
+// completed it.  With -phase, only the selected phases run and they run even
+// if an earlier run completed them.  When fn returns an error it's returned
+// with d.phase left set so the report shows the phase as failed, otherwise the
+// phase is recorded in the state file.
+func runPhase(d *DDConfig, name string, fn func() error) error {
+	if !phaseSelected(d, name) {
+		d.verboseMsg(fmt.Sprintf("Skipping the %+v phase, it wasn't selected with -phase", name))
+		addPhaseResult(d, name, resultSkipped, time.Time{})
+		return nil
+	}
+	if len(d.phases) == 0 && !d.restart && phaseDone(d, name) {
+		d.statusMsg(fmt.Sprintf("Skipping the %s phase, it was completed by an earlier run (use -restart to run it again)", name))
+		addPhaseResult(d, name, resultSkipped, time.Time{})
+		return nil
+	}
+
+	d.phase = name
+	d.phaseStart = time.Now()
+	err := fn()
+	if err != nil {
+		return err
+	}
+	d.phase = ""
+	addPhaseResult(d, name, resultCompleted, d.phaseStart)
+	markPhase(d, name)
+
+	return nil
+}
+
+// phaseKey returns the config values a phase state file applies to so changing
+// the version or source between runs doesn't skip phases done for another install
+func phaseKey(d *DDConfig) string {
+	return strings.Join([]string{d.conf.Install.Version, fmt.Sprint(d.conf.Install.SourceInstall),
+		d.conf.Install.SourceBranch, d.conf.Install.SourceTag, d.conf.Install.SourceCommit,
+		d.conf.Install.DB.Engine}, "|")
+}
+
+// readPhases returns the phases recorded in the state file in Install.Root.
+// Nothing is returned if there is no state file or it is for a different install.
+func readPhases(d *DDConfig) []string {
+	b, err := os.ReadFile(filepath.Join(d.conf.Install.Root, d.phaseState))
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if lines[0] != phaseStateHeader+phaseKey(d) {
+		d.verboseMsg("Phase state is from an install with a different config, ignoring it")
+		return nil
+	}
+
+	return lines[1:]
+}
+
+// phaseDone returns true if the phase name was completed by an earlier run
+func phaseDone(d *DDConfig, name string) bool {
+	for _, p := range readPhases(d) {
+		if p == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markPhase records the phase name as completed in the state file in
+// Install.Root.  Failing to write it only means a re-run repeats that phase.
+func markPhase(d *DDConfig, name string) {
+	if d.dryRun {
+		return
+	}
+
+	p, err := writePhases(d, append(readPhases(d), name))
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to record the %s phase in %+v, error was: %+v", name, p, err))
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Recorded the %s phase as completed in %+v", name, p))
+}
+
+// unmarkPhases removes the phases in names from the state file in
+// Install.Root so the next run repeats them, e.g. after they were rolled back
+func unmarkPhases(d *DDConfig, names []string) {
+	if d.dryRun || len(names) == 0 {
+		return
+	}
+
+	var phases []string
+	for _, p := range readPhases(d) {
+		keep := true
+		for _, n := range names {
+			if p == n {
+				keep = false
+			}
+		}
+		if keep {
+			phases = append(phases, p)
+		}
+	}
+	p, err := writePhases(d, phases)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to remove the rolled back phases from %+v, error was: %+v", p, err))
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Removed the rolled back phases %+v from %+v", names, p))
+}
+
+// writePhases writes phases to the state file in Install.Root, returning its path
+func writePhases(d *DDConfig, phases []string) (string, error) {
+	p := filepath.Join(d.conf.Install.Root, d.phaseState)
+	err := os.MkdirAll(d.conf.Install.Root, 0755)
+	if err != nil {
+		return p, err
+	}
+	s := phaseStateHeader + phaseKey(d) + "\n"
+	if len(phases) > 0 {
+		s += strings.Join(phases, "\n") + "\n"
+	}
+
+	return p, os.WriteFile(p, []byte(s), 0644)
+}
+
+// finishPhases records that every phase has run, clearing the phases so the
+// next run starts over
+func finishPhases(d *DDConfig) {
+	clearPhases(d)
+	markPhase(d, phaseComplete)
+}
+
+// clearPhases removes the phase state file so the next run starts over
+func clearPhases(d *DDConfig) {
+	if d.dryRun {
+		return
+	}
+
+	p := filepath.Join(d.conf.Install.Root, d.phaseState)
+	err := os.Remove(p)
+	if err != nil && !os.IsNotExist(err) {
+		d.traceMsg(fmt.Sprintf("Unable to remove the phase state file %+v, error was: %+v", p, err))
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of OS account and what godojo found for them in the account state file
+const (
+	acctUser     = "user"
+	acctGroup    = "group"
+	acctCreated  = "created"  // godojo created the account so an uninstall can remove it
+	acctExisting = "existing" // The account was there before godojo so it's left alone
+)
+
+// createServiceUser takes a pointer to a DDConfig struct and a pointer to the
+// target OS struct and creates the non-login system group and user DefectDojo
+// runs as from Install.OS, skipping either one if it already exists.  The
+// install root is then chowned to that user and group.
+func createServiceUser(d *DDConfig, t *targetOS) error {
+	d.sectionMsg("Creating the DefectDojo service user")
+	o := d.conf.Install.OS
+
+	// Group first as the user's primary group must exist
+	g, err := user.LookupGroup(o.Group)
+	switch {
+	case err == nil:
+		d.statusMsg(fmt.Sprintf("OS group %s already exists", o.Group))
+		recordAccount(d, acctGroup, o.Group, acctExisting)
+		if o.GID > 0 && g.Gid != strconv.Itoa(o.GID) {
+			d.warnMsg(fmt.Sprintf("OS group %s has GID %s instead of the configured GID %d, leaving it as is", o.Group, g.Gid, o.GID))
+		}
+	default:
+		d.traceMsg(fmt.Sprintf("Looking up OS group %+v returned: %+v", o.Group, err))
+		err = sendCmd(d, d.cmdLogger, groupAddCmd(d), "Unable to create the DefectDojo OS group", true)
+		if err != nil {
+			return err
+		}
+		if !d.dryRun {
+			d.statusMsg(fmt.Sprintf("Created OS group %s", o.Group))
+		}
+		recordAccount(d, acctGroup, o.Group, acctCreated)
+		d.addRollback("remove the OS group "+o.Group+" created by this run", func() error {
+			err := execCmd(d, d.cmdLogger, "groupdel "+o.Group, "Unable to remove the DefectDojo OS group", 0)
+			if err == nil {
+				forgetAccount(d, acctGroup, o.Group)
+			}
+			return err
+		})
+	}
+
+	u, err := user.Lookup(o.User)
+	switch {
+	case err == nil:
+		d.statusMsg(fmt.Sprintf("OS user %s already exists", o.User))
+		recordAccount(d, acctUser, o.User, acctExisting)
+		if o.UID > 0 && u.Uid != strconv.Itoa(o.UID) {
+			d.warnMsg(fmt.Sprintf("OS user %s has UID %s instead of the configured UID %d, leaving it as is", o.User, u.Uid, o.UID))
+		}
+	default:
+		d.traceMsg(fmt.Sprintf("Looking up OS user %+v returned: %+v", o.User, err))
+		err = sendCmd(d, d.cmdLogger, userAddCmd(d, t), "Unable to create the DefectDojo OS user", true)
+		if err != nil {
+			return err
+		}
+		if !d.dryRun {
+			d.statusMsg(fmt.Sprintf("Created OS user %s with home %s", o.User, serviceHome(d)))
+		}
+		recordAccount(d, acctUser, o.User, acctCreated)
+		d.addRollback("remove the OS user "+o.User+" created by this run", func() error {
+			err := execCmd(d, d.cmdLogger, "userdel -r "+o.User, "Unable to remove the DefectDojo OS user", 0)
+			if err == nil {
+				forgetAccount(d, acctUser, o.User)
+			}
+			return err
+		})
+	}
+
+	d.traceMsg(fmt.Sprintf("Setting the owner of %+v to %+v:%+v", d.conf.Install.Root, o.User, o.Group))
+	return sendCmd(d, d.cmdLogger, "chown -R "+o.User+":"+o.Group+" "+d.conf.Install.Root,
+		"Unable to set file ownership for "+d.conf.Install.Root, true)
+}
+
+// groupAddCmd returns the command to create the DefectDojo system group,
+// using the configured GID if one is set
+func groupAddCmd(d *DDConfig) string {
+	cmd := "/usr/sbin/groupadd -r"
+	if d.conf.Install.OS.GID > 0 {
+		cmd += " -g " + strconv.Itoa(d.conf.Install.OS.GID)
+	}
+
+	return cmd + " " + d.conf.Install.OS.Group
+}
+
+// userAddCmd returns the command to create the DefectDojo system user with
+// the distro's nologin shell, using the configured UID if one is set
+func userAddCmd(d *DDConfig, t *targetOS) string {
+	cmd := "/usr/sbin/useradd -r -m -d " + serviceHome(d) + " -s " + noLoginShell(t) + " -g " + d.conf.Install.OS.Group
+	if d.conf.Install.OS.UID > 0 {
+		cmd += " -u " + strconv.Itoa(d.conf.Install.OS.UID)
+	}
+
+	return cmd + " " + d.conf.Install.OS.User
+}
+
+// serviceHome returns the home directory for the DefectDojo OS user which
+// defaults to /home/<user> when Install.OS.Home isn't set
+func serviceHome(d *DDConfig) string {
+	if len(d.conf.Install.OS.Home) > 0 {
+		return d.conf.Install.OS.Home
+	}
+
+	return "/home/" + d.conf.Install.OS.User
+}
+
+// noLoginShell returns the path of the nologin shell for the target distro
+func noLoginShell(t *targetOS) string {
+	switch t.distro {
+	case "ubuntu", "debian":
+		return "/usr/sbin/nologin"
+	case "rhel", "amazon", "fedora", "suse", "gentoo":
+		return "/sbin/nologin"
+	case "arch":
+		return "/usr/bin/nologin"
+	}
+
+	// Fall back to whichever exists
+	if _, err := os.Stat("/usr/sbin/nologin"); err == nil {
+		return "/usr/sbin/nologin"
+	}
+	return "/sbin/nologin"
+}
+
+// readAccounts returns the OS accounts recorded in the account state file in
+// Install.Root, keyed by "<kind> <name>" with whether godojo created them
+func readAccounts(d *DDConfig) map[string]string {
+	accts := make(map[string]string)
+	b, err := os.ReadFile(filepath.Join(d.conf.Install.Root, d.accountState))
+	if err != nil {
+		return accts
+	}
+	for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		f := strings.Fields(l)
+		if len(f) != 3 {
+			continue
+		}
+		accts[f[1]+" "+f[2]] = f[0]
+	}
+
+	return accts
+}
+
+// accountCreated returns true if the account state file records that godojo
+// created the OS account of kind with name
+func accountCreated(d *DDConfig, kind string, name string) bool {
+	return readAccounts(d)[kind+" "+name] == acctCreated
+}
+
+// recordAccount records in the account state file in Install.Root whether the
+// OS account of kind with name was created by godojo or already existed.  An
+// account an earlier run created stays recorded as created when a re-run finds
+// it.  Failing to write it only means an uninstall leaves the account in place.
+func recordAccount(d *DDConfig, kind string, name string, status string) {
+	if d.dryRun {
+		return
+	}
+
+	accts := readAccounts(d)
+	if status == acctExisting && accts[kind+" "+name] == acctCreated {
+		return
+	}
+	accts[kind+" "+name] = status
+	p, err := writeAccounts(d, accts)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to record the OS %s %s in %+v, error was: %+v", kind, name, p, err))
+		return
+	}
+	d.verboseMsg(fmt.Sprintf("Recorded the OS %s %s as %s in %+v", kind, name, status, p))
+}
+
+// forgetAccount removes the OS account of kind with name from the account
+// state file in Install.Root, e.g. after it was removed
+func forgetAccount(d *DDConfig, kind string, name string) {
+	if d.dryRun {
+		return
+	}
+
+	accts := readAccounts(d)
+	delete(accts, kind+" "+name)
+	p, err := writeAccounts(d, accts)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Unable to remove the OS %s %s from %+v, error was: %+v", kind, name, p, err))
+	}
+}
+
+// writeAccounts writes accts to the account state file in Install.Root,
+// removing the file once no accounts are left, and returns its path
+func writeAccounts(d *DDConfig, accts map[string]string) (string, error) {
+	p := filepath.Join(d.conf.Install.Root, d.accountState)
+	if len(accts) == 0 {
+		err := os.Remove(p)
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, err
+	}
+	err := os.MkdirAll(d.conf.Install.Root, 0755)
+	if err != nil {
+		return p, err
+	}
+
+	lines := make([]string, 0, len(accts))
+	for k, v := range accts {
+		lines = append(lines, v+" "+k)
+	}
+	sort.Strings(lines)
+
+	return p, os.WriteFile(p, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
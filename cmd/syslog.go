@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogFacilities maps the facility names accepted by -syslog-facility to
+// their syslog priority
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// syslogFacility returns the syslog priority for the facility name f
+func syslogFacility(f string) (syslog.Priority, error) {
+	p, ok := syslogFacilities[strings.ToLower(f)]
+	if !ok {
+		return 0, fmt.Errorf("Unsupported -syslog-facility of %q, it must be one of user, daemon, local0 - local7 or another syslog facility", f)
+	}
+
+	return p, nil
+}
+
+// openSyslog takes a pointer to a DDConfig struct and connects to the local
+// syslog daemon when -trace-to-syslog is set.  If syslog isn't available a
+// warning is shown and the install continues logging to the log files only.
+func openSyslog(d *DDConfig) error {
+	if !d.traceSyslog {
+		return nil
+	}
+	fac, err := syslogFacility(d.syslogFacility)
+	if err != nil {
+		return err
+	}
+
+	w, err := syslog.New(fac|syslog.LOG_DEBUG, d.syslogTag)
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("Unable to connect to syslog, continuing without it. Error was: %+v", err))
+		return nil
+	}
+	d.sysLog = w
+	d.traceMsg(fmt.Sprintf("Sending log messages to syslog with facility %+v and tag %+v", d.syslogFacility, d.syslogTag))
+
+	return nil
+}
+
+// syslogQuiet turns off the console output, including the spinner, when
+// -trace-to-syslog is set and connected so the install is only recorded in
+// syslog and the log files.  It's called when -quiet or Install.Quiet is set.
+func syslogQuiet(d *DDConfig) {
+	if d.sysLog == nil {
+		return
+	}
+	d.quiet = true
+	d.plain = true
+}
+
+// toSyslog sends the already redacted string s to syslog at the priority for
+// level.  Only trace messages are sent unless -syslog-all is set.
+func (gd *DDConfig) toSyslog(level string, s string) {
+	if gd.sysLog == nil || (level != "trace" && !gd.syslogAll) {
+		return
+	}
+
+	// Errors sending to syslog are ignored so they don't interrupt the install
+	switch level {
+	case "trace":
+		_ = gd.sysLog.Debug(s)
+	case "warning":
+		_ = gd.sysLog.Warning(s)
+	case "error":
+		_ = gd.sysLog.Err(s)
+	default:
+		_ = gd.sysLog.Info(s)
+	}
+}
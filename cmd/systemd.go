@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Handles the template-based generation of the systemd units that run DefectDojo
+
+// Define the default templates, each can be replaced with a template file
+// using the matching Install.Systemd setting
+const appUnit = `[Unit]
+Description=DefectDojo uwsgi application server
+After=network.target
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.Group}}
+WorkingDirectory={{.Source}}
+Environment=DJANGO_SETTINGS_MODULE=dojo.settings.settings
+EnvironmentFile=-{{.EnvFile}}
+ExecStart={{.Bin}}/uwsgi --{{.Mode}} {{.Endpoint}} --enable-threads --processes 2 --threads 2 --wsgi dojo.wsgi:application
+Restart=on-failure
+KillSignal=SIGQUIT
+
+[Install]
+WantedBy=multi-user.target
+`
+
+const workerUnit = `[Unit]
+Description=DefectDojo celery worker
+After=network.target
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.Group}}
+WorkingDirectory={{.Source}}
+Environment=DJANGO_SETTINGS_MODULE=dojo.settings.settings
+EnvironmentFile=-{{.EnvFile}}
+EnvironmentFile=-{{.CeleryEnvFile}}
+ExecStart={{.Bin}}/celery --app=dojo worker --loglevel={{.LogLevel}} --pool={{.Pool}} --concurrency={{.Concurrency}}
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+
+const beatUnit = `[Unit]
+Description=DefectDojo celery beat scheduler
+After=network.target
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.Group}}
+WorkingDirectory={{.Source}}
+Environment=DJANGO_SETTINGS_MODULE=dojo.settings.settings
+EnvironmentFile=-{{.EnvFile}}
+EnvironmentFile=-{{.CeleryEnvFile}}
+ExecStart={{.Bin}}/celery --app=dojo beat --loglevel={{.LogLevel}} --schedule={{.Root}}/celerybeat-schedule
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// unitVals holds the values substituted into the systemd unit templates
+type unitVals struct {
+	User          string // DefectDojo OS user
+	Group         string // DefectDojo OS group
+	Root          string // Install root
+	Source        string // Full path to the DefectDojo source
+	Bin           string // bin directory of DefectDojo's virtualenv
+	EnvFile       string // Full path to the .env.prod file used by settings.py
+	Mode          string // uwsgi socket type like http or socket
+	Endpoint      string // Address uwsgi listens on
+	LogLevel      string // Celery log level
+	Pool          string // Celery worker pool, solo for a single worker process
+	Concurrency   int    // Number of celery worker processes
+	CeleryEnvFile string // Full path to the EnvironmentFile with Celery.Env for the celery units
+	Env           string // Extra environment as a supervisor environment= value
+}
+
+// Names of the systemd units godojo creates
+const (
+	appUnitName    = "defectdojo.service"
+	workerUnitName = "defectdojo-celery-worker.service"
+	beatUnitName   = "defectdojo-celery-beat.service"
+)
+
+// unit is a systemd unit godojo creates
+type unit struct {
+	name     string // Unit file name
+	tmpl     string // Default template
+	override string // Path to a template file used instead of tmpl, if any
+}
+
+// setupSystemd takes a pointer to a DDConfig struct and creates the systemd
+// unit for the DefectDojo app from its template, verifies it with
+// systemd-analyze then enables it.  The celery worker and beat units are
+// created by setupCelery.  It's skipped when systemd isn't running or
+// Install.Systemd.Manage is false.
+func setupSystemd(d *DDConfig) error {
+	s := d.conf.Install.Systemd
+	if !s.Manage {
+		d.statusMsg("Install.Systemd.Manage is false, no systemd units will be created")
+		return nil
+	}
+	if _, err := os.Stat("/run/systemd/system"); err != nil && !d.dryRun {
+		d.warnMsg("systemd isn't running on this host, skipping creating the DefectDojo systemd units")
+		return nil
+	}
+
+	d.sectionMsg("Creating the DefectDojo systemd units")
+	paths, names, created, err := writeUnits(d, []unit{{name: appUnitName, tmpl: appUnit, override: s.AppTemplate}})
+	if len(created) > 0 {
+		d.addRollback("disable and remove the systemd units "+strings.Join(created, ", ")+" created by this run", func() error {
+			return removeUnits(d, created)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return enableUnits(d, paths, names)
+}
+
+// writeUnits renders each of units and writes it to Install.Systemd.UnitDir,
+// returning the paths and names of the units and the names of the ones that
+// didn't exist before.  An existing unit that's different, e.g. edited by
+// hand, is backed up first and put back if the install fails.  On an error
+// the units created so far are still returned so they can be removed.
+func writeUnits(d *DDConfig, units []unit) ([]string, []string, []string, error) {
+	vals := newUnitVals(d)
+	var paths, names, created []string
+	for _, u := range units {
+		b, err := renderUnit(u, vals)
+		if err != nil {
+			return paths, names, created, fmt.Errorf("Unable to create the systemd unit %s, error was: %w", u.name, err)
+		}
+		p := filepath.Join(d.conf.Install.Systemd.UnitDir, u.name)
+		paths = append(paths, p)
+		names = append(names, u.name)
+		if d.dryRun {
+			d.statusMsg("[dry-run] Would write the systemd unit " + p)
+			continue
+		}
+		old, err := os.ReadFile(p)
+		switch {
+		case os.IsNotExist(err):
+			created = append(created, u.name)
+		case err != nil:
+			return paths, names, created, fmt.Errorf("Unable to read the existing systemd unit %s, error was: %w", p, err)
+		case !bytes.Equal(old, b):
+			err = backupUnit(d, p, old)
+			if err != nil {
+				return paths, names, created, err
+			}
+		}
+		d.traceMsg(fmt.Sprintf("Writing systemd unit %+v", p))
+		err = os.WriteFile(p, b, 0644)
+		if err != nil {
+			return paths, names, created, fmt.Errorf("Unable to write the systemd unit %s, error was: %w", p, err)
+		}
+	}
+
+	return paths, names, created, nil
+}
+
+// unitBackupSuffix is added to the name of the backup of a replaced systemd unit
+const unitBackupSuffix = ".godojo-bak"
+
+// backupUnit saves the contents old of the existing systemd unit at p next to
+// it before it's replaced, putting it back if the install fails.  The backup is
+// kept after a successful install so hand edits can be copied over.
+func backupUnit(d *DDConfig, p string, old []byte) error {
+	bak := p + unitBackupSuffix
+	d.traceMsg(fmt.Sprintf("Backing up the existing systemd unit %+v to %+v", p, bak))
+	err := os.WriteFile(bak, old, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to back up the existing systemd unit %s, error was: %w", p, err)
+	}
+	d.statusMsg("Backed up the existing systemd unit " + p + " to " + bak)
+	d.addRollback("put back the systemd unit "+p+" replaced by this run", func() error {
+		err := os.Rename(bak, p)
+		if err != nil {
+			return err
+		}
+		return execCmd(d, d.cmdLogger, "systemctl daemon-reload", "Unable to reload the systemd configuration", 0)
+	})
+
+	return nil
+}
+
+// enableUnits verifies the unit files at paths then reloads systemd and
+// enables the units in names
+func enableUnits(d *DDConfig, paths []string, names []string) error {
+	err := verifyUnits(d, paths)
+	if err != nil {
+		return err
+	}
+	err = sendCmd(d, d.cmdLogger, "systemctl daemon-reload", "Unable to reload the systemd configuration", true)
+	if err != nil {
+		return err
+	}
+	err = sendCmd(d, d.cmdLogger, "systemctl enable "+strings.Join(names, " "), "Unable to enable the DefectDojo systemd units", true)
+	if err != nil {
+		return err
+	}
+	if !d.dryRun {
+		d.statusMsg("Enabled the systemd units " + strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// newUnitVals returns the values for the systemd unit templates from the config
+func newUnitVals(d *DDConfig) unitVals {
+	src := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+	v := unitVals{
+		User:     d.conf.Install.OS.User,
+		Group:    d.conf.Install.OS.Group,
+		Root:     d.conf.Install.Root,
+		Source:   src,
+		Bin:      filepath.Join(venvPath(d), "bin"),
+		EnvFile:  filepath.Join(src, d.conf.Install.App, "settings", ".env.prod"),
+		Mode:     d.conf.Settings.UwsgiMode,
+		Endpoint: d.conf.Settings.UwsgiEndpoint,
+		LogLevel: d.conf.Settings.CeleryLogLevel,
+	}
+	v.Concurrency = workerCount(d)
+	v.Pool = celeryPool(v.Concurrency)
+	v.CeleryEnvFile = filepath.Join(d.conf.Install.Root, celeryEnvFile)
+	if len(v.LogLevel) == 0 {
+		v.LogLevel = "INFO"
+	}
+	if len(v.Mode) == 0 {
+		v.Mode = "http"
+	}
+	if len(v.Endpoint) == 0 {
+		port := d.conf.Settings.UwsgiPort
+		if len(port) == 0 {
+			port = "8000"
+		}
+		v.Endpoint = "127.0.0.1:" + port
+	}
+
+	return v
+}
+
+// renderUnit returns the unit u with the values from v substituted into its
+// template, reading the template from u.override if one is set
+func renderUnit(u unit, v unitVals) ([]byte, error) {
+	text := u.tmpl
+	if len(u.override) > 0 {
+		b, err := os.ReadFile(u.override)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the template %s: %w", u.override, err)
+		}
+		text = string(b)
+	}
+	t, err := template.New(u.name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	err = t.Execute(&b, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// verifyUnits runs systemd-analyze verify on the unit files at paths,
+// returning any problems found so broken units aren't enabled
+func verifyUnits(d *DDConfig, paths []string) error {
+	if d.dryRun {
+		d.statusMsg("[dry-run] Would verify the systemd units with systemd-analyze verify")
+		return nil
+	}
+	p, err := exec.LookPath("systemd-analyze")
+	if err != nil {
+		d.warnMsg("systemd-analyze wasn't found, the DefectDojo systemd units weren't verified")
+		return nil
+	}
+
+	d.traceMsg(fmt.Sprintf("Verifying systemd units %+v", paths))
+	out, err := exec.CommandContext(d.ctx, p, append([]string{"verify"}, paths...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemd-analyze found problems with the DefectDojo systemd units:\n%s\n"+
+			"  Fix the units or the templates set in Install.Systemd then re-run godojo", strings.TrimSpace(string(out)))
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		d.warnMsg(fmt.Sprintf("systemd-analyze reported for the DefectDojo systemd units:\n%s", strings.TrimSpace(string(out))))
+	}
+
+	return nil
+}
+
+// removeUnits stops, disables and removes the DefectDojo systemd units in names
+func removeUnits(d *DDConfig, names []string) error {
+	// Disabling fails for units that were never enabled, which is fine
+	_ = execCmd(d, d.cmdLogger, "systemctl disable --now "+strings.Join(names, " "), "Unable to disable the DefectDojo systemd units", 0)
+	for _, n := range names {
+		err := os.Remove(filepath.Join(d.conf.Install.Systemd.UnitDir, n))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return execCmd(d, d.cmdLogger, "systemctl daemon-reload", "Unable to reload the systemd configuration", 0)
+}
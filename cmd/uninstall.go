@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// uninstallArgs holds the command-line options for the uninstall subcommand
+type uninstallArgs struct {
+	yes      bool // Skip the confirmation prompt
+	users    bool // Remove the OS user and group godojo created for DefectDojo
+	services bool // Stop the local database service
+	database bool // Drop the DefectDojo database and database user
+}
+
+// uninstall takes a pointer to a DDConfig struct and the arguments after the
+// uninstall subcommand and removes what a godojo install created based on the
+// same dojoConfig.yml used for the install
+func uninstall(d *DDConfig, args []string) {
+	var u uninstallArgs
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	fs.BoolVar(&u.yes, "yes", false, "Don't prompt for confirmation before removing anything")
+	fs.BoolVar(&u.users, "users", false, "Also remove the OS user and group if godojo created them")
+	fs.BoolVar(&u.services, "services", false, "Also stop the local database service")
+	fs.BoolVar(&u.database, "database", false, "Also drop the DefectDojo database and database user")
+	fs.StringVar(&d.cfPath, "config", "", "Path to the config file used for the install instead of ./dojoConfig.yml")
+	fs.BoolVar(&d.allowUnpriv, "allow-unprivileged", false, "Don't exit when godojo isn't run as root")
+	fs.StringVar(&d.logFile, "log-file", "", "Also write all log messages and command output to this file")
+	fs.Usage = printUninstallHelp
+	_ = fs.Parse(args)
+	err := openLogFile(d)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(d.cfPath) > 0 {
+		err = checkConfigPath(d.cfPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	// Read the same config used for the install
+	readConfigFile(d)
+	if err := readEnvVars(&d.conf); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	setDBEngine(d)
+	d.initRedact()
+	checkUserPrivs(d, "to remove the DefectDojo install")
+	d.cmdLogger, err = setCmdLogging(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v", err))
+		os.Exit(1)
+	}
+
+	d.sectionMsg("Uninstalling DefectDojo")
+	srcPath, tarball, err := uninstallPaths(d)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("%+v\n  Nothing was removed, fix the config used for the install and try again", err))
+		os.Exit(1)
+	}
+
+	units, supConf := installedServices(d)
+	venv, err := uninstallVenv(d)
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("%+v\n  The virtualenv will be kept, remove it by hand if it's no longer needed", err))
+	}
+
+	// List what will be removed and confirm
+	todo := []string{
+		"Remove the DefectDojo source at " + srcPath,
+		"Remove the release tarball at " + tarball,
+	}
+	if len(units) > 0 {
+		todo = append(todo, "Stop, disable and remove the systemd units "+strings.Join(units, ", "))
+	}
+	if len(supConf) > 0 {
+		todo = append(todo, "Remove the supervisor config "+supConf+" and stop its celery programs")
+	}
+	if len(venv) > 0 {
+		todo = append(todo, "Remove the virtualenv at "+venv)
+	}
+	if u.database {
+		todo = append(todo, fmt.Sprintf("Drop the %s database %s and database user %s",
+			d.conf.Install.DB.Engine, d.conf.Install.DB.Name, d.conf.Install.DB.User))
+	}
+	if u.services {
+		todo = append(todo, fmt.Sprintf("Stop the local %s service", d.conf.Install.DB.Engine))
+	}
+	if u.users && accountCreated(d, acctUser, d.conf.Install.OS.User) {
+		todo = append(todo, "Remove the OS user "+d.conf.Install.OS.User+" and its home directory")
+	}
+	if u.users && accountCreated(d, acctGroup, d.conf.Install.OS.Group) {
+		todo = append(todo, "Remove the OS group "+d.conf.Install.OS.Group)
+	}
+	d.statusMsg("The uninstall will:")
+	for i := range todo {
+		d.statusMsg("  - " + todo[i])
+	}
+	if !u.yes && !confirm("Continue with the uninstall?") {
+		d.statusMsg("Uninstall cancelled, nothing was removed")
+		os.Exit(0)
+	}
+
+	// The database goes first as dropping it doesn't need the source
+	if u.database {
+		dropDojoDB(d)
+	}
+	if u.services {
+		stopDBService(d)
+	}
+
+	// The services run from the source so they're stopped before it's removed
+	if len(units) > 0 {
+		d.traceMsg(fmt.Sprintf("Removing the systemd units %+v", units))
+		err = removeUnits(d, units)
+		if err != nil {
+			d.errorMsg(fmt.Sprintf("Unable to remove the systemd units, error was: %+v", err))
+		} else {
+			d.statusMsg("Removed the systemd units " + strings.Join(units, ", "))
+		}
+	}
+	if len(supConf) > 0 {
+		removePath(d, supConf)
+		sendCmd(d, d.cmdLogger, "supervisorctl update", "Unable to stop the celery supervisor programs", false)
+	}
+
+	removePath(d, srcPath)
+	if len(venv) > 0 {
+		removePath(d, venv)
+	}
+	removePath(d, tarball)
+	removePath(d, tarball+".part")
+	removePath(d, filepath.Join(d.conf.Install.Root, celeryEnvFile))
+	removePath(d, filepath.Join(d.conf.Install.Root, d.extractState))
+	removePath(d, filepath.Join(d.conf.Install.Root, d.phaseState))
+
+	// The user goes first as the group is its primary group
+	if u.users {
+		removeAccount(d, acctUser, d.conf.Install.OS.User)
+		removeAccount(d, acctGroup, d.conf.Install.OS.Group)
+	}
+
+	d.statusMsg("Successfully uninstalled DefectDojo")
+}
+
+// uninstallPaths returns the DefectDojo source directory and release tarball
+// an uninstall removes, or an error if Root or Source would have it remove
+// something other than the install since the config isn't otherwise validated
+func uninstallPaths(d *DDConfig) (string, string, error) {
+	err := checkRoot(d.conf.Install.Root)
+	if err != nil {
+		return "", "", err
+	}
+	err = checkSource(d.conf.Install.Source)
+	if err != nil {
+		return "", "", err
+	}
+
+	src := filepath.Join(d.conf.Install.Root, d.conf.Install.Source)
+
+	return src, filepath.Join(d.conf.Install.Root, "dojo-v"+uninstallVersion(d, src)+".tar.gz"), nil
+}
+
+// uninstallVersion returns the release whose tarball an uninstall removes.
+// Version: latest is the release installed at src, or is looked up the same
+// way the install did if there's no install to read the version from.
+func uninstallVersion(d *DDConfig, src string) string {
+	if d.conf.Install.Version != latestVersion {
+		return d.conf.Install.Version
+	}
+	if v := installedVersion(d, src); len(v) > 0 {
+		return strings.TrimPrefix(v, "v")
+	}
+	err := resolveVersion(d)
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("Unable to find the release installed for Version: %s, the release tarball may be left in %s: %+v",
+			latestVersion, d.conf.Install.Root, err))
+	}
+
+	return d.conf.Install.Version
+}
+
+// installedServices returns the names of the DefectDojo systemd units in
+// Install.Systemd.UnitDir and the path of the celery supervisor config, or ""
+// if there isn't one, that an uninstall removes
+func installedServices(d *DDConfig) ([]string, string) {
+	var units []string
+	for _, u := range dojoUnits {
+		if _, err := os.Stat(filepath.Join(d.conf.Install.Systemd.UnitDir, u)); err == nil {
+			units = append(units, u)
+		}
+	}
+	p := filepath.Join(d.conf.Install.Celery.SupervisorDir, supervisorConf)
+	if _, err := os.Stat(p); err != nil {
+		p = ""
+	}
+
+	return units, p
+}
+
+// uninstallVenv returns the virtualenv an uninstall removes when VenvPath puts
+// it outside of Install.Root, "" if it's in Root, or an error if VenvPath
+// would have it remove something other than the virtualenv
+func uninstallVenv(d *DDConfig) (string, error) {
+	if len(d.conf.Install.VenvPath) == 0 {
+		return "", nil
+	}
+	err := checkVenvPath(d.conf.Install.VenvPath)
+	if err != nil {
+		return "", err
+	}
+	v := venvPath(d)
+	if rel, err := filepath.Rel(filepath.Clean(d.conf.Install.Root), v); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		d.traceMsg(fmt.Sprintf("VenvPath %+v is inside of Install.Root, not removing it", v))
+		return "", nil
+	}
+
+	return v, nil
+}
+
+// removePath removes the file or directory at p if it exists
+func removePath(d *DDConfig, p string) {
+	_, err := os.Stat(p)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Nothing to remove at %+v", p))
+		return
+	}
+	d.traceMsg(fmt.Sprintf("Removing %+v", p))
+	err = os.RemoveAll(p)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("Unable to remove %s, error was: %+v", p, err))
+		return
+	}
+	d.statusMsg("Removed " + p)
+}
+
+// removeAccount removes the OS account of kind with name, and the home
+// directory of a user, only if the account state file in Install.Root records
+// that godojo created it.  An account that was already there or has ID 0 is
+// left in place.
+func removeAccount(d *DDConfig, kind string, name string) {
+	if !accountCreated(d, kind, name) {
+		d.warnMsg(fmt.Sprintf("OS %s %s wasn't created by godojo, leaving it in place", kind, name))
+		return
+	}
+
+	id, err := accountID(kind, name)
+	if err != nil {
+		d.traceMsg(fmt.Sprintf("Looking up OS %+v %+v returned: %+v", kind, name, err))
+		d.statusMsg(fmt.Sprintf("OS %s %s no longer exists", kind, name))
+		forgetAccount(d, kind, name)
+		return
+	}
+	if id == "0" {
+		d.errorMsg(fmt.Sprintf("OS %s %s has ID 0, refusing to remove it", kind, name))
+		return
+	}
+
+	cmd := "groupdel " + name
+	if kind == acctUser {
+		cmd = "userdel -r " + name
+	}
+	d.traceMsg(fmt.Sprintf("Removing OS %+v %+v", kind, name))
+	err = execCmd(d, d.cmdLogger, cmd, "Unable to remove the DefectDojo OS "+kind, 0)
+	if err != nil {
+		d.errorMsg(fmt.Sprintf("Unable to remove the OS %s %s, error was: %+v", kind, name, err))
+		return
+	}
+	forgetAccount(d, kind, name)
+	if !d.dryRun {
+		d.statusMsg(fmt.Sprintf("Removed the OS %s %s", kind, name))
+	}
+}
+
+// accountID returns the UID or GID of the OS account of kind with name
+func accountID(kind string, name string) (string, error) {
+	if kind == acctUser {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return "", err
+	}
+
+	return g.Gid, nil
+}
+
+// dropDojoDB drops the configured DefectDojo database and database user
+func dropDojoDB(d *DDConfig) {
+	if !d.conf.Install.DB.Local {
+		d.warnMsg("Database is remote, godojo won't drop remote databases")
+		return
+	}
+
+	d.traceMsg(fmt.Sprintf("Dropping database %+v and user %+v", d.conf.Install.DB.Name, d.conf.Install.DB.User))
+	switch d.conf.Install.DB.Engine {
+	case "PostgreSQL":
+		sendCmd(d, d.cmdLogger, "sudo -u postgres psql -c 'DROP DATABASE IF EXISTS \""+d.conf.Install.DB.Name+"\";'",
+			"Unable to drop the DefectDojo database", false)
+		sendCmd(d, d.cmdLogger, "sudo -u postgres psql -c 'DROP USER IF EXISTS \""+d.conf.Install.DB.User+"\";'",
+			"Unable to drop the DefectDojo database user", false)
+	case "MySQL", "MariaDB":
+		sendCmd(d, d.cmdLogger, "mysql --user=\""+d.conf.Install.DB.Ruser+"\" --password=\""+d.conf.Install.DB.Rpass+"\" "+
+			"-e 'DROP DATABASE IF EXISTS `"+d.conf.Install.DB.Name+"`; DROP USER IF EXISTS \""+d.conf.Install.DB.User+"\"@\"localhost\";'",
+			"Unable to drop the DefectDojo database", false)
+	default:
+		d.warnMsg(fmt.Sprintf("Dropping a %s database isn't supported, skipping", d.conf.Install.DB.Engine))
+	}
+}
+
+// stopDBService stops the local database service
+func stopDBService(d *DDConfig) {
+	if !d.conf.Install.DB.Local {
+		d.traceMsg("Database is remote, no local database service to stop")
+		return
+	}
+
+	d.traceMsg(fmt.Sprintf("Stopping the local %+v service", d.conf.Install.DB.Engine))
+	switch d.conf.Install.DB.Engine {
+	case "PostgreSQL":
+		sendCmd(d, d.cmdLogger, "service postgresql stop", "Unable to stop the PostgreSQL service", false)
+	case "MySQL", "MariaDB":
+		sendCmd(d, d.cmdLogger, "service mysql stop || service mariadb stop", "Unable to stop the MySQL service", false)
+	default:
+		d.warnMsg(fmt.Sprintf("Stopping a %s service isn't supported, skipping", d.conf.Install.DB.Engine))
+	}
+}
+
+// printUninstallHelp prints the help for the uninstall subcommand to stdout
+func printUninstallHelp() {
+	fmt.Println("")
+	fmt.Println("Usage of godojo uninstall")
+	fmt.Println("")
+	fmt.Println("./godojo uninstall [optional arguments]")
+	fmt.Println("")
+	fmt.Println("  Removes the DefectDojo source and release tarball under Install.Root, the DefectDojo")
+	fmt.Println("  systemd units, the celery supervisor config and a VenvPath outside of Install.Root using")
+	fmt.Println("  the dojoConfig.yml in the current working directory")
+	fmt.Println("  -allow-unprivileged")
+	fmt.Println("        OPTIONAL - Don't exit when godojo isn't run as root or with sudo")
+	fmt.Println("  -config=/path/to/dojoConfig.yml")
+	fmt.Println("        OPTIONAL - Use the config file at the path provided instead of ./dojoConfig.yml")
+	fmt.Println("  -log-file=/path/to/godojo.log")
+	fmt.Println("        OPTIONAL - Also write every log message and all OS command output to the file provided")
+	fmt.Println("  -yes")
+	fmt.Println("        OPTIONAL - Don't prompt for confirmation before removing anything")
+	fmt.Println("  -users")
+	fmt.Println("        OPTIONAL - Also remove the OS user and group if godojo created them, an existing")
+	fmt.Println("        account it reused is left in place")
+	fmt.Println("  -services")
+	fmt.Println("        OPTIONAL - Also stop the local database service")
+	fmt.Println("  -database")
+	fmt.Println("        OPTIONAL - Also drop the DefectDojo database and database user")
+	fmt.Println("")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"archive/tar"
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"errors"
 	"fmt"
@@ -12,28 +13,42 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic bytes used to detect the compression of a release tarball
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic  = []byte("ustar") // Found at offset 257 of an uncompressed tar header
+)
+
+const (
+	tarMagicOff = 257             // Offset of the ustar magic in a tar header
+	tarMagicEnd = tarMagicOff + 5 // Bytes to peek at to detect the format
 )
 
 // untar takes a pointer to a DDConfig struct, destination path and a reader;
 // a tar reader loops over the tarfile creating the file structure at 'dst'
 // along the way, and writing any files
 // Based on https://medium.com/@skdomino/taring-untaring-files-in-go-6b07cf56bc07
-func untar(d *DDConfig, dst string, r io.Reader) error {
+func untar(d *DDConfig, dst string, r io.Reader) (err error) {
 
-	// Setup new gzip Reader to extract tarball contents
-	gzr, err := gzip.NewReader(r)
+	// Setup a Reader for the tarball's compression to extract its contents
+	zr, err := decompress(r)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		err := gzr.Close()
-		if err != nil {
-			d.errorMsg(fmt.Sprintf("Unable to close the gzip reader\nError was %v", err))
-			os.Exit(1)
+		cerr := zr.Close()
+		if cerr != nil && err == nil {
+			err = fmt.Errorf("unable to close the decompression reader: %w", cerr)
 		}
 	}()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(zr)
 
 	// Loop through the file reading each header to determine if its a file or directory
 	// then either create the directory (if needed) or create the file
@@ -52,14 +67,20 @@ func untar(d *DDConfig, dst string, r io.Reader) error {
 			continue
 		}
 
-		// the target location where the dir/file should be created
-		target := filepath.Join(dst, header.Name)
+		// the target location where the dir/file should be created, which must
+		// stay inside of dst to prevent path traversal aka zip-slip
+		target, err := safeJoin(dst, header.Name)
+		if err != nil {
+			return err
+		}
 
 		// check the file type
 		switch header.Typeflag {
 		// if its a dir and it doesn't exist create it
 		case tar.TypeDir:
-			// TODO: Reformat me
+			if err := checkLinks(dst, target); err != nil {
+				return err
+			}
 			if _, err := os.Stat(target); err != nil {
 				if err := os.MkdirAll(target, 0755); err != nil {
 					return err
@@ -68,14 +89,21 @@ func untar(d *DDConfig, dst string, r io.Reader) error {
 
 		// if it's a file create it
 		case tar.TypeReg:
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			if err := checkLinks(dst, filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			// O_NOFOLLOW stops an earlier symlink entry with this name redirecting the write
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|syscall.O_NOFOLLOW, os.FileMode(header.Mode))
 			if err != nil {
 				return err
 			}
 
 			// copy over contents
-			// TODO: Reformat me
 			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
 				return err
 			}
 
@@ -85,23 +113,189 @@ func untar(d *DDConfig, dst string, r io.Reader) error {
 			if err != nil {
 				return err
 			}
+
+		// if it's a symlink, make sure it doesn't point outside of dst
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("tar entry %s is a symlink to the absolute path %s", header.Name, header.Linkname)
+			}
+			if _, err := safeJoin(dst, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err != nil {
+				return fmt.Errorf("tar entry %s is a symlink outside of %s", header.Name, dst)
+			}
+			if err := checkLinks(dst, filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			// The link's path is inside dst but it may go through earlier links that aren't
+			if err := checkLinks(dst, target); err != nil {
+				_ = os.Remove(target)
+				return err
+			}
+
+		// if it's a hard link, make sure it doesn't point outside of dst
+		case tar.TypeLink:
+			src, err := safeJoin(dst, header.Linkname)
+			if err != nil {
+				return fmt.Errorf("tar entry %s is a hard link outside of %s", header.Name, dst)
+			}
+			if err := checkLinks(dst, filepath.Dir(src)); err != nil {
+				return err
+			}
+			if err := checkLinks(dst, filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Link(src, target); err != nil {
+				return err
+			}
 		}
 	}
 }
 
-func embdCk(d *DDConfig) {
-	// Check options after logging is turned on
-	if d.conf.Options.Embd {
-		d.quiet = true
-		err := extr(d)
+// safeJoin joins the tar entry name n to dst returning an error if n is an
+// absolute path or the result would be outside of dst
+func safeJoin(dst string, n string) (string, error) {
+	if filepath.IsAbs(n) {
+		return "", fmt.Errorf("tar entry %s is an absolute path", n)
+	}
+	target := filepath.Join(dst, n)
+	rel, err := filepath.Rel(filepath.Clean(dst), target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("tar entry %s is outside of %s", n, dst)
+	}
+
+	return target, nil
+}
+
+// checkLinks returns an error if any existing part of the path p below dst is
+// a symlink that resolves outside of dst.  safeJoin only checks the entry's
+// name so without this a chain of symlink entries could have later entries
+// written through them to anywhere.  Nothing is created, checking stops at the
+// first part of p that doesn't exist yet as there's no link below it.
+func checkLinks(dst string, p string) error {
+	root, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(filepath.Clean(dst), filepath.Clean(p))
+	if err != nil || rel == "." {
+		return err
+	}
+	cur := filepath.Clean(dst)
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		cur = filepath.Join(cur, part)
+		fi, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(cur)
+		if os.IsNotExist(err) {
+			// A dangling link can't be written through, MkdirAll and OpenFile fail on it
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if r, err := filepath.Rel(root, resolved); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("tar entry path %s goes through the symlink %s which is outside of %s", p, cur, dst)
+		}
+	}
+
+	return nil
+}
+
+// decompress returns a reader for the uncompressed contents of the tarball in
+// r, using the file's magic bytes to pick gzip, zstd or no decompression
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReaderSize(r, tarMagicEnd)
+	b, err := br.Peek(tarMagicEnd)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(b, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(b, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case len(b) == tarMagicEnd && bytes.HasPrefix(b[tarMagicOff:], tarMagic):
+		return io.NopCloser(br), nil
+	}
+
+	return nil, fmt.Errorf("unrecognized tarball format, expected a gzip or zstd compressed or plain tar file")
+}
+
+// tarTopDir reads the tarball from r and returns the name of the single
+// directory every entry is under, like django-DefectDojo-2.30.0.  An empty
+// string is returned if the entries don't share exactly one top-level directory.
+func tarTopDir(r io.Reader) (string, error) {
+	zr, err := decompress(r)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	top := ""
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return top, nil
+		}
 		if err != nil {
-			fmt.Printf("Configuration has Embd = %v but no embedded files available\n", d.conf.Options.Embd)
-			os.Exit(1)
+			return "", err
+		}
+
+		// GitHub tarballs start with a global header holding the commit ID
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		name := strings.TrimPrefix(filepath.ToSlash(header.Name), "./")
+		first, rest, _ := strings.Cut(name, "/")
+		if len(first) == 0 || (len(rest) == 0 && header.Typeflag != tar.TypeDir) {
+			// A file at the top of the tarball means there's no single top-level directory
+			return "", nil
+		}
+		if len(top) > 0 && first != top {
+			return "", nil
 		}
-		os.Exit(0)
+		top = first
 	}
 }
 
+// embdCk runs the embedded files when Options.Embd is set, returning true as
+// they replace the install
+func embdCk(d *DDConfig) (bool, error) {
+	// Check options after logging is turned on
+	if !d.conf.Options.Embd {
+		return false, nil
+	}
+	d.quiet = true
+	err := extr(d)
+	if err != nil {
+		return true, fmt.Errorf("Configuration has Embd = %v but no embedded files available: %w", d.conf.Options.Embd, err)
+	}
+
+	return true, nil
+}
+
 func extr(d *DDConfig) error {
 	// Check for non-existent tempdir and set to default location if needed
 	// TODO: Create a function to create a directory or fail gracefully
@@ -115,9 +309,7 @@ func extr(d *DDConfig) error {
 	f, err := embd.ReadFile(loc)
 	if err != nil {
 		// Embedded file was not found.
-		fmt.Println("Unable to extract embedded config file")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to extract embedded config file: %w", err)
 	}
 
 	if strings.Compare(d.conf.Options.Key, "jahtauCaizahXae4doh8oKoo") != 0 {
@@ -320,9 +512,7 @@ func hanc(d *DDConfig, s []string) error {
 			}
 			emsg += fmt.Sprintf(" %s,", e)
 		}
-		d.errorMsg(emsg)
-		fmt.Println("Unable to complete installation.  Quitting")
-		os.Exit(1)
+		return errors.New(strings.TrimRight(emsg, ","))
 	}
 	return nil
 }
@@ -344,11 +534,14 @@ func hane(d *DDConfig, s []string) error {
 	tempLog := log.New(temp, "[embd] # ", log.Ldate|log.Ltime)
 	for j := 0; j < len(t); j++ {
 		d.traceMsg(fmt.Sprintf("command is %+v\n", t[j]))
-		sendCmd(d,
+		err = sendCmd(d,
 			tempLog,
 			t[j],
 			fmt.Sprintf("Unable to run command: %v", t[j]),
 			true)
+		if err != nil {
+			return err
+		}
 	}
 	d.traceMsg("Final change of ownership for " + d.conf.Install.Root)
 	sendCmd(d,
@@ -398,3 +591,129 @@ func escSpCar(s string) string {
 
 	return s
 }
+
+// dirSize returns the total size in bytes of the regular files under path p
+func dirSize(p string) (int64, error) {
+	var size int64
+	err := filepath.Walk(p, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size, err
+}
+
+// statPath is os.Stat, a variable so tests can simulate stat failures
+var statPath = os.Stat
+
+// ensureDir creates the directory p if it doesn't exist.  Only a path that
+// doesn't exist is created, any other error from checking p like permission
+// denied is returned as is so it isn't hidden by a failed or misleading mkdir.
+func ensureDir(p string) error {
+	fi, err := statPath(p)
+	switch {
+	case err == nil:
+		if !fi.IsDir() {
+			return fmt.Errorf("%s exists but isn't a directory", p)
+		}
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		err = os.MkdirAll(p, 0755)
+		if err != nil {
+			return fmt.Errorf("unable to create %s: %w", p, err)
+		}
+		return nil
+	case errors.Is(err, os.ErrPermission):
+		return fmt.Errorf("permission denied checking %s, check the owner and mode of its parent directories: %w", p, err)
+	}
+
+	return fmt.Errorf("unable to check %s: %w", p, err)
+}
+
+// renameDir is os.Rename, a variable so tests can simulate rename failures
+var renameDir = os.Rename
+
+// moveDir moves the directory src to dst which must not exist yet.  When src
+// and dst are on different filesystems the rename fails so src is copied to
+// dst and then removed.  The errors returned say whether dst already exists or
+// the move wasn't permitted.
+func moveDir(src string, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("unable to move %s to %s, the target already exists", src, dst)
+	}
+
+	err := renameDir(src, dst)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, syscall.EXDEV):
+		// Different filesystems, e.g. Root is a mount point, so copy instead
+		err = copyDir(src, dst)
+		if err != nil {
+			os.RemoveAll(dst)
+			return fmt.Errorf("unable to copy %s to %s across filesystems: %w", src, dst, err)
+		}
+		return os.RemoveAll(src)
+	case errors.Is(err, os.ErrPermission):
+		return fmt.Errorf("permission denied moving %s to %s, check the owner and mode of %s: %w", src, dst, filepath.Dir(dst), err)
+	case errors.Is(err, os.ErrExist) || errors.Is(err, syscall.ENOTEMPTY):
+		return fmt.Errorf("unable to move %s to %s, the target already exists: %w", src, dst, err)
+	}
+
+	return fmt.Errorf("unable to move %s to %s: %w", src, dst, err)
+}
+
+// copyDir copies the directory tree at src to dst keeping file modes and symlinks
+func copyDir(src string, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(p, target, info.Mode().Perm())
+		}
+
+		// Skip anything else like sockets which a release doesn't have
+		return nil
+	})
+}
+
+// copyFile copies the regular file src to dst with the file mode m
+func copyFile(src string, dst string, m os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, m)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
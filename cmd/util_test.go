@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// tarEntry describes a single entry for a crafted test tarball
+type tarEntry struct {
+	name string
+	kind byte
+	link string
+	body string
+}
+
+// makeTarball returns a gzipped tarball containing the provided entries
+func makeTarball(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	if _, err := gzw.Write(makeTar(t, entries).Bytes()); err != nil {
+		t.Fatalf("Unable to write gzip data: %v", err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Unable to close gzip writer: %v", err)
+	}
+	return buf
+}
+
+// makeTar returns an uncompressed tarball containing the provided entries
+func makeTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.kind,
+			Linkname: e.link,
+			Mode:     0644,
+			Size:     int64(len(e.body)),
+		}
+		if e.kind == tar.TypeDir {
+			hdr.Mode = 0755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Unable to write tar header for %s: %v", e.name, err)
+		}
+		if len(e.body) > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("Unable to write tar body for %s: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Unable to close tar writer: %v", err)
+	}
+	return buf
+}
+
+func TestUntarRejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name:    "parent directory traversal",
+			entries: []tarEntry{{name: "../evil.txt", kind: tar.TypeReg, body: "evil"}},
+		},
+		{
+			name:    "nested traversal",
+			entries: []tarEntry{{name: "django-DefectDojo/../../evil.txt", kind: tar.TypeReg, body: "evil"}},
+		},
+		{
+			name:    "absolute path",
+			entries: []tarEntry{{name: "/tmp/godojo-evil.txt", kind: tar.TypeReg, body: "evil"}},
+		},
+		{
+			name:    "directory traversal",
+			entries: []tarEntry{{name: "../evil-dir/", kind: tar.TypeDir}},
+		},
+		{
+			name:    "relative symlink outside root",
+			entries: []tarEntry{{name: "django-DefectDojo/link", kind: tar.TypeSymlink, link: "../../outside"}},
+		},
+		{
+			name:    "absolute symlink",
+			entries: []tarEntry{{name: "django-DefectDojo/link", kind: tar.TypeSymlink, link: "/etc/passwd"}},
+		},
+		{
+			name:    "hard link outside root",
+			entries: []tarEntry{{name: "django-DefectDojo/link", kind: tar.TypeLink, link: "../outside"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dst := filepath.Join(parent, "root")
+			if err := os.Mkdir(dst, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := untar(&DDConfig{}, dst, makeTarball(t, tc.entries))
+			if err == nil {
+				t.Fatalf("Expected an error extracting %s, got nil", tc.entries[0].name)
+			}
+
+			// Nothing should have been written next to the root
+			found, _ := filepath.Glob(filepath.Join(parent, "*"))
+			if len(found) != 1 {
+				t.Errorf("Expected only the root in %s after a rejected extract, found %v", parent, found)
+			}
+		})
+	}
+}
+
+func TestUntarRejectsChainedSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "file through chained links",
+			entries: []tarEntry{
+				{name: "a/b/", kind: tar.TypeDir},
+				{name: "a/b/q", kind: tar.TypeSymlink, link: "../.."},
+				{name: "p", kind: tar.TypeSymlink, link: "a/b/q/.."},
+				{name: "p/escaped.txt", kind: tar.TypeReg, body: "evil"},
+			},
+		},
+		{
+			name: "hard link through chained links",
+			entries: []tarEntry{
+				{name: "a/b/", kind: tar.TypeDir},
+				{name: "a/b/q", kind: tar.TypeSymlink, link: "../.."},
+				{name: "p", kind: tar.TypeSymlink, link: "a/b/q/.."},
+				{name: "link", kind: tar.TypeLink, link: "p/outside.txt"},
+			},
+		},
+		{
+			name: "file over a symlink",
+			entries: []tarEntry{
+				{name: "a/", kind: tar.TypeDir},
+				{name: "a/l", kind: tar.TypeSymlink, link: "f"},
+				{name: "a/l", kind: tar.TypeReg, body: "evil"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dst := filepath.Join(parent, "root")
+			if err := os.Mkdir(dst, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(parent, "outside.txt"), []byte("outside"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := untar(&DDConfig{}, dst, makeTarball(t, tc.entries))
+			if err == nil {
+				t.Fatal("Expected an error extracting through chained symlinks, got nil")
+			}
+			found, _ := filepath.Glob(filepath.Join(parent, "*"))
+			if len(found) != 2 {
+				t.Errorf("Expected only the root and outside.txt in %s after a rejected extract, found %v", parent, found)
+			}
+			if _, err := os.Stat(filepath.Join(dst, "a", "f")); !os.IsNotExist(err) {
+				t.Errorf("Expected no file written through a symlink entry, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUntarExtractsSafeEntries(t *testing.T) {
+	dst := t.TempDir()
+	entries := []tarEntry{
+		{name: "django-DefectDojo/", kind: tar.TypeDir},
+		{name: "django-DefectDojo/dojo/settings.py", kind: tar.TypeReg, body: "DEBUG = False\n"},
+		{name: "django-DefectDojo/settings-link.py", kind: tar.TypeSymlink, link: "dojo/settings.py"},
+		{name: "django-DefectDojo/settings-hard.py", kind: tar.TypeLink, link: "django-DefectDojo/dojo/settings.py"},
+	}
+
+	err := untar(&DDConfig{}, dst, makeTarball(t, entries))
+	if err != nil {
+		t.Fatalf("Expected no error extracting a safe tarball, got %v", err)
+	}
+
+	for _, p := range []string{"dojo/settings.py", "settings-link.py", "settings-hard.py"} {
+		b, err := os.ReadFile(filepath.Join(dst, "django-DefectDojo", p))
+		if err != nil {
+			t.Errorf("Unable to read extracted %s: %v", p, err)
+			continue
+		}
+		if string(b) != "DEBUG = False\n" {
+			t.Errorf("Expecting %q in %s, got %q", "DEBUG = False\n", p, string(b))
+		}
+	}
+}
+
+func TestUntarCompressionFormats(t *testing.T) {
+	entries := []tarEntry{
+		{name: "django-DefectDojo/", kind: tar.TypeDir},
+		{name: "django-DefectDojo/dojo/settings.py", kind: tar.TypeReg, body: "DEBUG = False\n"},
+	}
+	zstdTar := func(t *testing.T) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		zw, err := zstd.NewWriter(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := zw.Write(makeTar(t, entries).Bytes()); err != nil {
+			t.Fatalf("Unable to write zstd data: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("Unable to close zstd writer: %v", err)
+		}
+		return buf
+	}
+
+	tests := []struct {
+		name    string
+		tarball func(t *testing.T) *bytes.Buffer
+	}{
+		{name: "gzip", tarball: func(t *testing.T) *bytes.Buffer { return makeTarball(t, entries) }},
+		{name: "zstd", tarball: zstdTar},
+		{name: "plain tar", tarball: func(t *testing.T) *bytes.Buffer { return makeTar(t, entries) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := t.TempDir()
+			err := untar(&DDConfig{}, dst, tc.tarball(t))
+			if err != nil {
+				t.Fatalf("Expected no error extracting a %s tarball, got %v", tc.name, err)
+			}
+			b, err := os.ReadFile(filepath.Join(dst, "django-DefectDojo", "dojo", "settings.py"))
+			if err != nil {
+				t.Fatalf("Unable to read extracted settings.py: %v", err)
+			}
+			if string(b) != "DEBUG = False\n" {
+				t.Errorf("Expecting %q in settings.py, got %q", "DEBUG = False\n", string(b))
+			}
+
+			top, err := tarTopDir(tc.tarball(t))
+			if err != nil || top != "django-DefectDojo" {
+				t.Errorf("Expecting top-level directory django-DefectDojo from a %s tarball, got %q and %v", tc.name, top, err)
+			}
+		})
+	}
+}
+
+func TestUntarUnrecognizedFormat(t *testing.T) {
+	tests := map[string][]byte{
+		"zip":   []byte("PK\x03\x04 not a tarball"),
+		"bzip2": []byte("BZh91AY&SY not a tarball"),
+		"empty": {},
+	}
+
+	for name, b := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := untar(&DDConfig{}, t.TempDir(), bytes.NewReader(b))
+			if err == nil || !strings.Contains(err.Error(), "unrecognized tarball format") {
+				t.Errorf("Expected an unrecognized tarball format error, got %v", err)
+			}
+		})
+	}
+}
+
+// makeTree creates the files, keyed by path relative to root, with their contents
+func makeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for p, body := range files {
+		full := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMoveDirExistingTarget(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "django-DefectDojo-2.30.0")
+	dst := filepath.Join(root, "django-DefectDojo")
+	makeTree(t, src, map[string]string{"manage.py": "# new\n"})
+	makeTree(t, dst, map[string]string{"manage.py": "# old\n"})
+
+	err := moveDir(src, dst)
+	if err == nil || !strings.Contains(err.Error(), "target already exists") {
+		t.Fatalf("Expected a target already exists error, got %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dst, "manage.py")); string(b) != "# old\n" {
+		t.Errorf("Expected the existing target to be left as is, got %q", string(b))
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("Expected the source to be left as is, got %v", err)
+	}
+}
+
+func TestMoveDirCrossDevice(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "django-DefectDojo-2.30.0")
+	dst := filepath.Join(root, "django-DefectDojo")
+	makeTree(t, src, map[string]string{
+		"manage.py":                 "# manage.py\n",
+		"dojo/settings/settings.py": "DEBUG = False\n",
+	})
+	if err := os.Symlink("manage.py", filepath.Join(src, "link.py")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail the rename like it does when Root is on another filesystem
+	renameDir = func(o string, n string) error {
+		return &os.LinkError{Op: "rename", Old: o, New: n, Err: syscall.EXDEV}
+	}
+	defer func() { renameDir = os.Rename }()
+
+	if err := moveDir(src, dst); err != nil {
+		t.Fatalf("Expected the cross-device move to fall back to a copy, got %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dst, "dojo", "settings", "settings.py")); err != nil || string(b) != "DEBUG = False\n" {
+		t.Errorf("Expected settings.py to be copied, got %q and %v", string(b), err)
+	}
+	if l, err := os.Readlink(filepath.Join(dst, "link.py")); err != nil || l != "manage.py" {
+		t.Errorf("Expected the symlink to be copied, got %q and %v", l, err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected the source to be removed after copying, got %v", err)
+	}
+}
+
+func TestMoveDirPermissionDenied(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "django-DefectDojo-2.30.0")
+	makeTree(t, src, map[string]string{"manage.py": "# manage.py\n"})
+
+	renameDir = func(o string, n string) error {
+		return &os.LinkError{Op: "rename", Old: o, New: n, Err: syscall.EACCES}
+	}
+	defer func() { renameDir = os.Rename }()
+
+	err := moveDir(src, filepath.Join(root, "django-DefectDojo"))
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected a permission denied error, got %v", err)
+	}
+}
+
+func TestEnsureDirCreatesMissingDir(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "opt", "dojo")
+	if err := ensureDir(p); err != nil {
+		t.Fatalf("Expected the missing directory to be created, got %v", err)
+	}
+	if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+		t.Errorf("Expected %s to be a directory, got %v", p, err)
+	}
+	if err := ensureDir(p); err != nil {
+		t.Errorf("Expected an existing directory to be left as is, got %v", err)
+	}
+}
+
+func TestEnsureDirNotADir(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "dojo")
+	if err := os.WriteFile(p, []byte("not a dir\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := ensureDir(p)
+	if err == nil || !strings.Contains(err.Error(), "isn't a directory") {
+		t.Errorf("Expected an isn't a directory error, got %v", err)
+	}
+}
+
+func TestEnsureDirPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root isn't denied by directory permissions")
+	}
+	locked := filepath.Join(t.TempDir(), "locked")
+	if err := os.Mkdir(locked, 0000); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0755)
+
+	p := filepath.Join(locked, "dojo")
+	err := ensureDir(p)
+	if err == nil || !strings.Contains(err.Error(), "permission denied checking") {
+		t.Fatalf("Expected a permission denied error checking %s, got %v", p, err)
+	}
+	if _, err := os.Lstat(p); err == nil {
+		t.Errorf("Expected %s not to be created", p)
+	}
+}
+
+func TestEnsureDirStatError(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "dojo")
+
+	// Fail the stat like it does for a directory without search permission
+	statPath = func(n string) (os.FileInfo, error) {
+		return nil, &os.PathError{Op: "stat", Path: n, Err: syscall.EACCES}
+	}
+	defer func() { statPath = os.Stat }()
+
+	err := ensureDir(p)
+	if err == nil || !strings.Contains(err.Error(), "permission denied checking") {
+		t.Fatalf("Expected a permission denied error, got %v", err)
+	}
+	if _, err := os.Lstat(p); err == nil {
+		t.Errorf("Expected %s not to be created when the stat fails", p)
+	}
+}
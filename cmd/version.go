@@ -0,0 +1,19 @@
+package cmd
+
+import "fmt"
+
+// Build information set at build time via -ldflags, see build.bash
+//
+//	-X github.com/defectdojo/godojo/cmd.buildVersion=1.2.4
+//	-X github.com/defectdojo/godojo/cmd.buildCommit=$(git rev-parse --short HEAD)
+//	-X github.com/defectdojo/godojo/cmd.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+var (
+	buildVersion = ""        // Overrides the default godojo version when set
+	buildCommit  = "unknown" // Git commit godojo was built from
+	buildDate    = "unknown" // Date godojo was built
+)
+
+// versionInfo returns the godojo version, commit and build date for -version
+func (d *DDConfig) versionInfo() string {
+	return fmt.Sprintf("godojo version %s\n  commit: %s\n  built:  %s", d.ver, buildCommit, buildDate)
+}
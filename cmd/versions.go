@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dependency is a key dependency whose installed version is recorded
+type dependency struct {
+	name string // Name shown in the report
+	ver  string // Command that prints the dependency's version
+	path string // Command that prints the path of the file to find the OS package that installed it
+}
+
+// depVersion is the installed version of a dependency in the dependency manifest
+type depVersion struct {
+	Name    string `json:"name"`    // Name of the dependency
+	Version string `json:"version"` // First line of its version command, "" if it isn't installed
+	Package string `json:"package"` // OS package and package version that installed it, "" if unknown
+}
+
+// depManifest is the record of the installed dependency versions written to Install.Root
+type depManifest struct {
+	Target       string       `json:"target"`       // Target OS the dependencies were installed on
+	Timestamp    string       `json:"timestamp"`    // When the versions were recorded
+	Godojo       string       `json:"godojo"`       // Version of godojo that recorded them
+	Dependencies []depVersion `json:"dependencies"` // Installed version of each key dependency
+}
+
+// keyDependencies returns the dependencies recorded in the dependency manifest
+func keyDependencies(d *DDConfig) []dependency {
+	py := d.conf.Options.PyPath
+	return []dependency{
+		{"Python", py + " --version", "command -v " + py},
+		{"pip", py + " -m pip --version", py + " -c 'import os, pip; print(os.path.dirname(pip.__file__))'"},
+		{"virtualenv", py + " -m virtualenv --version", py + " -c 'import os, virtualenv; print(os.path.dirname(virtualenv.__file__))'"},
+		{"Node.js", "node --version", "command -v node"},
+		{"Yarn", "yarn --version", "command -v yarn"},
+		{"git", "git --version", "command -v git"},
+		{"PostgreSQL client", "psql --version", "command -v psql"},
+		{"MySQL client", "mysql --version", "command -v mysql"},
+	}
+}
+
+// pkgQuery returns the command that prints the name and version of the OS
+// package owning the file at the path printed by p using the target OS's
+// package manager, or "" if godojo doesn't know the distro's package manager
+func pkgQuery(t *targetOS, p string) string {
+	f := "f=$(readlink -f \"$(" + p + ")\") && "
+	switch t.distro {
+	case "ubuntu", "debian":
+		// Merged /usr means dpkg may have recorded the file without the /usr prefix
+		return f + "pkg=$({ dpkg-query -S \"$f\" 2>/dev/null || dpkg-query -S \"${f#/usr}\"; } | head -n 1 | cut -d: -f1) && " +
+			"dpkg-query -W -f='${Package} ${Version}\\n' \"$pkg\""
+	case "rhel", "amazon", "fedora", "suse":
+		return f + "rpm -qf --qf '%{NAME} %{VERSION}-%{RELEASE}\\n' \"$f\""
+	case "arch":
+		return f + "pacman -Q \"$(pacman -Qqo \"$f\")\""
+	case "gentoo":
+		return f + "portageq owners / \"$f\" | head -n 1"
+	}
+
+	return ""
+}
+
+// reportVersions takes a pointer to a DDConfig struct and a pointer to the
+// target OS struct and records the installed versions of the key dependencies,
+// and the OS packages that installed them, in the log and a manifest in
+// Install.Root so installs can be diffed to catch drift between runs
+func reportVersions(d *DDConfig, t *targetOS) {
+	d.sectionMsg("Recording the installed dependency versions")
+	mp := filepath.Join(d.conf.Install.Root, d.depManifest)
+	deps := keyDependencies(d)
+	if d.dryRun {
+		names := make([]string, 0, len(deps))
+		for i := range deps {
+			names = append(names, deps[i].name)
+		}
+		d.statusMsg(fmt.Sprintf("[dry-run] Would record the installed versions of %s in %s", strings.Join(names, ", "), mp))
+		return
+	}
+
+	m := depManifest{
+		Target:    t.id,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Godojo:    d.ver,
+	}
+	for i := range deps {
+		v := depVersion{Name: deps[i].name}
+		out, err := inspectCmd(d, deps[i].ver, "Unable to get the version of "+deps[i].name, false)
+		if err != nil {
+			d.traceMsg(fmt.Sprintf("Unable to get the version of %+v, error was: %+v", deps[i].name, err))
+			d.statusMsg(fmt.Sprintf("  %-18s not installed", deps[i].name))
+			m.Dependencies = append(m.Dependencies, v)
+			continue
+		}
+		v.Version = firstLine(out)
+
+		q := pkgQuery(t, deps[i].path)
+		if len(q) > 0 {
+			out, err = inspectCmd(d, q, "Unable to find the OS package for "+deps[i].name, false)
+			if err != nil {
+				d.traceMsg(fmt.Sprintf("Unable to find the OS package for %+v, error was: %+v", deps[i].name, err))
+			}
+			v.Package = firstLine(out)
+		}
+		m.Dependencies = append(m.Dependencies, v)
+
+		pkg := ""
+		if len(v.Package) > 0 {
+			pkg = " (" + v.Package + ")"
+		}
+		d.statusMsg(fmt.Sprintf("  %-18s %s%s", deps[i].name, v.Version, pkg))
+	}
+
+	// The versions are a record so not being able to write them doesn't stop the install
+	err := writeDepManifest(mp, m)
+	if err != nil {
+		d.warnMsg(fmt.Sprintf("%+v", err))
+		return
+	}
+	d.statusMsg("Installed dependency versions recorded in " + mp)
+}
+
+// writeDepManifest writes the dependency manifest m to the file at mp
+func writeDepManifest(mp string, m depManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ensureDir(filepath.Dir(mp))
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(mp, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write the dependency manifest %s: %w", mp, err)
+	}
+
+	return nil
+}
+
+// firstLine returns the first non-empty line of s without surrounding whitespace
+func firstLine(s string) string {
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if len(l) > 0 {
+			return l
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,1113 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported Amazon Linux Install Targets
+// Amazon Linux 2 uses yum and amazon-linux-extras while Amazon Linux 2023 uses dnf
+var amazonReleases = []c.Target{
+	{
+		ID:      "Amazon:2",
+		Distro:  "Amazon",
+		Release: "2",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+	{
+		ID:      "Amazon:2023",
+		Distro:  "Amazon",
+		Release: "2023",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Commands for Amazon
+func GetAmazon(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getAmazonBootstrap(bc, t)
+		if err != nil {
+			// Return error from getAmazonBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getAmazonInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getAmazonInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getAmazonPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getAmazonInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getAmazonCreateSettings(bc, t)
+		if err != nil {
+			// Return error from getAmazonCreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getAmazonSetupDojo(bc, t)
+		if err != nil {
+			// Return error from getAmazonCreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetAmazonDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getAmazonInstallMySQL(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getAmazonInstallPostgres(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getAmazonStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getAmazonStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getAmazonInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getAmazonInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetAmazonBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetAmazonBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getAmazonInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getAmazonInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getAmazonInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonBootstrap() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2Bootstrap
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023Bootstrap
+		}
+	}
+}
+
+func getAmazonBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setAmazonBootstrap()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 Bootstrap commands
+var amzn2Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "yum check-update || [ $? -eq 100 ]",
+		Errmsg:     "Unable to update Amazon Linux package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "yum update -y",
+		Errmsg:     "Unable to upgrade OS packages with yum",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "amazon-linux-extras install -y epel",
+		Errmsg:     "Unable to enable the EPEL repo via amazon-linux-extras",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "yum install -y python3 python3-pip ca-certificates curl gnupg git sudo tar",
+		Errmsg:     "Unable to install prerequisites for installer via yum",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Amazon Linux 2023 Bootstrap commands
+var amzn2023Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf check-update || [ $? -eq 100 ]",
+		Errmsg:     "Unable to update Amazon Linux package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "dnf update -y",
+		Errmsg:     "Unable to upgrade OS packages with dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "dnf install -y python3.11 python3.11-pip ca-certificates gnupg2 git sudo tar",
+		Errmsg:     "Unable to install prerequisites for installer via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallerPrep() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = singleCmds(amzn2InstallerPrep)
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = singleCmds(amzn2023InstallerPrep)
+		}
+	}
+}
+
+func getAmazonInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonInstallerPrep()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 installer prep Commands
+// Node.js 18 needs a newer glibc than Amazon Linux 2 has so Node.js 16 is used
+var amzn2InstallerPrep = []Cmd{
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl --silent --location https://dl.yarnpkg.com/rpm/yarn.repo | sudo tee /etc/yum.repos.d/yarn.repo",
+		Errmsg:     "Unable to add the repo for Yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl --silent --location https://rpm.nodesource.com/setup_16.x | sudo bash -",
+		Errmsg:     "Unable to add yard repo as an apt source",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "yum check-update || [ $? -eq 100 ]", // yum also returns a 100 exit code when updates are available
+		Errmsg:     "Unable to update Amazon Linux package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "yum install -y sudo mariadb yarn expect gcc python3-devel initscripts mariadb-devel libcurl-devel",
+		Errmsg:     "Unable to install Amazon Linux packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+}
+
+// Amazon Linux 2023 installer prep Commands
+var amzn2023InstallerPrep = []Cmd{
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl --silent --location https://dl.yarnpkg.com/rpm/yarn.repo | sudo tee /etc/yum.repos.d/yarn.repo",
+		Errmsg:     "Unable to add the repo for Yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl --silent --location https://rpm.nodesource.com/setup_18.x | sudo bash -",
+		Errmsg:     "Unable to add the Node.js repo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "dnf check-update || [ $? -eq 100 ]",
+		Errmsg:     "Unable to update Amazon Linux package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "dnf install -y sudo mariadb105 yarn expect gcc python3.11-devel mariadb-connector-c-devel libcurl-devel",
+		Errmsg:     "Unable to install Amazon Linux packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallMySQL() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2NoDBMySQL
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023NoDBMySQL
+		}
+	}
+}
+
+func getAmazonInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonInstallMySQL()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// Amazon Linux 2 install MySQL Commands
+// TODO: https://computingforgeeks.com/install-mysql-5-7-on-centos-rhel-linux/
+var amzn2NoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "echo 'CURRENTLY UNSUPPORTED' && false",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023NoDBMySQL = append([]c.SingleCmd{}, amzn2NoDBMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallPostgres() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2NoDBPostgres
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023NoDBPostgres
+		}
+	}
+}
+
+func getAmazonInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonInstallPostgres()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// Amazon Linux 2 install Postgres Commands
+var amzn2NoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "amazon-linux-extras enable postgresql13",
+		Errmsg:     "Unable to enable install of PostgreSQL 13",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "yum install -y postgresql-server",
+		Errmsg:     "Unable to install PostgreSQL 13",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "postgresql-setup --initdb",
+		Errmsg:     "Unable to initialize PostgreSQL 13",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Amazon Linux 2023 install Postgres Commands
+var amzn2023NoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y postgresql15-server",
+		Errmsg:     "Unable to install PostgreSQL 15",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "postgresql-setup --initdb",
+		Errmsg:     "Unable to initialize PostgreSQL 15",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallMySQLClient() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			//amazonReleases[k].PkgCmds = amzn2InstMySQLClient
+		case amazonReleases[k].Release == "2023":
+			//amazonReleases[k].PkgCmds = amzn2023InstMySQLClient
+		}
+	}
+}
+
+func getAmazonInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonInstallMySQLClient()
+
+	// No match for the target provided
+	//return fmt.Errorf("Unable to find commands for target %s\n", t)
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallPgClient() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2InstPgClient
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023InstPgClient
+		}
+	}
+}
+
+func getAmazonInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonInstallPgClient()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 install Postgres client Commands
+var amzn2InstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "amazon-linux-extras install -y postgresql13",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f postgres",
+		Errmsg:     "Unable to add postgres group",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "id postgres &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g postgres postgres; fi",
+		Errmsg:     "Unable to add postgres user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir -p /var/lib/pgsql",
+		Errmsg:     "Unable to create postgres user directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Amazon Linux 2023 uses dnf for the Postgres client
+var amzn2023InstPgClient = append([]c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y postgresql15",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}, amzn2InstPgClient[1:]...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonStartMySQL() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2StartMySQL
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023StartMySQL
+		}
+	}
+}
+
+func getAmazonStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonStartMySQL()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 Start MySQL Commands
+var amzn2StartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "service mysql start && false",
+		Errmsg:     "Unable to start MySQL server",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023StartMySQL = append([]c.SingleCmd{}, amzn2StartMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonStartPostgres() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2StartPostgres
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023StartPostgres
+		}
+	}
+}
+
+func getAmazonStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonStartPostgres()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 Start Postgres Commands
+var amzn2StartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "systemctl start postgresql",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023StartPostgres = append([]c.SingleCmd{}, amzn2StartPostgres...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonPrepDjango() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2PrepDjango
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023PrepDjango
+		}
+	}
+}
+
+func getAmazonPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonPrepDjango()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 Prep Django Commands
+var amzn2PrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{PyPath} -m pip install virtualenv",
+		Errmsg:     "Unable to install virtualenv module for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}{PyPath} -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to create virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "Upgrade of Python pip failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023PrepDjango = append([]c.SingleCmd{}, amzn2PrepDjango...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonCreateSettings() {
+	// Connect bootstrap commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2CreateSettings
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023CreateSettings
+		}
+	}
+}
+
+func getAmazonCreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setAmazonCreateSettings()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 Create Settings Commands
+var amzn2CreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create customization directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/.env.prod",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023CreateSettings = append([]c.SingleCmd{}, amzn2CreateSettings...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonSetupDojo() {
+	// Connect setup DefectDojo commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2SetupDojo
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023SetupDojo
+		}
+	}
+}
+
+func getAmazonSetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setAmazonSetupDojo()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 setup DefectDojo Commands
+var amzn2SetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Amazon Linux 2023
+var amzn2023SetupDojo = append([]c.SingleCmd{}, amzn2SetupDojo...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setAmazonInstallRedis() {
+	// Connect Redis install commands to the supported Amazon releases
+	for k := range amazonReleases {
+		switch {
+		case amazonReleases[k].Release == "2":
+			amazonReleases[k].PkgCmds = amzn2InstRedis
+		case amazonReleases[k].Release == "2023":
+			amazonReleases[k].PkgCmds = amzn2023InstRedis
+		}
+	}
+}
+
+func getAmazonInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setAmazonInstallRedis()
+
+	// Cycle through Amazon install targets
+	for k, v := range amazonReleases {
+		// Find a match for the target ID and the existing list of commands in amazonReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, amazonReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Amazon Linux 2 install Redis Commands
+var amzn2InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "amazon-linux-extras install -y redis6",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Amazon Linux 2023 install Redis Commands, Redis 6 is packaged as redis6
+var amzn2023InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y redis6",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func getAmazonInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// No match for the target provided
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
@@ -0,0 +1,941 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported Arch Linux Install Targets
+// Arch is a rolling release so there's a single target for whatever is current
+var archReleases = []c.Target{
+	{
+		ID:      "Arch:rolling",
+		Distro:  "Arch",
+		Release: "rolling",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Commands for Arch
+func GetArch(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getArchBootstrap(bc, t)
+		if err != nil {
+			// Return error from getArchBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getArchInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getArchInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getArchPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getArchInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getArchCreateSettings(bc, t)
+		if err != nil {
+			// Return error from getArchCreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getArchSetupDojo(bc, t)
+		if err != nil {
+			// Return error from getArchCreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetArchDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getArchInstallMySQL(bc, t)
+			if err != nil {
+				// Return error from getArchInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getArchInstallPostgres(bc, t)
+			if err != nil {
+				// Return error from getArchInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getArchStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getArchInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getArchStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getArchInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getArchInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getArchInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getArchInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getArchInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetArchBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetArchBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getArchInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getArchInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getArchInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getArchInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchBootstrap() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archBootstrap
+		}
+	}
+}
+
+func getArchBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setArchBootstrap()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch Bootstrap commands
+// pacman needs --noconfirm to run without prompts and --needed to skip packages already installed
+var archBootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -Syu --noconfirm",
+		Errmsg:     "Unable to update the Arch package database and OS packages",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// Arch's Python package is simply python, it provides python3 as well
+		Cmd:        "pacman -S --noconfirm --needed python python-pip python-virtualenv ca-certificates curl gnupg git sudo tar",
+		Errmsg:     "Unable to install prerequisites for installer via pacman",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallerPrep() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archInstallerPrep
+		}
+	}
+}
+
+func getArchInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchInstallerPrep()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch installer prep Commands
+// Arch packages ship their headers so there are no -devel packages to install
+var archInstallerPrep = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "pacman -S --noconfirm --needed sudo mariadb-clients mariadb-libs nodejs yarn expect gcc make pkgconf " +
+			"curl inetutils",
+		Errmsg:     "Unable to install Arch packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallMySQL() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archNoDBMySQL
+		}
+	}
+}
+
+func getArchInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchInstallMySQL()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// Arch install MySQL Commands
+// TODO: MariaDB installs fine with pacman but godojo doesn't yet know the default credentials outside of Debian/Ubuntu
+var archNoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "echo 'CURRENTLY UNSUPPORTED' && false",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallPostgres() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archNoDBPostgres
+		}
+	}
+}
+
+func getArchInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchInstallPostgres()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// Arch install Postgres Commands
+var archNoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -S --noconfirm --needed postgresql",
+		Errmsg:     "Unable to install PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// The Arch package doesn't create the cluster, password auth for TCP means pg_hba.conf needs no changes
+		Cmd: "[ -f /var/lib/postgres/data/PG_VERSION ] || sudo -u postgres initdb --locale=C.UTF-8 --encoding=UTF8 " +
+			"-D /var/lib/postgres/data --auth-host=scram-sha-256 --auth-local=peer",
+		Errmsg:     "Unable to initialize PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallMySQLClient() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archInstMySQLClient
+		}
+	}
+}
+
+func getArchInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchInstallMySQLClient()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch install MySQL client Commands
+var archInstMySQLClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -S --noconfirm --needed mariadb-clients mariadb-libs",
+		Errmsg:     "Unable to install MySQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallPgClient() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archInstPgClient
+		}
+	}
+}
+
+func getArchInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchInstallPgClient()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch install Postgres client Commands
+// psql comes with the postgresql package, the server isn't started or initialized
+var archInstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -S --noconfirm --needed postgresql",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchStartMySQL() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archStartMySQL
+		}
+	}
+}
+
+func getArchStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchStartMySQL()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch Start MySQL Commands
+var archStartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "systemctl start mariadb",
+		Errmsg:     "Unable to start MariaDB",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchStartPostgres() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archStartPostgres
+		}
+	}
+}
+
+func getArchStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchStartPostgres()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch Start Postgres Commands
+var archStartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "systemctl start postgresql",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchPrepDjango() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archPrepDjango
+		}
+	}
+}
+
+func getArchPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchPrepDjango()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch Prep Django Commands
+// virtualenv comes from the python-virtualenv package as Arch's Python doesn't allow pip installs outside a virtualenv
+var archPrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}python3 -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to create virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "Upgrade of Python pip failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchCreateSettings() {
+	// Connect bootstrap commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archCreateSettings
+		}
+	}
+}
+
+func getArchCreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setArchCreateSettings()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch Create Settings Commands
+var archCreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create customization directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/.env.prod",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchSetupDojo() {
+	// Connect setup DefectDojo commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archSetupDojo
+		}
+	}
+}
+
+func getArchSetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setArchSetupDojo()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch setup DefectDojo Commands
+var archSetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallRedis() {
+	// Connect Redis install commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archInstRedis
+		}
+	}
+}
+
+func getArchInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setArchInstallRedis()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch install Redis Commands, Arch replaced Redis with Valkey which speaks the same protocol
+var archInstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -S --noconfirm --needed valkey",
+		Errmsg:     "Unable to install Valkey",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setArchInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported Arch releases
+	for k := range archReleases {
+		switch {
+		case archReleases[k].Release == "rolling":
+			archReleases[k].PkgCmds = archInstRabbitMQ
+		}
+	}
+}
+
+func getArchInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setArchInstallRabbitMQ()
+
+	// Cycle through Arch install targets
+	for k, v := range archReleases {
+		// Find a match for the target ID and the existing list of commands in archReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, archReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Arch install RabbitMQ Commands
+var archInstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "pacman -S --noconfirm --needed rabbitmq",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
@@ -0,0 +1,1037 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported Debian Install Targets
+// Debian 12 is bookworm and Debian 11 is bullseye
+var debianReleases = []c.Target{
+	{
+		ID:      "Debian:12",
+		Distro:  "Debian",
+		Release: "12",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+	{
+		ID:      "Debian:11",
+		Distro:  "Debian",
+		Release: "11",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Commands for Debian
+func GetDebian(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getDebianBootstrap(bc, t)
+		if err != nil {
+			// Return error from getDebianBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getDebianInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getDebianInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getDebianPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getDebianInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getDebianCreateSettings(bc, t)
+		if err != nil {
+			// Return error from getDebianCreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getDebianSetupDojo(bc, t)
+		if err != nil {
+			// Return error from getDebianCreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetDebianDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getDebianInstallMySQL(bc, t)
+			if err != nil {
+				// REturn error from getDebianInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getDebianInstallPostgres(bc, t)
+			if err != nil {
+				// REturn error from getDebianInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getDebianStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getDebianStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getDebianInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getDebianInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetDebianBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetDebianBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getDebianInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getDebianInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getDebianInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianBootstrap() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12Bootstrap
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11Bootstrap
+		}
+	}
+}
+
+func getDebianBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setDebianBootstrap()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 Bootstrap commands
+var deb12Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get update",
+		Errmsg:     "Unable to update apt database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get -y upgrade",
+		Errmsg:     "Unable to upgrade OS packages with apt",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get -y -o Dpkg::Options::=\"--force-confdef\" -o Dpkg::Options::=\"--force-confold\" install python3 python3-virtualenv ca-certificates curl gnupg git sudo",
+		Errmsg:     "Unable to install prerequisites for installer via apt",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11Bootstrap = append([]c.SingleCmd{}, deb12Bootstrap...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallerPrep() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = singleCmds(deb12InstallerPrep)
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = singleCmds(deb11InstallerPrep)
+		}
+	}
+}
+
+func getDebianInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianInstallerPrep()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 installer prep Commands
+// TODO Check if the yarn command needs updating
+var deb12InstallerPrep = []Cmd{
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl -sS {yarnGPG} | apt-key add -",
+		Errmsg:     "Unable to obtain the gpg key for Yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "echo -n {yarnRepo} > /etc/apt/sources.list.d/yarn.list",
+		Errmsg:     "Unable to add yard repo as an apt source",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get update",
+		Errmsg:     "Unable to update apt database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get -y install sudo default-libmysqlclient-dev",
+		Errmsg:     "Unable to install sudo and MySQL client library",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "curl -sL {nodeURL} | bash - ",
+		Errmsg:     "Unable to install nodejs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+	{SingleCmd: c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y apt-transport-https libjpeg-dev gcc libssl-dev python3-dev python3-pip python3-virtualenv yarn build-essential expect libcurl4-openssl-dev",
+		Errmsg:     "Installing OS packages with apt failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	}},
+}
+
+// No command changes needed for Debian 11
+var deb11InstallerPrep = append([]Cmd{}, deb12InstallerPrep...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallMySQL() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12NoDBMySQL
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11NoDBMySQL
+		}
+	}
+}
+
+func getDebianInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianInstallMySQL()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// Debian 12 install MySQL Commands
+// Note: Debian ships MariaDB as its default-mysql-server package
+var deb12NoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y default-mysql-server default-libmysqlclient-dev",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11NoDBMySQL = append([]c.SingleCmd{}, deb12NoDBMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallPostgres() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12NoDBPostgres
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11NoDBPostgres
+		}
+	}
+}
+
+func getDebianInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianInstallPostgres()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// Debian 12 install Postgres Commands
+var deb12NoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y libpq-dev postgresql postgresql-contrib postgresql-client-common",
+		Errmsg:     "Unable to install PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11NoDBPostgres = append([]c.SingleCmd{}, deb12NoDBPostgres...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallMySQLClient() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			//debianReleases[k].PkgCmds = deb12InstMySQLClient
+		case debianReleases[k].Release == "11":
+			//debianReleases[k].PkgCmds = deb11InstMySQLClient
+		}
+	}
+}
+
+func getDebianInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianInstallMySQLClient()
+
+	// No match for the target provided
+	//return fmt.Errorf("Unable to find commands for target %s\n", t)
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallPgClient() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12InstPgClient
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11InstPgClient
+		}
+	}
+}
+
+func getDebianInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianInstallPgClient()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 install Postgres client Commands
+var deb12InstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y postgresql-client",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f postgres",
+		Errmsg:     "Unable to add postgres group",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/useradd -s /bin/bash -m -g postgres postgres",
+		Errmsg:     "Unable to add postgres user",
+		Hard:       false, // incase there is an existing postgres user, useradd returns a 9 exit code
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11InstPgClient = append([]c.SingleCmd{}, deb12InstPgClient...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianStartMySQL() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12StartMySQL
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11StartMySQL
+		}
+	}
+}
+
+func getDebianStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianStartMySQL()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 Start MySQL Commands
+var deb12StartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "service mariadb start",
+		Errmsg:     "Unable to start MariaDB",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11StartMySQL = append([]c.SingleCmd{}, deb12StartMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianStartPostgres() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12StartPostgres
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11StartPostgres
+		}
+	}
+}
+
+func getDebianStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianStartPostgres()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 Start Postgres Commands
+var deb12StartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/service postgresql start",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11StartPostgres = append([]c.SingleCmd{}, deb12StartPostgres...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianPrepDjango() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12PrepDjango
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11PrepDjango
+		}
+	}
+}
+
+func getDebianPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianPrepDjango()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 Prep Django Commands
+var deb12PrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}python3 -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to setup virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11PrepDjango = append([]c.SingleCmd{}, deb12PrepDjango...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianCreateSettings() {
+	// Connect bootstrap commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12CreateSettings
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11CreateSettings
+		}
+	}
+}
+
+func getDebianCreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setDebianCreateSettings()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 Create Settings Commands
+var deb12CreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create settings.py file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/settings.py",
+		Errmsg:     "Unable to change ownership of settings.py file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11CreateSettings = append([]c.SingleCmd{}, deb12CreateSettings...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianSetupDojo() {
+	// Connect setup DefectDojo commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12SetupDojo
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11SetupDojo
+		}
+	}
+}
+
+func getDebianSetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setDebianSetupDojo()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 setup DefectDojo Commands
+var deb12SetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11SetupDojo = append([]c.SingleCmd{}, deb12SetupDojo...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallRedis() {
+	// Connect Redis install commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12InstRedis
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11InstRedis
+		}
+	}
+}
+
+func getDebianInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setDebianInstallRedis()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 install Redis Commands
+var deb12InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y redis-server",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11InstRedis = append([]c.SingleCmd{}, deb12InstRedis...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setDebianInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported Debian releases
+	for k := range debianReleases {
+		switch {
+		case debianReleases[k].Release == "12":
+			debianReleases[k].PkgCmds = deb12InstRabbitMQ
+		case debianReleases[k].Release == "11":
+			debianReleases[k].PkgCmds = deb11InstRabbitMQ
+		}
+	}
+}
+
+func getDebianInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setDebianInstallRabbitMQ()
+
+	// Cycle through Debian install targets
+	for k, v := range debianReleases {
+		// Find a match for the target ID and the existing list of commands in debianReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, debianReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Debian 12 install RabbitMQ Commands
+var deb12InstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y rabbitmq-server",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Debian 11
+var deb11InstRabbitMQ = append([]c.SingleCmd{}, deb12InstRabbitMQ...)
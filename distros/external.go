@@ -0,0 +1,193 @@
+package distros
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	c "github.com/mtesauro/commandeer"
+	"gopkg.in/yaml.v2"
+)
+
+// How the commands in an external command set are combined with the built-in
+// commands for the same label and target
+const (
+	ModeReplace = "replace" // Use only the external commands, the default
+	ModePrepend = "prepend" // Run the external commands before the built-in ones
+	ModeAppend  = "append"  // Run the external commands after the built-in ones
+)
+
+// Labels are the command package labels that can have external commands
+var Labels = []string{"bootstrap", "installerprep", "installdb", "installdbclient", "startdb",
+	"prepdjango", "createsettings", "setupdojo", "installbroker"}
+
+// cmdFile is a YAML file of external commands for a single distro target, e.g.
+//
+//	ID: "Debian:12"
+//	Commands:
+//	  bootstrap:
+//	    Mode: append
+//	    Cmds:
+//	      - Cmd: "DEBIAN_FRONTEND=noninteractive apt-get -y install vim"
+//	        Errmsg: "Unable to install vim via apt"
+//	        Hard: true
+//	        Timeout: 10m
+type cmdFile struct {
+	ID       string            `yaml:"ID"`
+	Commands map[string]cmdSet `yaml:"Commands"`
+}
+
+// cmdSet is the external commands for a label and how they're combined with
+// the built-in ones
+type cmdSet struct {
+	Mode string    `yaml:"Mode"`
+	Cmds []fileCmd `yaml:"Cmds"`
+	file string    // File the commands were read from for error messages
+}
+
+// fileCmd is a single command in a cmdFile, matching commandeer's SingleCmd
+type fileCmd struct {
+	Cmd        string        `yaml:"Cmd"`
+	Errmsg     string        `yaml:"Errmsg"`
+	Hard       bool          `yaml:"Hard"`
+	Timeout    time.Duration `yaml:"Timeout"`
+	BeforeText string        `yaml:"BeforeText"`
+	AfterText  string        `yaml:"AfterText"`
+	Parallel   bool          `yaml:"Parallel"`
+}
+
+// externalCmds holds the commands loaded by LoadCmdDir keyed by the lower case
+// target ID then the label
+var externalCmds = map[string]map[string]cmdSet{}
+
+// LoadCmdDir reads every .yml and .yaml file in the directory dir as external
+// commands for a distro target, replacing any loaded earlier.  Each file sets
+// the target ID, e.g. Ubuntu:22.04, and the commands for one or more labels.
+// Only one file can set the commands for a label and target.  An empty dir
+// clears the external commands.
+func LoadCmdDir(dir string) error {
+	loaded := map[string]map[string]cmdSet{}
+	if len(dir) == 0 {
+		externalCmds = loaded
+		return nil
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("Unable to read the distro command directory %s: %v", dir, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("The distro command directory %s isn't a directory", dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+	if err != nil {
+		return fmt.Errorf("Unable to list the distro command files in %s: %v", dir, err)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if ext := filepath.Ext(f); ext != ".yml" && ext != ".yaml" {
+			continue
+		}
+		err = loadCmdFile(f, loaded)
+		if err != nil {
+			return err
+		}
+	}
+	externalCmds = loaded
+
+	return nil
+}
+
+// loadCmdFile reads the external commands in the YAML file f into loaded
+func loadCmdFile(f string, loaded map[string]map[string]cmdSet) error {
+	b, err := os.ReadFile(f)
+	if err != nil {
+		return fmt.Errorf("Unable to read the distro command file %s: %v", f, err)
+	}
+	var cf cmdFile
+	err = yaml.UnmarshalStrict(b, &cf)
+	if err != nil {
+		return fmt.Errorf("Unable to parse the distro command file %s: %v", f, err)
+	}
+	if !strings.Contains(cf.ID, ":") {
+		return fmt.Errorf("The distro command file %s needs an ID of Distro:Release like Ubuntu:22.04, got %q", f, cf.ID)
+	}
+
+	id := strings.ToLower(cf.ID)
+	if loaded[id] == nil {
+		loaded[id] = map[string]cmdSet{}
+	}
+	for l, s := range cf.Commands {
+		if !validLabel(l) {
+			return fmt.Errorf("Unknown label %s for %s in the distro command file %s, it must be one of %s",
+				l, cf.ID, f, strings.Join(Labels, ", "))
+		}
+		if prev, ok := loaded[id][l]; ok {
+			return fmt.Errorf("The %s commands for %s are set in both %s and %s", l, cf.ID, prev.file, f)
+		}
+		if len(s.Mode) == 0 {
+			s.Mode = ModeReplace
+		}
+		if s.Mode != ModeReplace && s.Mode != ModePrepend && s.Mode != ModeAppend {
+			return fmt.Errorf("Unknown Mode %s for the %s commands for %s in %s, it must be one of %s, %s or %s",
+				s.Mode, l, cf.ID, f, ModeReplace, ModePrepend, ModeAppend)
+		}
+		for i := range s.Cmds {
+			if len(strings.TrimSpace(s.Cmds[i].Cmd)) == 0 {
+				return fmt.Errorf("Command %d of the %s commands for %s in %s has no Cmd", i+1, l, cf.ID, f)
+			}
+		}
+		s.file = f
+		loaded[id][l] = s
+	}
+
+	return nil
+}
+
+// validLabel returns true if l is one of Labels
+func validLabel(l string) bool {
+	for i := range Labels {
+		if Labels[i] == l {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeExternal returns the built-in commands for the label l and target t
+// combined with any external commands loaded for them
+func mergeExternal(l string, t string, builtin []Cmd) []Cmd {
+	s, ok := externalCmds[strings.ToLower(t)][l]
+	if !ok {
+		return builtin
+	}
+
+	ext := make([]Cmd, 0, len(s.Cmds))
+	for _, fc := range s.Cmds {
+		sc := c.SingleCmd{
+			Cmd:        fc.Cmd,
+			Errmsg:     fc.Errmsg,
+			Hard:       fc.Hard,
+			Timeout:    fc.Timeout,
+			BeforeText: fc.BeforeText,
+			AfterText:  fc.AfterText,
+		}
+		if len(sc.Errmsg) == 0 {
+			sc.Errmsg = "Unable to run the " + l + " command from " + filepath.Base(s.file)
+		}
+		ext = append(ext, Cmd{SingleCmd: sc, Parallel: fc.Parallel})
+	}
+
+	switch s.Mode {
+	case ModePrepend:
+		return append(ext, builtin...)
+	case ModeAppend:
+		return append(append([]Cmd{}, builtin...), ext...)
+	}
+
+	return ext
+}
@@ -0,0 +1,1120 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported Fedora Install Targets
+var fedoraReleases = []c.Target{
+	{
+		ID:      "Fedora:38",
+		Distro:  "Fedora",
+		Release: "38",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+	{
+		ID:      "Fedora:39",
+		Distro:  "Fedora",
+		Release: "39",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+	{
+		ID:      "Fedora:40",
+		Distro:  "Fedora",
+		Release: "40",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Commands for Fedora
+func GetFedora(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getFedoraBootstrap(bc, t)
+		if err != nil {
+			// Return error from getFedoraBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getFedoraInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getFedoraInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getFedoraPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getFedoraInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getFedoraCreateSettings(bc, t)
+		if err != nil {
+			// Return error from getFedoraCreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getFedoraSetupDojo(bc, t)
+		if err != nil {
+			// Return error from getFedoraCreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetFedoraDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getFedoraInstallMySQL(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getFedoraInstallPostgres(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getFedoraStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getFedoraStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getFedoraInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getFedoraInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetFedoraBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetFedoraBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getFedoraInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getFedoraInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getFedoraInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraBootstrap() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38Bootstrap
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39Bootstrap
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40Bootstrap
+		}
+	}
+}
+
+func getFedoraBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setFedoraBootstrap()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 Bootstrap commands
+// Fedora's own repos have everything the installer needs so, unlike RHEL, EPEL isn't enabled
+var fedora38Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf check-update || [ $? -eq 100 ]", // dnf returns a 100 exit code if updates are available
+		Errmsg:     "Unable to update Fedora package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "dnf update -y",
+		Errmsg:     "Unable to upgrade OS packages with dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// Fedora 38's python3 is 3.11
+		Cmd:        "dnf install -y python3 python3-pip python3-virtualenv ca-certificates curl gnupg2 git sudo tar",
+		Errmsg:     "Unable to install prerequisites for installer via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Fedora 39 Bootstrap commands
+// Fedora 39 moved python3 to 3.12, install 3.11 alongside it for PYPATH=/usr/bin/python3.11
+var fedora39Bootstrap = []c.SingleCmd{
+	fedora38Bootstrap[0],
+	fedora38Bootstrap[1],
+	c.SingleCmd{
+		Cmd:        "dnf install -y python3 python3.11 python3-pip python3-virtualenv ca-certificates curl gnupg2 git sudo tar",
+		Errmsg:     "Unable to install prerequisites for installer via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 40
+var fedora40Bootstrap = append([]c.SingleCmd{}, fedora39Bootstrap...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallerPrep() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38InstallerPrep
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39InstallerPrep
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40InstallerPrep
+		}
+	}
+}
+
+func getFedoraInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraInstallerPrep()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 installer prep Commands
+// Node.js and Yarn come from the Fedora repos rather than the Nodesource and Yarn repos used for RHEL
+var fedora38InstallerPrep = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf check-update || [ $? -eq 100 ]", // dnf returns a 100 exit code if updates are available
+		Errmsg:     "Unable to update Fedora package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "dnf install -y sudo community-mysql nodejs yarnpkg expect gcc python3-devel initscripts " +
+			"mariadb-connector-c-devel libcurl-devel",
+		Errmsg:     "Unable to install Fedora packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Fedora 39 installer prep Commands
+var fedora39InstallerPrep = []c.SingleCmd{
+	fedora38InstallerPrep[0],
+	c.SingleCmd{
+		Cmd: "dnf install -y sudo community-mysql nodejs yarnpkg expect gcc python3-devel python3.11-devel initscripts " +
+			"mariadb-connector-c-devel libcurl-devel",
+		Errmsg:     "Unable to install Fedora packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// Fedora 40 installer prep Commands
+// Fedora 40 split the service command out of initscripts into initscripts-service
+var fedora40InstallerPrep = []c.SingleCmd{
+	fedora38InstallerPrep[0],
+	c.SingleCmd{
+		Cmd: "dnf install -y sudo community-mysql nodejs yarnpkg expect gcc python3-devel python3.11-devel initscripts-service " +
+			"mariadb-connector-c-devel libcurl-devel",
+		Errmsg:     "Unable to install Fedora packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallMySQL() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38NoDBMySQL
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39NoDBMySQL
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40NoDBMySQL
+		}
+	}
+}
+
+func getFedoraInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraInstallMySQL()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// Fedora 38 install MySQL Commands
+// TODO: https://computingforgeeks.com/install-mysql-5-7-on-centos-fedora-linux/
+var fedora38NoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "echo 'CURRENTLY UNSUPPORTED' && false",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39NoDBMySQL = append([]c.SingleCmd{}, fedora38NoDBMySQL...)
+var fedora40NoDBMySQL = append([]c.SingleCmd{}, fedora39NoDBMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallPostgres() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38NoDBPostgres
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39NoDBPostgres
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40NoDBPostgres
+		}
+	}
+}
+
+func getFedoraInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraInstallPostgres()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// Fedora 38 install Postgres Commands
+var fedora38NoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		// Fedora has no PostgreSQL modules, its repos ship a single current PostgreSQL
+		Cmd:        "dnf install -y postgresql-server",
+		Errmsg:     "Unable to install PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "postgresql-setup --initdb",
+		Errmsg:     "Unable to initialize PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39NoDBPostgres = append([]c.SingleCmd{}, fedora38NoDBPostgres...)
+var fedora40NoDBPostgres = append([]c.SingleCmd{}, fedora39NoDBPostgres...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallMySQLClient() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			//fedoraReleases[k].PkgCmds = fedora38InstMySQLClient
+		case fedoraReleases[k].Release == "39":
+			//fedoraReleases[k].PkgCmds = fedora39InstMySQLClient
+		case fedoraReleases[k].Release == "40":
+			//fedoraReleases[k].PkgCmds = fedora40InstMySQLClient
+		}
+	}
+}
+
+func getFedoraInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraInstallMySQLClient()
+
+	// No match for the target provided
+	//return fmt.Errorf("Unable to find commands for target %s\n", t)
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallPgClient() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38InstPgClient
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39InstPgClient
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40InstPgClient
+		}
+	}
+}
+
+func getFedoraInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraInstallPgClient()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 install Postgres client Commands
+var fedora38InstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y postgresql",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f postgres",
+		Errmsg:     "Unable to add postgres group",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "id postgres &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g postgres postgres; fi",
+		Errmsg:     "Unable to add postgres user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir -p /var/lib/pgsql",
+		Errmsg:     "Unable to create postgres user directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39InstPgClient = append([]c.SingleCmd{}, fedora38InstPgClient...)
+var fedora40InstPgClient = append([]c.SingleCmd{}, fedora39InstPgClient...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraStartMySQL() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38StartMySQL
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39StartMySQL
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40StartMySQL
+		}
+	}
+}
+
+func getFedoraStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraStartMySQL()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 Start MySQL Commands
+var fedora38StartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "service mysql start && false",
+		Errmsg:     "Unable to start MySQL server",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39StartMySQL = append([]c.SingleCmd{}, fedora38StartMySQL...)
+var fedora40StartMySQL = append([]c.SingleCmd{}, fedora39StartMySQL...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraStartPostgres() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38StartPostgres
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39StartPostgres
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40StartPostgres
+		}
+	}
+}
+
+func getFedoraStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraStartPostgres()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 Start Postgres Commands
+var fedora38StartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "systemctl start postgresql",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39StartPostgres = append([]c.SingleCmd{}, fedora38StartPostgres...)
+var fedora40StartPostgres = append([]c.SingleCmd{}, fedora39StartPostgres...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraPrepDjango() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38PrepDjango
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39PrepDjango
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40PrepDjango
+		}
+	}
+}
+
+func getFedoraPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraPrepDjango()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 Prep Django Commands
+var fedora38PrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{PyPath} -m pip install virtualenv",
+		Errmsg:     "Unable to install virtualenv module for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}{PyPath} -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to create virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "Upgrade of Python pip failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39PrepDjango = append([]c.SingleCmd{}, fedora38PrepDjango...)
+var fedora40PrepDjango = append([]c.SingleCmd{}, fedora39PrepDjango...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraCreateSettings() {
+	// Connect bootstrap commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38CreateSettings
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39CreateSettings
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40CreateSettings
+		}
+	}
+}
+
+func getFedoraCreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setFedoraCreateSettings()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 Create Settings Commands
+var fedora38CreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create customization directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/.env.prod",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39CreateSettings = append([]c.SingleCmd{}, fedora38CreateSettings...)
+var fedora40CreateSettings = append([]c.SingleCmd{}, fedora39CreateSettings...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraSetupDojo() {
+	// Connect setup DefectDojo commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38SetupDojo
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39SetupDojo
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40SetupDojo
+		}
+	}
+}
+
+func getFedoraSetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setFedoraSetupDojo()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 setup DefectDojo Commands
+var fedora38SetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39 or 40
+var fedora39SetupDojo = append([]c.SingleCmd{}, fedora38SetupDojo...)
+var fedora40SetupDojo = append([]c.SingleCmd{}, fedora39SetupDojo...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallRedis() {
+	// Connect Redis install commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38InstRedis
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39InstRedis
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40InstRedis
+		}
+	}
+}
+
+func getFedoraInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setFedoraInstallRedis()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 install Redis Commands
+var fedora38InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y redis",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39
+var fedora39InstRedis = append([]c.SingleCmd{}, fedora38InstRedis...)
+
+// No command changes needed for Fedora 40
+var fedora40InstRedis = append([]c.SingleCmd{}, fedora38InstRedis...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setFedoraInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported Fedora releases
+	for k := range fedoraReleases {
+		switch {
+		case fedoraReleases[k].Release == "38":
+			fedoraReleases[k].PkgCmds = fedora38InstRabbitMQ
+		case fedoraReleases[k].Release == "39":
+			fedoraReleases[k].PkgCmds = fedora39InstRabbitMQ
+		case fedoraReleases[k].Release == "40":
+			fedoraReleases[k].PkgCmds = fedora40InstRabbitMQ
+		}
+	}
+}
+
+func getFedoraInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setFedoraInstallRabbitMQ()
+
+	// Cycle through Fedora install targets
+	for k, v := range fedoraReleases {
+		// Find a match for the target ID and the existing list of commands in fedoraReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, fedoraReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Fedora 38 install RabbitMQ Commands
+var fedora38InstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y rabbitmq-server",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Fedora 39
+var fedora39InstRabbitMQ = append([]c.SingleCmd{}, fedora38InstRabbitMQ...)
+
+// No command changes needed for Fedora 40
+var fedora40InstRabbitMQ = append([]c.SingleCmd{}, fedora38InstRabbitMQ...)
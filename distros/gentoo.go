@@ -0,0 +1,972 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported Gentoo Install Targets
+// Gentoo is a rolling release so there's a single target for whatever is current
+var gentooReleases = []c.Target{
+	{
+		ID:      "Gentoo:rolling",
+		Distro:  "Gentoo",
+		Release: "rolling",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Portage compiles packages from source so emerge commands get timeouts well
+// past the CmdTimeoutMinutes default, Node.js is by far the longest build
+const (
+	gentooEmergeTimeout = 3 * time.Hour
+	gentooNodeTimeout   = 6 * time.Hour
+)
+
+// Commands for Gentoo
+func GetGentoo(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getGentooBootstrap(bc, t)
+		if err != nil {
+			// Return error from getGentooBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getGentooInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getGentooInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getGentooPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getGentooInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getGentooCreateSettings(bc, t)
+		if err != nil {
+			// Return error from getGentooCreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getGentooSetupDojo(bc, t)
+		if err != nil {
+			// Return error from getGentooCreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetGentooDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getGentooInstallMySQL(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getGentooInstallPostgres(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getGentooStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getGentooStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getGentooInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getGentooInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetGentooBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetGentooBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getGentooInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getGentooInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getGentooInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooBootstrap() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooBootstrap
+		}
+	}
+}
+
+func getGentooBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setGentooBootstrap()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo Bootstrap commands
+// emerge needs --ask=n in case EMERGE_DEFAULT_OPTS has --ask and --noreplace to skip packages already installed
+var gentooBootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		// A failed sync (e.g. rsync mirror rate limits) still leaves a usable Portage tree
+		Cmd:        "emerge --sync --quiet",
+		Errmsg:     "Unable to sync the Portage tree, continuing with the current tree",
+		Hard:       false,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "emerge --ask=n --noreplace --quiet-build dev-lang/python dev-python/pip dev-python/virtualenv " +
+			"app-misc/ca-certificates net-misc/curl app-crypt/gnupg dev-vcs/git app-admin/sudo app-arch/tar",
+		Errmsg:     "Unable to install prerequisites for installer via emerge",
+		Hard:       true,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallerPrep() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooInstallerPrep
+		}
+	}
+}
+
+func getGentooInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooInstallerPrep()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo installer prep Commands
+// Gentoo packages ship their headers so there are no -devel packages to install
+var gentooInstallerPrep = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "emerge --ask=n --noreplace --quiet-build dev-db/mariadb-connector-c dev-tcltk/expect " +
+			"dev-util/pkgconf net-misc/curl",
+		Errmsg:     "Unable to install Gentoo packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// Node.js can take hours to compile, a binary package host makes this much quicker
+		Cmd:        "emerge --ask=n --noreplace --quiet-build net-libs/nodejs sys-apps/yarn",
+		Errmsg:     "Unable to install Node.js and yarn via emerge",
+		Hard:       true,
+		Timeout:    gentooNodeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// gcc and make are part of @system so a failure here is rarely fatal
+		Cmd:        "emerge --ask=n --noreplace --quiet-build sys-devel/gcc dev-build/make",
+		Errmsg:     "Unable to check the Gentoo build toolchain via emerge",
+		Hard:       false,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallMySQL() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooNoDBMySQL
+		}
+	}
+}
+
+func getGentooInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooInstallMySQL()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// Gentoo install MySQL Commands
+// TODO: MariaDB installs fine with emerge but godojo doesn't yet know the default credentials outside of Debian/Ubuntu
+var gentooNoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "echo 'CURRENTLY UNSUPPORTED' && false",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallPostgres() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooNoDBPostgres
+		}
+	}
+}
+
+func getGentooInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooInstallPostgres()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// Gentoo install Postgres Commands
+var gentooNoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "emerge --ask=n --noreplace --quiet-build dev-db/postgresql",
+		Errmsg:     "Unable to install PostgreSQL",
+		Hard:       true,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// The ebuild doesn't create the cluster, emerge --config does under /var/lib/postgresql/<slot>/data
+		// and password auth for TCP means pg_hba.conf needs no changes
+		Cmd: "ls /var/lib/postgresql/*/data/PG_VERSION &>/dev/null || PG_INITDB_OPTS=\"--locale=C.UTF-8 --encoding=UTF8 " +
+			"--auth-host=scram-sha-256 --auth-local=peer\" emerge --config dev-db/postgresql",
+		Errmsg:     "Unable to initialize PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallMySQLClient() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooInstMySQLClient
+		}
+	}
+}
+
+func getGentooInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooInstallMySQLClient()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo install MySQL client Commands
+var gentooInstMySQLClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "emerge --ask=n --noreplace --quiet-build virtual/mysql dev-db/mariadb-connector-c",
+		Errmsg:     "Unable to install MySQL client",
+		Hard:       true,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallPgClient() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooInstPgClient
+		}
+	}
+}
+
+func getGentooInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooInstallPgClient()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo install Postgres client Commands
+// psql comes with the postgresql package, the server isn't started or initialized
+var gentooInstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "emerge --ask=n --noreplace --quiet-build dev-db/postgresql",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    gentooEmergeTimeout,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooStartMySQL() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooStartMySQL
+		}
+	}
+}
+
+func getGentooStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooStartMySQL()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo Start MySQL Commands
+// Gentoo runs either OpenRC or systemd so use whichever is running
+var gentooStartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "if [ -d /run/systemd/system ]; then systemctl start mariadb; else rc-service mysql start; fi",
+		Errmsg:     "Unable to start MariaDB",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooStartPostgres() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooStartPostgres
+		}
+	}
+}
+
+func getGentooStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooStartPostgres()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo Start Postgres Commands
+// Services are named for the PostgreSQL slot, e.g. postgresql-16, so start the newest one installed
+var gentooStartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "if [ -d /run/systemd/system ]; then systemctl start $(systemctl list-unit-files 'postgresql-*' --no-legend | " +
+			"awk '{print $1}' | sort -V | tail -1); else rc-service $(ls /etc/init.d | grep '^postgresql-' | sort -V | tail -1) start; fi",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooPrepDjango() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooPrepDjango
+		}
+	}
+}
+
+func getGentooPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooPrepDjango()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo Prep Django Commands
+// virtualenv comes from dev-python/virtualenv as Gentoo's Python doesn't allow pip installs outside a virtualenv
+var gentooPrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}python3 -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to create virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "Upgrade of Python pip failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooCreateSettings() {
+	// Connect bootstrap commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooCreateSettings
+		}
+	}
+}
+
+func getGentooCreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setGentooCreateSettings()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo Create Settings Commands
+var gentooCreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create customization directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/.env.prod",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooSetupDojo() {
+	// Connect setup DefectDojo commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooSetupDojo
+		}
+	}
+}
+
+func getGentooSetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setGentooSetupDojo()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo setup DefectDojo Commands
+var gentooSetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallRedis() {
+	// Connect Redis install commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooInstRedis
+		}
+	}
+}
+
+func getGentooInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setGentooInstallRedis()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo install Redis Commands
+var gentooInstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "emerge --ask=n --noreplace --quiet-build dev-db/redis",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setGentooInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported Gentoo releases
+	for k := range gentooReleases {
+		switch {
+		case gentooReleases[k].Release == "rolling":
+			gentooReleases[k].PkgCmds = gentooInstRabbitMQ
+		}
+	}
+}
+
+func getGentooInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setGentooInstallRabbitMQ()
+
+	// Cycle through Gentoo install targets
+	for k, v := range gentooReleases {
+		// Find a match for the target ID and the existing list of commands in gentooReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, gentooReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Gentoo install RabbitMQ Commands
+var gentooInstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "emerge --ask=n --noreplace --quiet-build net-misc/rabbitmq-server",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
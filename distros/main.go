@@ -7,16 +7,74 @@ import (
 	c "github.com/mtesauro/commandeer"
 )
 
-func CmdsForTarget(cp *c.CmdPkg, t string) ([]c.SingleCmd, error) {
+// Cmd is a command from a command package for a target and whether it's safe
+// to run at the same time as the parallel commands next to it
+type Cmd struct {
+	c.SingleCmd
+	Parallel bool // Run at the same time as the parallel commands next to it
+}
+
+// CmdsForTarget returns the commands in the command package cp for the target
+// ID t, merged with the external commands loaded by LoadCmdDir for the
+// package's label and target.  The commands are copies so changing them
+// doesn't change the built-in command sets.
+func CmdsForTarget(cp *c.CmdPkg, t string) ([]Cmd, error) {
 	// Cycle through Ubuntu install targets
 	for k := range cp.Targets {
 		if strings.Compare(
 			strings.ToLower(cp.Targets[k].ID),
 			strings.ToLower(t)) == 0 {
 			// Return the commands matching that target
-			return cp.Targets[k].PkgCmds, nil
+			return mergeExternal(cp.Label, t, builtinCmds(cp.Label, t, cp.Targets[k].PkgCmds)), nil
 		}
 	}
 
-	return make([]c.SingleCmd, 1), fmt.Errorf("Unable to find commands for OS target %s\n", t)
+	// External commands can replace the commands for a target with none built-in
+	if s, ok := externalCmds[strings.ToLower(t)][cp.Label]; ok && s.Mode == ModeReplace {
+		return mergeExternal(cp.Label, t, nil), nil
+	}
+
+	return make([]Cmd, 1), fmt.Errorf("Unable to find commands for OS target %s\n", t)
+}
+
+// parallelSets holds the built-in command sets that have commands safe to run
+// at the same time as the parallel commands next to them, by lower case
+// target ID then label.  Commands using the OS package manager aren't marked
+// since apt, dnf, yum and zypper hold a lock for the length of the command.
+var parallelSets = map[string]map[string][]Cmd{
+	"ubuntu:23.10": {"installerprep": u2310InstallerPrep},
+	"ubuntu:22.04": {"installerprep": u2204InstallerPrep},
+	"ubuntu:21.04": {"installerprep": u2104InstallerPrep},
+	"debian:12":    {"installerprep": deb12InstallerPrep},
+	"debian:11":    {"installerprep": deb11InstallerPrep},
+	"rhel:9":       {"installerprep": rhel9InstallerPrep},
+	"rhel:8":       {"installerprep": rhel8InstallerPrep},
+	"amazon:2":     {"installerprep": amzn2InstallerPrep},
+	"amazon:2023":  {"installerprep": amzn2023InstallerPrep},
+}
+
+// singleCmds returns the commands in cmds without their Parallel marks for a
+// Target's PkgCmds
+func singleCmds(cmds []Cmd) []c.SingleCmd {
+	sc := make([]c.SingleCmd, 0, len(cmds))
+	for _, cmd := range cmds {
+		sc = append(sc, cmd.SingleCmd)
+	}
+
+	return sc
+}
+
+// builtinCmds returns the built-in commands for label on the target ID t as
+// Cmds, using the command set in parallelSets if there is one so Parallel is
+// set for its commands
+func builtinCmds(label string, t string, cmds []c.SingleCmd) []Cmd {
+	if set, ok := parallelSets[strings.ToLower(t)][label]; ok {
+		return append([]Cmd{}, set...)
+	}
+	bc := make([]Cmd, 0, len(cmds))
+	for _, sc := range cmds {
+		bc = append(bc, Cmd{SingleCmd: sc})
+	}
+
+	return bc
 }
@@ -130,6 +130,36 @@ func GetRHELDB(bc *c.CmdPkg, t string, d string) error {
 	return nil
 }
 
+// GetRHELBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetRHELBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getRHELInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getRHELInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getRHELInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getRHELInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 //                           Bootstrap commands                              //
 ///////////////////////////////////////////////////////////////////////////////
@@ -183,6 +213,15 @@ var rhel8Bootstrap = []c.SingleCmd{
 		BeforeText: "",
 		AfterText:  "",
 	},
+	c.SingleCmd{
+		// Rocky Linux and AlmaLinux ship epel-release in their repos, RHEL needs the RPM from Fedora
+		Cmd:        "dnf install -y epel-release || dnf install -y https://dl.fedoraproject.org/pub/epel/epel-release-latest-8.noarch.rpm",
+		Errmsg:     "Unable to enable the EPEL repo via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
 	c.SingleCmd{
 		Cmd:        "dnf install -y python39 python3-virtualenv ca-certificates curl gnupg git sudo",
 		Errmsg:     "Unable to install prerequisites for installer via dnf",
@@ -193,8 +232,42 @@ var rhel8Bootstrap = []c.SingleCmd{
 	},
 }
 
-// No command changes needed for RHEL 9
-var rhel9Bootstrap = append([]c.SingleCmd{}, rhel8Bootstrap...)
+// RHEL 9 Bootstrap commands
+var rhel9Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf check-update || [ $? -eq 100 ]", // WTF, dnf returns a 100 exit code if this command is successful!!
+		Errmsg:     "Unable to update RHEL package database",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "dnf update -y",
+		Errmsg:     "Unable to upgrade OS packages with dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		// Rocky Linux and AlmaLinux ship epel-release in their repos, RHEL needs the RPM from Fedora
+		Cmd:        "dnf install -y epel-release || dnf install -y https://dl.fedoraproject.org/pub/epel/epel-release-latest-9.noarch.rpm",
+		Errmsg:     "Unable to enable the EPEL repo via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "dnf install -y python39 python3-virtualenv ca-certificates curl gnupg git sudo",
+		Errmsg:     "Unable to install prerequisites for installer via dnf",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
 
 ///////////////////////////////////////////////////////////////////////////////
 //                           Installer Prep commands                         //
@@ -205,9 +278,9 @@ func setRHELInstallerPrep() {
 	for k := range rhelReleases {
 		switch {
 		case rhelReleases[k].Release == "8":
-			rhelReleases[k].PkgCmds = rhel8InstallerPrep
+			rhelReleases[k].PkgCmds = singleCmds(rhel8InstallerPrep)
 		case rhelReleases[k].Release == "9":
-			rhelReleases[k].PkgCmds = rhel9InstallerPrep
+			rhelReleases[k].PkgCmds = singleCmds(rhel9InstallerPrep)
 		}
 	}
 }
@@ -232,43 +305,43 @@ func getRHELInstallerPrep(bc *c.CmdPkg, t string) error {
 }
 
 // RHEL 8 installer prep Commands
-var rhel8InstallerPrep = []c.SingleCmd{
-	c.SingleCmd{
+var rhel8InstallerPrep = []Cmd{
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "curl --silent --location https://dl.yarnpkg.com/rpm/yarn.repo | sudo tee /etc/yum.repos.d/yarn.repo",
 		Errmsg:     "Unable to add the repo for Yarn",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "curl --silent --location https://rpm.nodesource.com/setup_18.x | sudo bash -",
 		Errmsg:     "Unable to add yard repo as an apt source",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "dnf check-update || [ $? -eq 100 ]", // WTF, dnf returns a 100 exit code if this command is successful!!
 		Errmsg:     "Unable to update RHEL package database",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "dnf install -y sudo mysql yarn expect gcc python39-devel python39-pip initscripts mariadb-connector-c-devel libcurl-devel",
 		Errmsg:     "Unable to install RHEL packages needed to prep the installer",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
+	}},
 }
 
 // No command changes needed for RHEL 9
-var rhel9InstallerPrep = append([]c.SingleCmd{}, rhel8InstallerPrep...)
+var rhel9InstallerPrep = append([]Cmd{}, rhel8InstallerPrep...)
 
 ///////////////////////////////////////////////////////////////////////////////
 //                           Install MySQL commands                          //
@@ -632,7 +705,7 @@ var rhel8PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{PyPath} -m virtualenv --python={PyPath} {conf.Install.Root}",
+		Cmd:        "{VirtualenvEnv}{PyPath} -m virtualenv --python={PyPath} {VenvPath}",
 		Errmsg:     "Unable to create virtualenv for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -640,7 +713,7 @@ var rhel8PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/python3 -m pip install --upgrade pip",
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
 		Errmsg:     "Upgrade of Python pip failed",
 		Hard:       true,
 		Timeout:    0,
@@ -648,7 +721,7 @@ var rhel8PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install --upgrade setuptools",
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
 		Errmsg:     "",
 		Hard:       true,
 		Timeout:    0,
@@ -656,7 +729,7 @@ var rhel8PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install -r {conf.Install.Root}/django-DefectDojo/requirements.txt",
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
 		Errmsg:     "Unable to install Python3 modules for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -808,7 +881,7 @@ func getRHELSetupDojo(bc *c.CmdPkg, t string) error {
 // RHEL 8 setup DefectDojo Commands
 var rhel8SetupDojo = []c.SingleCmd{
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py makemigrations dojo",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
 		Errmsg:     "Failed during makemgration dojo",
 		Hard:       true,
 		Timeout:    0,
@@ -816,7 +889,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
 		Errmsg:     "Failed during database migrate",
 		Hard:       true,
 		Timeout:    0,
@@ -824,7 +897,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py createsuperuser" +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
 			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
 		Errmsg:     "Failed while creating DefectDojo superuser",
 		Hard:       true,
@@ -833,7 +906,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
 			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
 		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
 		Hard:       true,
@@ -842,7 +915,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py loaddata " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
 			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
 			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
 		Errmsg:     "Failed while the loading data for a default install",
@@ -852,7 +925,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate_textquestions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
 		Errmsg:     "Failed while the loading data for a default survey questions",
 		Hard:       true,
 		Timeout:    0,
@@ -860,7 +933,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py buildwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
 		Errmsg:     "Failed while the running buildwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -868,7 +941,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py installwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
 		Errmsg:     "Failed while the running installwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -876,7 +949,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_test_types",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
 		Errmsg:     "Failed to initialize test_types",
 		Hard:       true,
 		Timeout:    0,
@@ -884,7 +957,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_permissions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
 		Errmsg:     "Failed to initialize permissions",
 		Hard:       true,
 		Timeout:    0,
@@ -900,7 +973,7 @@ var rhel8SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source ../bin/activate && python3 manage.py collectstatic --noinput",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
 		Errmsg:     "Failed while the running collectstatic",
 		Hard:       true,
 		Timeout:    0,
@@ -919,3 +992,62 @@ var rhel8SetupDojo = []c.SingleCmd{
 
 // No command changes needed for RHEL 9
 var rhel9SetupDojo = append([]c.SingleCmd{}, rhel8SetupDojo...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setRHELInstallRedis() {
+	// Connect Redis install commands to the supported RHEL releases
+	for k := range rhelReleases {
+		switch {
+		case rhelReleases[k].Release == "8":
+			rhelReleases[k].PkgCmds = rhel8InstRedis
+		case rhelReleases[k].Release == "9":
+			rhelReleases[k].PkgCmds = rhel9InstRedis
+		}
+	}
+}
+
+func getRHELInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setRHELInstallRedis()
+
+	// Cycle through RHEL install targets
+	for k, v := range rhelReleases {
+		// Find a match for the target ID and the existing list of commands in rhelReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, rhelReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// RHEL 8 install Redis Commands
+var rhel8InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "dnf install -y redis",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for RHEL 9
+var rhel9InstRedis = append([]c.SingleCmd{}, rhel8InstRedis...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func getRHELInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// No match for the target provided
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
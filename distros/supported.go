@@ -0,0 +1,131 @@
+package distros
+
+import (
+	"reflect"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Family is a distro family godojo has built-in commands for
+type Family struct {
+	Name    string    // Distro name used in the target IDs, e.g. Ubuntu
+	PkgMgr  string    // Package manager the commands use
+	Also    []string  // Other distros installed with this family's commands
+	Targets []Release // Supported releases in the order they're listed in the command sets
+}
+
+// Release is a supported release of a distro family and the command set it uses
+type Release struct {
+	ID     string // Target ID, e.g. Ubuntu:22.04
+	CmdSet string // ID of the release whose commands this one uses, the same as ID unless it shares an earlier release's commands
+}
+
+// family links a distro family to its releases and command lookups
+type family struct {
+	name      string
+	pkgMgr    string
+	also      []string
+	releases  *[]c.Target
+	get       func(*c.CmdPkg, string) error
+	getDB     func(*c.CmdPkg, string, string) error
+	getBroker func(*c.CmdPkg, string, string) error
+}
+
+// families is every distro family with built-in commands
+var families = []family{
+	{"Ubuntu", "apt", nil, &ubuntuReleases, GetUbuntu, GetUbuntuDB, GetUbuntuBroker},
+	{"Debian", "apt", []string{"Raspberry Pi OS"}, &debianReleases, GetDebian, GetDebianDB, GetDebianBroker},
+	{"RHEL", "dnf", []string{"Rocky Linux", "AlmaLinux"}, &rhelReleases, GetRHEL, GetRHELDB, GetRHELBroker},
+	{"Amazon", "yum/dnf", nil, &amazonReleases, GetAmazon, GetAmazonDB, GetAmazonBroker},
+	{"Fedora", "dnf", nil, &fedoraReleases, GetFedora, GetFedoraDB, GetFedoraBroker},
+	{"Arch", "pacman", nil, &archReleases, GetArch, GetArchDB, GetArchBroker},
+	{"Gentoo", "emerge", nil, &gentooReleases, GetGentoo, GetGentooDB, GetGentooBroker},
+	{"SUSE", "zypper", []string{"openSUSE Leap", "SLES"}, &suseReleases, GetSUSE, GetSUSEDB, GetSUSEBroker},
+}
+
+// Labels of the database commands and the databases they're looked up for
+var (
+	dbLabels = []string{"installdb", "installdbclient", "startdb"}
+	dbNames  = []string{"MySQL", "PostgreSQL"}
+)
+
+// Label of the celery broker commands and the brokers they're looked up for
+var (
+	brokerLabel = "installbroker"
+	brokerNames = []string{"Redis", "RabbitMQ"}
+)
+
+// Supported returns the distro families with built-in commands and their
+// releases.  A release whose built-in commands for every label, database and
+// broker are the same as an earlier release of the family has that release as
+// its CmdSet so the releases where the commands branch can be told apart.
+func Supported() []Family {
+	var all []Family
+	for _, f := range families {
+		fam := Family{Name: f.name, PkgMgr: f.pkgMgr, Also: f.also}
+		var seen [][][]c.SingleCmd
+		for _, t := range *f.releases {
+			cmds := releaseCmds(f, t.ID)
+			r := Release{ID: t.ID, CmdSet: t.ID}
+			for i := range seen {
+				if reflect.DeepEqual(seen[i], cmds) {
+					r.CmdSet = fam.Targets[i].CmdSet
+					break
+				}
+			}
+			seen = append(seen, cmds)
+			fam.Targets = append(fam.Targets, r)
+		}
+		all = append(all, fam)
+	}
+
+	return all
+}
+
+// releaseCmds returns the built-in commands of the family f for the target t
+// for each label, database and broker, nil where a label has no commands for t
+func releaseCmds(f family, t string) [][]c.SingleCmd {
+	var cmds [][]c.SingleCmd
+	for _, l := range Labels {
+		if isDBLabel(l) || l == brokerLabel {
+			continue
+		}
+		cp := &c.CmdPkg{Label: l}
+		if f.get(cp, t) != nil || len(cp.Targets) == 0 {
+			cmds = append(cmds, nil)
+			continue
+		}
+		cmds = append(cmds, cp.Targets[0].PkgCmds)
+	}
+	for _, l := range dbLabels {
+		for _, db := range dbNames {
+			cp := &c.CmdPkg{Label: l}
+			if f.getDB(cp, t, db) != nil || len(cp.Targets) == 0 {
+				cmds = append(cmds, nil)
+				continue
+			}
+			cmds = append(cmds, cp.Targets[0].PkgCmds)
+		}
+	}
+	for _, b := range brokerNames {
+		cp := &c.CmdPkg{Label: brokerLabel}
+		if f.getBroker(cp, t, b) != nil || len(cp.Targets) == 0 {
+			cmds = append(cmds, nil)
+			continue
+		}
+		cmds = append(cmds, cp.Targets[0].PkgCmds)
+	}
+
+	return cmds
+}
+
+// isDBLabel returns true if l is one of the database command labels
+func isDBLabel(l string) bool {
+	for i := range dbLabels {
+		if dbLabels[i] == l {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,970 @@
+package distros
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/mtesauro/commandeer"
+)
+
+// Slice of Target structs supported SUSE Linux Install Targets
+// SLES 15 and openSUSE Leap 15 share packages so both use the SUSE:15 target
+var suseReleases = []c.Target{
+	{
+		ID:      "SUSE:15",
+		Distro:  "SUSE",
+		Release: "15",
+		OS:      "Linux",
+		Shell:   "bash",
+	},
+}
+
+// Commands for SUSE
+func GetSUSE(bc *c.CmdPkg, t string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "bootstrap":
+		err := getSUSEBootstrap(bc, t)
+		if err != nil {
+			// Return error from getSUSEBootstrap()
+			return err
+		}
+	case bc.Label == "installerprep":
+		err := getSUSEInstallerPrep(bc, t)
+		if err != nil {
+			// Return error from getSUSEInstallerPrep()
+			return err
+		}
+	case bc.Label == "prepdjango":
+		err := getSUSEPrepDjango(bc, t)
+		if err != nil {
+			// Return error from getSUSEInstallerPrep()
+			return err
+		}
+	case bc.Label == "createsettings":
+		err := getSUSECreateSettings(bc, t)
+		if err != nil {
+			// Return error from getSUSECreateSettings()
+			return err
+		}
+	case bc.Label == "setupdojo":
+		err := getSUSESetupDojo(bc, t)
+		if err != nil {
+			// Return error from getSUSECreateSettings()
+			return err
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+func GetSUSEDB(bc *c.CmdPkg, t string, d string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getSUSEInstallMySQL(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getSUSEInstallPostgres(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find a set of commands for the database %s\n", d)
+		}
+	case bc.Label == "startdb":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getSUSEStartMySQL(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallMySQL()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getSUSEStartPostgres(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	case bc.Label == "installdbclient":
+		// Determine target DB
+		switch {
+		case strings.ToLower(d) == "mysql":
+			err := getSUSEInstallMySQLClient(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallMySQLClient()
+				return err
+			}
+		case strings.ToLower(d) == "postgresql":
+			err := getSUSEInstallPgClient(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallPostgres()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to start the database %s\n", d)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+// GetSUSEBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetSUSEBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getSUSEInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getSUSEInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getSUSEInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Bootstrap commands                              //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEBootstrap() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15Bootstrap
+		}
+	}
+}
+
+func getSUSEBootstrap(bc *c.CmdPkg, t string) error {
+	// Set bootstrap as the commands to use
+	setSUSEBootstrap()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 Bootstrap commands
+// SLES 15 and Leap 15 ship Python 3.6 as python3 so Python 3.11 is installed alongside it
+var suse15Bootstrap = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive refresh",
+		Errmsg:     "Unable to refresh SUSE package repositories",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive update",
+		Errmsg:     "Unable to upgrade OS packages with zypper",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install python311 python311-pip ca-certificates curl gpg2 git sudo tar gzip",
+		Errmsg:     "Unable to install prerequisites for installer via zypper",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Installer Prep commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallerPrep() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15InstallerPrep
+		}
+	}
+}
+
+func getSUSEInstallerPrep(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEInstallerPrep()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 installer prep Commands
+// Node.js comes from the OS repos as NodeSource doesn't publish packages for SUSE
+var suse15InstallerPrep = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install nodejs18 npm18",
+		Errmsg:     "Unable to install Node.js 18 via zypper",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "npm install -g yarn",
+		Errmsg:     "Unable to install Yarn via npm",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install sudo mariadb-client expect gcc python311-devel libmariadb-devel libcurl-devel",
+		Errmsg:     "Unable to install SUSE packages needed to prep the installer",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallMySQL() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15NoDBMySQL
+		}
+	}
+}
+
+func getSUSEInstallMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEInstallMySQL()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install MySQL for target %s\n", t)
+}
+
+// SUSE 15 install MySQL Commands
+// TODO: SUSE ships MariaDB rather than MySQL so this needs MariaDB specific commands
+var suse15NoDBMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "echo 'CURRENTLY UNSUPPORTED' && false",
+		Errmsg:     "Unable to install MySQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallPostgres() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15NoDBPostgres
+		}
+	}
+}
+
+func getSUSEInstallPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEInstallPostgres()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands to install PostgreSQL for target %s\n", t)
+}
+
+// SUSE 15 install Postgres Commands
+// The SUSE postgresql service initializes the data directory on its first start
+var suse15NoDBPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install postgresql15-server postgresql15",
+		Errmsg:     "Unable to install PostgreSQL 15",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install MySQL client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallMySQLClient() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			//suseReleases[k].PkgCmds = suse15InstMySQLClient
+		}
+	}
+}
+
+func getSUSEInstallMySQLClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEInstallMySQLClient()
+
+	// No match for the target provided
+	//return fmt.Errorf("Unable to find commands for target %s\n", t)
+	return fmt.Errorf("Commands for target %s have not been implemented\n", t)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Postgres client commands                //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallPgClient() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15InstPgClient
+		}
+	}
+}
+
+func getSUSEInstallPgClient(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEInstallPgClient()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 install Postgres client Commands
+var suse15InstPgClient = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install postgresql15",
+		Errmsg:     "Unable to install PostgreSQL client",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f postgres",
+		Errmsg:     "Unable to add postgres group",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "id postgres &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g postgres postgres; fi",
+		Errmsg:     "Unable to add postgres user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir -p /var/lib/pgsql",
+		Errmsg:     "Unable to create postgres user directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start MySQL commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEStartMySQL() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15StartMySQL
+		}
+	}
+}
+
+func getSUSEStartMySQL(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEStartMySQL()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 Start MySQL Commands
+var suse15StartMySQL = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "service mysql start && false",
+		Errmsg:     "Unable to start MySQL server",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Start Postgres commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEStartPostgres() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15StartPostgres
+		}
+	}
+}
+
+func getSUSEStartPostgres(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEStartPostgres()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 Start Postgres Commands
+var suse15StartPostgres = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "systemctl start postgresql",
+		Errmsg:     "Unable to start PostgreSQL",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Prep Django commands                            //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEPrepDjango() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15PrepDjango
+		}
+	}
+}
+
+func getSUSEPrepDjango(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSEPrepDjango()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 Prep Django Commands
+var suse15PrepDjango = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "{PyPath} -m pip install virtualenv",
+		Errmsg:     "Unable to install virtualenv module for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VirtualenvEnv}{PyPath} -m virtualenv --python={PyPath} {VenvPath}",
+		Errmsg:     "Unable to create virtualenv for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
+		Errmsg:     "Upgrade of Python pip failed",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
+		Errmsg:     "Unable to install Python3 modules for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "mkdir {conf.Install.Root}/logs",
+		Errmsg:     "Unable to create a directory for logs",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "/usr/sbin/groupadd -f {conf.Install.OS.Group}",
+		Errmsg:     "Unable to create a group for DefectDojo OS user",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "id {conf.Install.OS.User} &>/dev/null; if [ $? -ne 0 ]; then useradd -s /bin/bash -m -g " +
+			"{conf.Install.OS.Group} {conf.Install.OS.User}; fi",
+		Errmsg:     "Unable to create an OS user for DefectDojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                          Create Settings commands                         //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSECreateSettings() {
+	// Connect bootstrap commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15CreateSettings
+		}
+	}
+}
+
+func getSUSECreateSettings(bc *c.CmdPkg, t string) error {
+	// Set Installer Prep as the commands to use
+	setSUSECreateSettings()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 Create Settings Commands
+var suse15CreateSettings = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd: "ln -s {conf.Install.Root}/django-DefectDojo/dojo/settings/ " +
+			"{conf.Install.Root}/customizations",
+		Errmsg:     "Unable to create customization directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "echo '# Add customizations here\n# For more details see:" +
+			" https://documentation.defectdojo.com/getting_started/configuration/' > {conf.Install.Root}/customizations/local_settings.py",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "chown {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}" +
+			"/django-DefectDojo/dojo/settings/.env.prod",
+		Errmsg:     "Unable to change ownership of .env.prod file",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Setup DefectDojo commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSESetupDojo() {
+	// Connect setup DefectDojo commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15SetupDojo
+		}
+	}
+}
+
+func getSUSESetupDojo(bc *c.CmdPkg, t string) error {
+	// Set setup DefectDojo as the commands to use
+	setSUSESetupDojo()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 setup DefectDojo Commands
+var suse15SetupDojo = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
+		Errmsg:     "Failed during makemgration dojo",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
+		Errmsg:     "Failed during database migrate",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
+			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
+		Errmsg:     "Failed while creating DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
+			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
+		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
+			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
+			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
+		Errmsg:     "Failed while the loading data for a default install",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
+		Errmsg:     "Failed while the loading data for a default survey questions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
+		Errmsg:     "Failed while the running buildwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
+		Errmsg:     "Failed while the running installwatson",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
+		Errmsg:     "Failed to initialize test_types",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
+		Errmsg:     "Failed to initialize permissions",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/components && yarn",
+		Errmsg:     "Failed while the running yarn",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
+		Errmsg:     "Failed while the running collectstatic",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "chown -R {conf.Install.OS.User}.{conf.Install.OS.Group} {conf.Install.Root}",
+		Errmsg:     "Unable to change ownership of the DefectDojo directory",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallRedis() {
+	// Connect Redis install commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15InstRedis
+		}
+	}
+}
+
+func getSUSEInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setSUSEInstallRedis()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 install Redis Commands, openSUSE runs an instance of redis@ per config in /etc/redis
+var suse15InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install redis",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+	c.SingleCmd{
+		Cmd:        "[ -f /etc/redis/default.conf ] || install -m 0640 -o root -g redis /etc/redis/default.conf.example /etc/redis/default.conf",
+		Errmsg:     "Unable to create the default Redis config",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setSUSEInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported SUSE releases
+	for k := range suseReleases {
+		switch {
+		case suseReleases[k].Release == "15":
+			suseReleases[k].PkgCmds = suse15InstRabbitMQ
+		}
+	}
+}
+
+func getSUSEInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setSUSEInstallRabbitMQ()
+
+	// Cycle through SUSE install targets
+	for k, v := range suseReleases {
+		// Find a match for the target ID and the existing list of commands in suseReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, suseReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// SUSE 15 install RabbitMQ Commands
+var suse15InstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "zypper --non-interactive install rabbitmq-server",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
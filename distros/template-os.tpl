@@ -620,7 +620,7 @@ func getTemplatePrepDjango(bc *c.CmdPkg, t string) error {
 // Template 22.04 Prep Django Commands
 var t2204PrepDjango = []c.SingleCmd{
 	c.SingleCmd{
-		Cmd:        "python3 -m virtualenv --python={PyPath} {conf.Install.Root}",
+		Cmd:        "{VirtualenvEnv}python3 -m virtualenv --python={PyPath} {VenvPath}",
 		Errmsg:     "Unable to setup virtualenv for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -628,7 +628,7 @@ var t2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/python3 -m pip install --upgrade pip",
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
 		Errmsg:     "",
 		Hard:       true,
 		Timeout:    0,
@@ -636,7 +636,7 @@ var t2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install --upgrade setuptools",
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
 		Errmsg:     "",
 		Hard:       true,
 		Timeout:    0,
@@ -644,7 +644,7 @@ var t2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install -r {conf.Install.Root}/django-DefectDojo/requirements.txt",
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
 		Errmsg:     "Unable to install Python3 modules for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -796,7 +796,7 @@ func getTemplateSetupDojo(bc *c.CmdPkg, t string) error {
 // Template 22.04 setup DefectDojo Commands
 var t2204SetupDojo = []c.SingleCmd{
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py makemigrations dojo",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
 		Errmsg:     "Failed during makemgration dojo",
 		Hard:       true,
 		Timeout:    0,
@@ -804,7 +804,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
 		Errmsg:     "Failed during database migrate",
 		Hard:       true,
 		Timeout:    0,
@@ -812,7 +812,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py createsuperuser" +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
 			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
 		Errmsg:     "Failed while creating DefectDojo superuser",
 		Hard:       true,
@@ -821,7 +821,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
 			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
 		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
 		Hard:       true,
@@ -830,7 +830,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py loaddata " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
 			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
 			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
 		Errmsg:     "Failed while the loading data for a default install",
@@ -840,7 +840,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate_textquestions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
 		Errmsg:     "Failed while the loading data for a default survey questions",
 		Hard:       true,
 		Timeout:    0,
@@ -848,7 +848,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py buildwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
 		Errmsg:     "Failed while the running buildwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -856,7 +856,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py installwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
 		Errmsg:     "Failed while the running installwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -864,7 +864,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_test_types",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
 		Errmsg:     "Failed to initialize test_types",
 		Hard:       true,
 		Timeout:    0,
@@ -872,7 +872,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_permissions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
 		Errmsg:     "Failed to initialize permissions",
 		Hard:       true,
 		Timeout:    0,
@@ -888,7 +888,7 @@ var t2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source ../bin/activate && python3 manage.py collectstatic --noinput",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
 		Errmsg:     "Failed while the running collectstatic",
 		Hard:       true,
 		Timeout:    0,
@@ -137,6 +137,36 @@ func GetUbuntuDB(bc *c.CmdPkg, t string, d string) error {
 	return nil
 }
 
+// GetUbuntuBroker adds the commands to install the celery broker b, Redis or
+// RabbitMQ, for the label installbroker and the target t to bc
+func GetUbuntuBroker(bc *c.CmdPkg, t string, b string) error {
+	// Use the label and target to get the correct commands
+	switch {
+	case bc.Label == "installbroker":
+		// Determine target broker
+		switch {
+		case strings.ToLower(b) == "redis":
+			err := getUbuntuInstallRedis(bc, t)
+			if err != nil {
+				// Return error from getUbuntuInstallRedis()
+				return err
+			}
+		case strings.ToLower(b) == "rabbitmq":
+			err := getUbuntuInstallRabbitMQ(bc, t)
+			if err != nil {
+				// Return error from getUbuntuInstallRabbitMQ()
+				return err
+			}
+		default:
+			return fmt.Errorf("Unable to find commands to install the broker %s\n", b)
+		}
+	default:
+		return fmt.Errorf("Unable to find a set of commands for the label %s\n", bc.Label)
+	}
+
+	return nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 //                           Bootstrap commands                              //
 ///////////////////////////////////////////////////////////////////////////////
@@ -217,11 +247,11 @@ func setUbuntuInstallerPrep() {
 	for k := range ubuntuReleases {
 		switch {
 		case ubuntuReleases[k].Release == "23.10":
-			ubuntuReleases[k].PkgCmds = u2310InstallerPrep
+			ubuntuReleases[k].PkgCmds = singleCmds(u2310InstallerPrep)
 		case ubuntuReleases[k].Release == "22.04":
-			ubuntuReleases[k].PkgCmds = u2204InstallerPrep
+			ubuntuReleases[k].PkgCmds = singleCmds(u2204InstallerPrep)
 		case ubuntuReleases[k].Release == "21.04":
-			ubuntuReleases[k].PkgCmds = u2104InstallerPrep
+			ubuntuReleases[k].PkgCmds = singleCmds(u2104InstallerPrep)
 		}
 	}
 }
@@ -247,62 +277,62 @@ func getUbuntuInstallerPrep(bc *c.CmdPkg, t string) error {
 
 // Ubuntu 22.04 installer prep Commands
 // TODO Check if the yarn command needs updating
-var u2204InstallerPrep = []c.SingleCmd{
-	c.SingleCmd{
+var u2204InstallerPrep = []Cmd{
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "curl -sS {yarnGPG} | apt-key add -",
 		Errmsg:     "Unable to obtain the gpg key for Yarn",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "echo -n {yarnRepo} > /etc/apt/sources.list.d/yarn.list",
 		Errmsg:     "Unable to add yard repo as an apt source",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}, Parallel: true},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get update",
 		Errmsg:     "Unable to update apt database",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get -y install sudo libmysqlclient-dev",
 		Errmsg:     "Unable to install sudo and MySQL client library",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "curl -sL {nodeURL} | bash - ",
 		Errmsg:     "Unable to install nodejs",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
-	c.SingleCmd{
+	}},
+	{SingleCmd: c.SingleCmd{
 		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y apt-transport-https libjpeg-dev gcc libssl-dev python3-dev python3-pip python3-virtualenv yarn build-essential expect libcurl4-openssl-dev",
 		Errmsg:     "Installing OS packages with apt failed",
 		Hard:       true,
 		Timeout:    0,
 		BeforeText: "",
 		AfterText:  "",
-	},
+	}},
 }
 
 // No command changes needed for Ubuntu 21.04
-var u2104InstallerPrep = append([]c.SingleCmd{}, u2204InstallerPrep...)
+var u2104InstallerPrep = append([]Cmd{}, u2204InstallerPrep...)
 
 // No command changes needed for Ubuntu 23.10
-var u2310InstallerPrep = append([]c.SingleCmd{}, u2204InstallerPrep...)
+var u2310InstallerPrep = append([]Cmd{}, u2204InstallerPrep...)
 
 ///////////////////////////////////////////////////////////////////////////////
 //                           Install MySQL commands                          //
@@ -662,7 +692,7 @@ func getUbuntuPrepDjango(bc *c.CmdPkg, t string) error {
 // Ubuntu 22.04 Prep Django Commands
 var u2204PrepDjango = []c.SingleCmd{
 	c.SingleCmd{
-		Cmd:        "python3 -m virtualenv --python={PyPath} {conf.Install.Root}",
+		Cmd:        "{VirtualenvEnv}python3 -m virtualenv --python={PyPath} {VenvPath}",
 		Errmsg:     "Unable to setup virtualenv for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -670,7 +700,7 @@ var u2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/python3 -m pip install --upgrade pip",
+		Cmd:        "{VenvPath}/bin/python3 -m pip install --upgrade {PipSpec}",
 		Errmsg:     "",
 		Hard:       true,
 		Timeout:    0,
@@ -678,7 +708,7 @@ var u2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install --upgrade setuptools",
+		Cmd:        "{VenvPath}/bin/pip3 install --upgrade setuptools",
 		Errmsg:     "",
 		Hard:       true,
 		Timeout:    0,
@@ -686,7 +716,7 @@ var u2204PrepDjango = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "{conf.Install.Root}/bin/pip3 install -r {conf.Install.Root}/django-DefectDojo/requirements.txt",
+		Cmd:        "{VenvPath}/bin/pip3 install -r {Requirements}",
 		Errmsg:     "Unable to install Python3 modules for DefectDojo",
 		Hard:       true,
 		Timeout:    0,
@@ -848,7 +878,7 @@ func getUbuntuSetupDojo(bc *c.CmdPkg, t string) error {
 // Ubuntu 22.04 setup DefectDojo Commands
 var u2204SetupDojo = []c.SingleCmd{
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py makemigrations dojo",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py makemigrations dojo",
 		Errmsg:     "Failed during makemgration dojo",
 		Hard:       true,
 		Timeout:    0,
@@ -856,7 +886,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate",
 		Errmsg:     "Failed during database migrate",
 		Hard:       true,
 		Timeout:    0,
@@ -864,7 +894,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py createsuperuser" +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py createsuperuser" +
 			" --noinput --username=\"{conf.Install.Admin.User}\" --email=\"{conf.Install.Admin.Email}\"",
 		Errmsg:     "Failed while creating DefectDojo superuser",
 		Hard:       true,
@@ -873,7 +903,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && " +
 			"{conf.Install.Root}/django-DefectDojo/setup-superuser.expect {conf.Install.Admin.User} \"{conf.Install.Admin.Pass}\"",
 		Errmsg:     "Failed while setting the password for the DefectDojo superuser",
 		Hard:       true,
@@ -882,7 +912,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py loaddata " +
+		Cmd: "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py loaddata " +
 			"system_settings initial_banner_conf product_type test_type development_environment benchmark_type " +
 			"benchmark_category benchmark_requirement language_type objects_review regulation initial_surveys role",
 		Errmsg:     "Failed while the loading data for a default install",
@@ -892,7 +922,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py migrate_textquestions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py migrate_textquestions",
 		Errmsg:     "Failed while the loading data for a default survey questions",
 		Hard:       true,
 		Timeout:    0,
@@ -900,7 +930,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py buildwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py buildwatson",
 		Errmsg:     "Failed while the running buildwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -908,7 +938,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py installwatson",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py installwatson",
 		Errmsg:     "Failed while the running installwatson",
 		Hard:       true,
 		Timeout:    0,
@@ -916,7 +946,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_test_types",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_test_types",
 		Errmsg:     "Failed to initialize test_types",
 		Hard:       true,
 		Timeout:    0,
@@ -924,7 +954,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source ../bin/activate && python3 manage.py initialize_permissions",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo && source {VenvPath}/bin/activate && python3 manage.py initialize_permissions",
 		Errmsg:     "Failed to initialize permissions",
 		Hard:       true,
 		Timeout:    0,
@@ -940,7 +970,7 @@ var u2204SetupDojo = []c.SingleCmd{
 		AfterText:  "",
 	},
 	c.SingleCmd{
-		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source ../bin/activate && python3 manage.py collectstatic --noinput",
+		Cmd:        "cd {conf.Install.Root}/django-DefectDojo/ && source {VenvPath}/bin/activate && python3 manage.py collectstatic --noinput",
 		Errmsg:     "Failed while the running collectstatic",
 		Hard:       true,
 		Timeout:    0,
@@ -962,3 +992,113 @@ var u2104SetupDojo = append([]c.SingleCmd{}, u2204SetupDojo...)
 
 // No command changes needed for Ubuntu 23.10
 var u2310SetupDojo = append([]c.SingleCmd{}, u2204SetupDojo...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install Redis commands                          //
+///////////////////////////////////////////////////////////////////////////////
+
+func setUbuntuInstallRedis() {
+	// Connect Redis install commands to the supported Ubuntu releases
+	for k := range ubuntuReleases {
+		switch {
+		case ubuntuReleases[k].Release == "23.10":
+			ubuntuReleases[k].PkgCmds = u2310InstRedis
+		case ubuntuReleases[k].Release == "22.04":
+			ubuntuReleases[k].PkgCmds = u2204InstRedis
+		case ubuntuReleases[k].Release == "21.04":
+			ubuntuReleases[k].PkgCmds = u2104InstRedis
+		}
+	}
+}
+
+func getUbuntuInstallRedis(bc *c.CmdPkg, t string) error {
+	// Set Redis install as the commands to use
+	setUbuntuInstallRedis()
+
+	// Cycle through Ubuntu install targets
+	for k, v := range ubuntuReleases {
+		// Find a match for the target ID and the existing list of commands in ubuntuReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, ubuntuReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Ubuntu 22.04 install Redis Commands
+var u2204InstRedis = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y redis-server",
+		Errmsg:     "Unable to install Redis",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Ubuntu 23.10
+var u2310InstRedis = append([]c.SingleCmd{}, u2204InstRedis...)
+
+// No command changes needed for Ubuntu 21.04
+var u2104InstRedis = append([]c.SingleCmd{}, u2204InstRedis...)
+
+///////////////////////////////////////////////////////////////////////////////
+//                           Install RabbitMQ commands                       //
+///////////////////////////////////////////////////////////////////////////////
+
+func setUbuntuInstallRabbitMQ() {
+	// Connect RabbitMQ install commands to the supported Ubuntu releases
+	for k := range ubuntuReleases {
+		switch {
+		case ubuntuReleases[k].Release == "23.10":
+			ubuntuReleases[k].PkgCmds = u2310InstRabbitMQ
+		case ubuntuReleases[k].Release == "22.04":
+			ubuntuReleases[k].PkgCmds = u2204InstRabbitMQ
+		case ubuntuReleases[k].Release == "21.04":
+			ubuntuReleases[k].PkgCmds = u2104InstRabbitMQ
+		}
+	}
+}
+
+func getUbuntuInstallRabbitMQ(bc *c.CmdPkg, t string) error {
+	// Set RabbitMQ install as the commands to use
+	setUbuntuInstallRabbitMQ()
+
+	// Cycle through Ubuntu install targets
+	for k, v := range ubuntuReleases {
+		// Find a match for the target ID and the existing list of commands in ubuntuReleases
+		if strings.Compare(
+			strings.ToLower(v.ID),
+			strings.ToLower(t)) == 0 {
+			bc.Targets = append(bc.Targets, ubuntuReleases[k])
+			return nil
+		}
+	}
+
+	// No match for the target provided
+	return fmt.Errorf("Unable to find commands for target %s\n", t)
+}
+
+// Ubuntu 22.04 install RabbitMQ Commands
+var u2204InstRabbitMQ = []c.SingleCmd{
+	c.SingleCmd{
+		Cmd:        "DEBIAN_FRONTEND=noninteractive apt-get install -y rabbitmq-server",
+		Errmsg:     "Unable to install RabbitMQ",
+		Hard:       true,
+		Timeout:    0,
+		BeforeText: "",
+		AfterText:  "",
+	},
+}
+
+// No command changes needed for Ubuntu 23.10
+var u2310InstRabbitMQ = append([]c.SingleCmd{}, u2204InstRabbitMQ...)
+
+// No command changes needed for Ubuntu 21.04
+var u2104InstRabbitMQ = append([]c.SingleCmd{}, u2204InstRabbitMQ...)
@@ -4,22 +4,27 @@ go 1.19
 
 require (
 	github.com/briandowns/spinner v1.6.1
+	github.com/fatih/color v1.7.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/klauspost/compress v1.16.7
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-isatty v0.0.8
 	github.com/mtesauro/commandeer v1.1.4
 	github.com/spf13/viper v1.4.0
+	golang.org/x/crypto v0.14.0
 	golang.org/x/text v0.13.0
 	gopkg.in/src-d/go-git.v4 v4.12.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/emirpasic/gods v1.12.0 // indirect
-	github.com/fatih/color v1.7.0 // indirect
 	github.com/fsnotify/fsnotify v1.4.7 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
 	github.com/kevinburke/ssh_config v0.0.0-20180830205328-81db2a75821e // indirect
 	github.com/magiconair/properties v1.8.0 // indirect
 	github.com/mattn/go-colorable v0.1.2 // indirect
-	github.com/mattn/go-isatty v0.0.8 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/mapstructure v1.1.2 // indirect
 	github.com/pelletier/go-buffruneio v0.2.0 // indirect
@@ -31,10 +36,8 @@ require (
 	github.com/spf13/pflag v1.0.3 // indirect
 	github.com/src-d/gcfg v1.4.0 // indirect
 	github.com/xanzy/ssh-agent v0.2.1 // indirect
-	golang.org/x/crypto v0.14.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
 	gopkg.in/src-d/go-billy.v4 v4.3.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )